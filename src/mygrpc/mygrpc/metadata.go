@@ -0,0 +1,7 @@
+// Metadata keys of the rpcs of mygrpc shared by the clients and the servers
+
+package mygrpc
+
+// metadata key with which clients opt in the partial results mode of the streaming
+// rpcs, as the partial_results field of ServiceChains does
+const PartialResultsKey = "mygrpc-partial-results"
\ No newline at end of file
@@ -10,16 +10,38 @@ It is generated from these files:
 It has these top-level messages:
 	Service
 	ServiceChain
+	StoredChain
+	ChainRequest
+	ListChainsRequest
+	ListChainsResponse
 	ServiceChains
 	ServiceDescriptor
 	ServiceChainDescriptor
+	Hop
+	ChainStatus
+	FieldViolation
 	ServiceChainDescriptors
+	ServiceRequest
+	ListServicesRequest
+	ListServicesResponse
+	SearchServicesRequest
+	SearchServicesResponse
+	WatchServicesRequest
+	ServiceEvent
+	WatchChainRequest
+	ChainEvent
+	StoredChainEvent
+	FaultDelay
+	FaultRule
+	FaultConfig
+	GetFaultsRequest
 */
 package mygrpc
 
 import proto "github.com/golang/protobuf/proto"
 import fmt "fmt"
 import math "math"
+import google_protobuf "github.com/golang/protobuf/ptypes/timestamp"
 
 import (
 	context "golang.org/x/net/context"
@@ -37,11 +59,79 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
+type EventType int32
+
+const (
+	EventType_UNKNOWN_EVENT EventType = 0
+	// the service is added, or is present when the watch starts
+	EventType_ADDED EventType = 1
+	// the service is modified
+	EventType_UPDATED EventType = 2
+	// the service is removed
+	EventType_DELETED EventType = 3
+	// no watched service is modified up to the resume_token, sent periodically by the
+	// watches skipping the modifications of the other services
+	EventType_PROGRESS EventType = 4
+)
+
+var EventType_name = map[int32]string{
+	0: "UNKNOWN_EVENT",
+	1: "ADDED",
+	2: "UPDATED",
+	3: "DELETED",
+	4: "PROGRESS",
+}
+var EventType_value = map[string]int32{
+	"UNKNOWN_EVENT": 0,
+	"ADDED":         1,
+	"UPDATED":       2,
+	"DELETED":       3,
+	"PROGRESS":      4,
+}
+
+func (x EventType) String() string {
+	return proto.EnumName(EventType_name, int32(x))
+}
+func (EventType) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+type DelayDistribution int32
+
+const (
+	// always delay_ms
+	DelayDistribution_DELAY_FIXED DelayDistribution = 0
+	// uniformly between delay_ms and max_ms
+	DelayDistribution_DELAY_UNIFORM DelayDistribution = 1
+	// exponentially with the mean delay_ms
+	DelayDistribution_DELAY_EXPONENTIAL DelayDistribution = 2
+	// normally with the mean delay_ms and the standard deviation stddev_ms, never below 0
+	DelayDistribution_DELAY_NORMAL DelayDistribution = 3
+)
+
+var DelayDistribution_name = map[int32]string{
+	0: "DELAY_FIXED",
+	1: "DELAY_UNIFORM",
+	2: "DELAY_EXPONENTIAL",
+	3: "DELAY_NORMAL",
+}
+var DelayDistribution_value = map[string]int32{
+	"DELAY_FIXED":       0,
+	"DELAY_UNIFORM":     1,
+	"DELAY_EXPONENTIAL": 2,
+	"DELAY_NORMAL":      3,
+}
+
+func (x DelayDistribution) String() string {
+	return proto.EnumName(DelayDistribution_name, int32(x))
+}
+func (DelayDistribution) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
 type Service struct {
-	// name of the service
+	// name of the service, empty when the entry references a chain
 	SvcName string `protobuf:"bytes,1,opt,name=svc_name,json=svcName" json:"svc_name,omitempty"`
 	// position of the service in the service chain
 	SvcPos int32 `protobuf:"varint,2,opt,name=svc_pos,json=svcPos" json:"svc_pos,omitempty"`
+	// id of a chain stored by the server whose services are expanded in place of this entry
+	ChainRef int32 `protobuf:"varint,3,opt,name=chain_ref,json=chainRef" json:"chain_ref,omitempty"`
 }
 
 func (m *Service) Reset()                    { *m = Service{} }
@@ -63,6 +153,15 @@ func (m *Service) GetSvcPos() int32 {
 	return 0
 }
 
+func (m *Service) GetChainRef() int32 {
+	if m != nil {
+		return m.ChainRef
+	}
+	return 0
+}
+
+// a service chain, or a reference to a chain stored by the server when it holds
+// only its chain_id
 type ServiceChain struct {
 	// unique identifier of the service chain
 	ChainId int32 `protobuf:"varint,1,opt,name=chain_id,json=chainId" json:"chain_id,omitempty"`
@@ -98,14 +197,148 @@ func (m *ServiceChain) GetChain() []*Service {
 	return nil
 }
 
+// a service chain stored by the server, fetched by the requests carrying only its chain_id
+type StoredChain struct {
+	// unique identifier of the service chain
+	ChainId int32 `protobuf:"varint,1,opt,name=chain_id,json=chainId" json:"chain_id,omitempty"`
+	// name of the service chain, e.g. checkout
+	Name string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	// services forming the service chain, whose positions cover 1..the number of services
+	Chain []*Service `protobuf:"bytes,3,rep,name=chain" json:"chain,omitempty"`
+	// time the chain is created, set by the server
+	CreatedAt *google_protobuf.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt" json:"created_at,omitempty"`
+	// time the chain is last created or updated, set by the server
+	UpdatedAt *google_protobuf.Timestamp `protobuf:"bytes,5,opt,name=updated_at,json=updatedAt" json:"updated_at,omitempty"`
+}
+
+func (m *StoredChain) Reset()                    { *m = StoredChain{} }
+func (m *StoredChain) String() string            { return proto.CompactTextString(m) }
+func (*StoredChain) ProtoMessage()               {}
+func (*StoredChain) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *StoredChain) GetChainId() int32 {
+	if m != nil {
+		return m.ChainId
+	}
+	return 0
+}
+
+func (m *StoredChain) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *StoredChain) GetChain() []*Service {
+	if m != nil {
+		return m.Chain
+	}
+	return nil
+}
+
+func (m *StoredChain) GetCreatedAt() *google_protobuf.Timestamp {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return nil
+}
+
+func (m *StoredChain) GetUpdatedAt() *google_protobuf.Timestamp {
+	if m != nil {
+		return m.UpdatedAt
+	}
+	return nil
+}
+
+type ChainRequest struct {
+	// unique identifier of the service chain
+	ChainId int32 `protobuf:"varint,1,opt,name=chain_id,json=chainId" json:"chain_id,omitempty"`
+}
+
+func (m *ChainRequest) Reset()                    { *m = ChainRequest{} }
+func (m *ChainRequest) String() string            { return proto.CompactTextString(m) }
+func (*ChainRequest) ProtoMessage()               {}
+func (*ChainRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *ChainRequest) GetChainId() int32 {
+	if m != nil {
+		return m.ChainId
+	}
+	return 0
+}
+
+type ListChainsRequest struct {
+	// maximal number of chains returned, a default size is used if not positive
+	PageSize int32 `protobuf:"varint,1,opt,name=page_size,json=pageSize" json:"page_size,omitempty"`
+	// token returned by the previous call to get the next page, empty for the first page
+	PageToken string `protobuf:"bytes,2,opt,name=page_token,json=pageToken" json:"page_token,omitempty"`
+}
+
+func (m *ListChainsRequest) Reset()                    { *m = ListChainsRequest{} }
+func (m *ListChainsRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListChainsRequest) ProtoMessage()               {}
+func (*ListChainsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *ListChainsRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *ListChainsRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
+type ListChainsResponse struct {
+	// stored chains in the page
+	Chains []*StoredChain `protobuf:"bytes,1,rep,name=chains" json:"chains,omitempty"`
+	// token to get the next page, empty if this is the last page
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken" json:"next_page_token,omitempty"`
+	// number of stored chains
+	TotalSize int32 `protobuf:"varint,3,opt,name=total_size,json=totalSize" json:"total_size,omitempty"`
+}
+
+func (m *ListChainsResponse) Reset()                    { *m = ListChainsResponse{} }
+func (m *ListChainsResponse) String() string            { return proto.CompactTextString(m) }
+func (*ListChainsResponse) ProtoMessage()               {}
+func (*ListChainsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *ListChainsResponse) GetChains() []*StoredChain {
+	if m != nil {
+		return m.Chains
+	}
+	return nil
+}
+
+func (m *ListChainsResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
+func (m *ListChainsResponse) GetTotalSize() int32 {
+	if m != nil {
+		return m.TotalSize
+	}
+	return 0
+}
+
 type ServiceChains struct {
 	Chains []*ServiceChain `protobuf:"bytes,1,rep,name=chains" json:"chains,omitempty"`
+	// whether a failing chain is reported by its own status instead of failing the whole stream
+	PartialResults bool `protobuf:"varint,2,opt,name=partial_results,json=partialResults" json:"partial_results,omitempty"`
 }
 
 func (m *ServiceChains) Reset()                    { *m = ServiceChains{} }
 func (m *ServiceChains) String() string            { return proto.CompactTextString(m) }
 func (*ServiceChains) ProtoMessage()               {}
-func (*ServiceChains) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+func (*ServiceChains) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
 
 func (m *ServiceChains) GetChains() []*ServiceChain {
 	if m != nil {
@@ -114,6 +347,13 @@ func (m *ServiceChains) GetChains() []*ServiceChain {
 	return nil
 }
 
+func (m *ServiceChains) GetPartialResults() bool {
+	if m != nil {
+		return m.PartialResults
+	}
+	return false
+}
+
 type ServiceDescriptor struct {
 	// name of the service
 	SvcName string `protobuf:"bytes,1,opt,name=svc_name,json=svcName" json:"svc_name,omitempty"`
@@ -121,12 +361,26 @@ type ServiceDescriptor struct {
 	SvcDesc string `protobuf:"bytes,2,opt,name=svc_desc,json=svcDesc" json:"svc_desc,omitempty"`
 	// position of the service in the service chain
 	SvcPos int32 `protobuf:"varint,3,opt,name=svc_pos,json=svcPos" json:"svc_pos,omitempty"`
+	// version of the service, e.g. 1.4.2
+	Version string `protobuf:"bytes,4,opt,name=version" json:"version,omitempty"`
+	// addresses of the instances of the service as host:port
+	Endpoints []string `protobuf:"bytes,5,rep,name=endpoints" json:"endpoints,omitempty"`
+	// free-form labels of the service, e.g. tier: backend
+	Labels map[string]string `protobuf:"bytes,6,rep,name=labels" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// team or person owning the service
+	Owner string `protobuf:"bytes,7,opt,name=owner" json:"owner,omitempty"`
+	// time the service is registered, set by the server
+	CreatedAt *google_protobuf.Timestamp `protobuf:"bytes,8,opt,name=created_at,json=createdAt" json:"created_at,omitempty"`
+	// time the service is last registered or updated, set by the server
+	UpdatedAt *google_protobuf.Timestamp `protobuf:"bytes,9,opt,name=updated_at,json=updatedAt" json:"updated_at,omitempty"`
+	// ids of the nested chains a service of a resolved chain is expanded from, outermost first
+	ChainPath []int32 `protobuf:"varint,10,rep,name=chain_path,json=chainPath,packed" json:"chain_path,omitempty"`
 }
 
 func (m *ServiceDescriptor) Reset()                    { *m = ServiceDescriptor{} }
 func (m *ServiceDescriptor) String() string            { return proto.CompactTextString(m) }
 func (*ServiceDescriptor) ProtoMessage()               {}
-func (*ServiceDescriptor) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+func (*ServiceDescriptor) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
 
 func (m *ServiceDescriptor) GetSvcName() string {
 	if m != nil {
@@ -149,6 +403,55 @@ func (m *ServiceDescriptor) GetSvcPos() int32 {
 	return 0
 }
 
+func (m *ServiceDescriptor) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *ServiceDescriptor) GetEndpoints() []string {
+	if m != nil {
+		return m.Endpoints
+	}
+	return nil
+}
+
+func (m *ServiceDescriptor) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *ServiceDescriptor) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *ServiceDescriptor) GetCreatedAt() *google_protobuf.Timestamp {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return nil
+}
+
+func (m *ServiceDescriptor) GetUpdatedAt() *google_protobuf.Timestamp {
+	if m != nil {
+		return m.UpdatedAt
+	}
+	return nil
+}
+
+func (m *ServiceDescriptor) GetChainPath() []int32 {
+	if m != nil {
+		return m.ChainPath
+	}
+	return nil
+}
+
 type ServiceChainDescriptor struct {
 	// unique identifier of the service chain
 	ChainId int32 `protobuf:"varint,1,opt,name=chain_id,json=chainId" json:"chain_id,omitempty"`
@@ -156,12 +459,16 @@ type ServiceChainDescriptor struct {
 	ChainLen int32 `protobuf:"varint,2,opt,name=chain_len,json=chainLen" json:"chain_len,omitempty"`
 	// descriptions of the services froming the service chain
 	ChainDesc []*ServiceDescriptor `protobuf:"bytes,3,rep,name=chain_desc,json=chainDesc" json:"chain_desc,omitempty"`
+	// error resolving the service chain in the partial results mode, in which case chain_desc is empty
+	Status *ChainStatus `protobuf:"bytes,4,opt,name=status" json:"status,omitempty"`
+	// hops the service chain went through when executed by forwarding between servers, in order
+	Hops []*Hop `protobuf:"bytes,5,rep,name=hops" json:"hops,omitempty"`
 }
 
 func (m *ServiceChainDescriptor) Reset()                    { *m = ServiceChainDescriptor{} }
 func (m *ServiceChainDescriptor) String() string            { return proto.CompactTextString(m) }
 func (*ServiceChainDescriptor) ProtoMessage()               {}
-func (*ServiceChainDescriptor) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+func (*ServiceChainDescriptor) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
 
 func (m *ServiceChainDescriptor) GetChainId() int32 {
 	if m != nil {
@@ -184,329 +491,1752 @@ func (m *ServiceChainDescriptor) GetChainDesc() []*ServiceDescriptor {
 	return nil
 }
 
-type ServiceChainDescriptors struct {
-	ChainDescs []*ServiceChainDescriptor `protobuf:"bytes,1,rep,name=chain_descs,json=chainDescs" json:"chain_descs,omitempty"`
+func (m *ServiceChainDescriptor) GetStatus() *ChainStatus {
+	if m != nil {
+		return m.Status
+	}
+	return nil
 }
 
-func (m *ServiceChainDescriptors) Reset()                    { *m = ServiceChainDescriptors{} }
-func (m *ServiceChainDescriptors) String() string            { return proto.CompactTextString(m) }
-func (*ServiceChainDescriptors) ProtoMessage()               {}
-func (*ServiceChainDescriptors) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
-
-func (m *ServiceChainDescriptors) GetChainDescs() []*ServiceChainDescriptor {
+func (m *ServiceChainDescriptor) GetHops() []*Hop {
 	if m != nil {
-		return m.ChainDescs
+		return m.Hops
 	}
 	return nil
 }
 
-func init() {
-	proto.RegisterType((*Service)(nil), "mygrpc.Service")
-	proto.RegisterType((*ServiceChain)(nil), "mygrpc.ServiceChain")
-	proto.RegisterType((*ServiceChains)(nil), "mygrpc.ServiceChains")
-	proto.RegisterType((*ServiceDescriptor)(nil), "mygrpc.ServiceDescriptor")
-	proto.RegisterType((*ServiceChainDescriptor)(nil), "mygrpc.ServiceChainDescriptor")
-	proto.RegisterType((*ServiceChainDescriptors)(nil), "mygrpc.ServiceChainDescriptors")
+// a server executing a service chain in the chain forwarding mode
+type Hop struct {
+	// name of the service providing by the server
+	SvcName string `protobuf:"bytes,1,opt,name=svc_name,json=svcName" json:"svc_name,omitempty"`
+	// position of the service in the chain, 0 for a server outside the chain forwarding to its first service
+	SvcPos int32 `protobuf:"varint,2,opt,name=svc_pos,json=svcPos" json:"svc_pos,omitempty"`
+	// pod, or host, serving the hop
+	Pod string `protobuf:"bytes,3,opt,name=pod" json:"pod,omitempty"`
+	// unix time in nanoseconds when the hop received the service chain
+	StartTimeNs int64 `protobuf:"varint,4,opt,name=start_time_ns,json=startTimeNs" json:"start_time_ns,omitempty"`
+	// time in nanoseconds spent by the hop, including the following hops
+	ElapsedNs int64 `protobuf:"varint,5,opt,name=elapsed_ns,json=elapsedNs" json:"elapsed_ns,omitempty"`
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
-
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
-
-// Client API for MyGrpc service
+func (m *Hop) Reset()                    { *m = Hop{} }
+func (m *Hop) String() string            { return proto.CompactTextString(m) }
+func (*Hop) ProtoMessage()               {}
+func (*Hop) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
 
-type MyGrpcClient interface {
-	// Get a service chain descriptor according to a request indicating to a single chain
-	GetChainReqResp(ctx context.Context, in *ServiceChain, opts ...grpc.CallOption) (*ServiceChainDescriptor, error)
-	// Get service chain descriptors according to a request indicating to multiple chains
-	GetChainsReqResps(ctx context.Context, in *ServiceChains, opts ...grpc.CallOption) (MyGrpc_GetChainsReqRespsClient, error)
-	// Get a descriptor describing multiple service chains according to requests of which indicating to a single chain each
-	GetChainsReqsResp(ctx context.Context, opts ...grpc.CallOption) (MyGrpc_GetChainsReqsRespClient, error)
-	// Get multiple service chain descriptors according to requests of which indicating to a single chain each
-	GetChainsReqsResps(ctx context.Context, opts ...grpc.CallOption) (MyGrpc_GetChainsReqsRespsClient, error)
+func (m *Hop) GetSvcName() string {
+	if m != nil {
+		return m.SvcName
+	}
+	return ""
 }
 
-type myGrpcClient struct {
-	cc *grpc.ClientConn
+func (m *Hop) GetSvcPos() int32 {
+	if m != nil {
+		return m.SvcPos
+	}
+	return 0
 }
 
-func NewMyGrpcClient(cc *grpc.ClientConn) MyGrpcClient {
-	return &myGrpcClient{cc}
+func (m *Hop) GetPod() string {
+	if m != nil {
+		return m.Pod
+	}
+	return ""
 }
 
-func (c *myGrpcClient) GetChainReqResp(ctx context.Context, in *ServiceChain, opts ...grpc.CallOption) (*ServiceChainDescriptor, error) {
-	out := new(ServiceChainDescriptor)
-	err := grpc.Invoke(ctx, "/mygrpc.MyGrpc/GetChainReqResp", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+func (m *Hop) GetStartTimeNs() int64 {
+	if m != nil {
+		return m.StartTimeNs
 	}
-	return out, nil
+	return 0
 }
 
-func (c *myGrpcClient) GetChainsReqResps(ctx context.Context, in *ServiceChains, opts ...grpc.CallOption) (MyGrpc_GetChainsReqRespsClient, error) {
-	stream, err := grpc.NewClientStream(ctx, &_MyGrpc_serviceDesc.Streams[0], c.cc, "/mygrpc.MyGrpc/GetChainsReqResps", opts...)
-	if err != nil {
-		return nil, err
-	}
-	x := &myGrpcGetChainsReqRespsClient{stream}
-	if err := x.ClientStream.SendMsg(in); err != nil {
-		return nil, err
-	}
-	if err := x.ClientStream.CloseSend(); err != nil {
-		return nil, err
+func (m *Hop) GetElapsedNs() int64 {
+	if m != nil {
+		return m.ElapsedNs
 	}
-	return x, nil
+	return 0
 }
 
-type MyGrpc_GetChainsReqRespsClient interface {
-	Recv() (*ServiceChainDescriptor, error)
-	grpc.ClientStream
+// result of a single service chain failing in the partial results mode
+type ChainStatus struct {
+	// grpc status code
+	Code int32 `protobuf:"varint,1,opt,name=code" json:"code,omitempty"`
+	// description of the error
+	Message string `protobuf:"bytes,2,opt,name=message" json:"message,omitempty"`
+	// machine-readable reason of the error, e.g. SERVICE_NOT_FOUND
+	Reason string `protobuf:"bytes,3,opt,name=reason" json:"reason,omitempty"`
+	// fields of the service chain causing the error
+	Violations []*FieldViolation `protobuf:"bytes,4,rep,name=violations" json:"violations,omitempty"`
 }
 
-type myGrpcGetChainsReqRespsClient struct {
-	grpc.ClientStream
-}
+func (m *ChainStatus) Reset()                    { *m = ChainStatus{} }
+func (m *ChainStatus) String() string            { return proto.CompactTextString(m) }
+func (*ChainStatus) ProtoMessage()               {}
+func (*ChainStatus) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
 
-func (x *myGrpcGetChainsReqRespsClient) Recv() (*ServiceChainDescriptor, error) {
-	m := new(ServiceChainDescriptor)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
-		return nil, err
+func (m *ChainStatus) GetCode() int32 {
+	if m != nil {
+		return m.Code
 	}
-	return m, nil
+	return 0
 }
 
-func (c *myGrpcClient) GetChainsReqsResp(ctx context.Context, opts ...grpc.CallOption) (MyGrpc_GetChainsReqsRespClient, error) {
-	stream, err := grpc.NewClientStream(ctx, &_MyGrpc_serviceDesc.Streams[1], c.cc, "/mygrpc.MyGrpc/GetChainsReqsResp", opts...)
-	if err != nil {
-		return nil, err
+func (m *ChainStatus) GetMessage() string {
+	if m != nil {
+		return m.Message
 	}
-	x := &myGrpcGetChainsReqsRespClient{stream}
-	return x, nil
+	return ""
 }
 
-type MyGrpc_GetChainsReqsRespClient interface {
-	Send(*ServiceChain) error
-	CloseAndRecv() (*ServiceChainDescriptors, error)
-	grpc.ClientStream
+func (m *ChainStatus) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
 }
 
-type myGrpcGetChainsReqsRespClient struct {
-	grpc.ClientStream
+func (m *ChainStatus) GetViolations() []*FieldViolation {
+	if m != nil {
+		return m.Violations
+	}
+	return nil
 }
 
-func (x *myGrpcGetChainsReqsRespClient) Send(m *ServiceChain) error {
-	return x.ClientStream.SendMsg(m)
+type FieldViolation struct {
+	// path of the field in the service chain, e.g. chain[1].svc_name
+	Field string `protobuf:"bytes,1,opt,name=field" json:"field,omitempty"`
+	// description of the problem
+	Description string `protobuf:"bytes,2,opt,name=description" json:"description,omitempty"`
 }
 
-func (x *myGrpcGetChainsReqsRespClient) CloseAndRecv() (*ServiceChainDescriptors, error) {
-	if err := x.ClientStream.CloseSend(); err != nil {
-		return nil, err
-	}
-	m := new(ServiceChainDescriptors)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
-		return nil, err
+func (m *FieldViolation) Reset()                    { *m = FieldViolation{} }
+func (m *FieldViolation) String() string            { return proto.CompactTextString(m) }
+func (*FieldViolation) ProtoMessage()               {}
+func (*FieldViolation) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
+func (m *FieldViolation) GetField() string {
+	if m != nil {
+		return m.Field
 	}
-	return m, nil
+	return ""
 }
 
-func (c *myGrpcClient) GetChainsReqsResps(ctx context.Context, opts ...grpc.CallOption) (MyGrpc_GetChainsReqsRespsClient, error) {
+func (m *FieldViolation) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
+}
+
+type ServiceChainDescriptors struct {
+	ChainDescs []*ServiceChainDescriptor `protobuf:"bytes,1,rep,name=chain_descs,json=chainDescs" json:"chain_descs,omitempty"`
+}
+
+func (m *ServiceChainDescriptors) Reset()                    { *m = ServiceChainDescriptors{} }
+func (m *ServiceChainDescriptors) String() string            { return proto.CompactTextString(m) }
+func (*ServiceChainDescriptors) ProtoMessage()               {}
+func (*ServiceChainDescriptors) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+func (m *ServiceChainDescriptors) GetChainDescs() []*ServiceChainDescriptor {
+	if m != nil {
+		return m.ChainDescs
+	}
+	return nil
+}
+
+type ServiceRequest struct {
+	// name of the service
+	SvcName string `protobuf:"bytes,1,opt,name=svc_name,json=svcName" json:"svc_name,omitempty"`
+}
+
+func (m *ServiceRequest) Reset()                    { *m = ServiceRequest{} }
+func (m *ServiceRequest) String() string            { return proto.CompactTextString(m) }
+func (*ServiceRequest) ProtoMessage()               {}
+func (*ServiceRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
+func (m *ServiceRequest) GetSvcName() string {
+	if m != nil {
+		return m.SvcName
+	}
+	return ""
+}
+
+type ListServicesRequest struct {
+	// maximal number of services returned, a default size is used if not positive
+	PageSize int32 `protobuf:"varint,1,opt,name=page_size,json=pageSize" json:"page_size,omitempty"`
+	// token returned by the previous call to get the next page, empty for the first page
+	PageToken string `protobuf:"bytes,2,opt,name=page_token,json=pageToken" json:"page_token,omitempty"`
+}
+
+func (m *ListServicesRequest) Reset()                    { *m = ListServicesRequest{} }
+func (m *ListServicesRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListServicesRequest) ProtoMessage()               {}
+func (*ListServicesRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+func (m *ListServicesRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *ListServicesRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
+type ListServicesResponse struct {
+	// descriptors of the services in the page
+	Services []*ServiceDescriptor `protobuf:"bytes,1,rep,name=services" json:"services,omitempty"`
+	// token to get the next page, empty if this is the last page
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken" json:"next_page_token,omitempty"`
+	// number of services in the registry
+	TotalSize int32 `protobuf:"varint,3,opt,name=total_size,json=totalSize" json:"total_size,omitempty"`
+}
+
+func (m *ListServicesResponse) Reset()                    { *m = ListServicesResponse{} }
+func (m *ListServicesResponse) String() string            { return proto.CompactTextString(m) }
+func (*ListServicesResponse) ProtoMessage()               {}
+func (*ListServicesResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
+func (m *ListServicesResponse) GetServices() []*ServiceDescriptor {
+	if m != nil {
+		return m.Services
+	}
+	return nil
+}
+
+func (m *ListServicesResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
+func (m *ListServicesResponse) GetTotalSize() int32 {
+	if m != nil {
+		return m.TotalSize
+	}
+	return 0
+}
+
+type SearchServicesRequest struct {
+	// kubernetes-style label selector, e.g. "tier=backend,env in (prod,staging),!deprecated", any labels if empty
+	LabelSelector string `protobuf:"bytes,1,opt,name=label_selector,json=labelSelector" json:"label_selector,omitempty"`
+	// prefix of the service names, or a glob pattern if it holds any of *?[, e.g. svc*-v2, any name if empty
+	Name string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	// words all found in the descriptions of the services, case-insensitively, any description if empty
+	Query string `protobuf:"bytes,3,opt,name=query" json:"query,omitempty"`
+	// maximal number of services returned, a default size is used if not positive
+	PageSize int32 `protobuf:"varint,4,opt,name=page_size,json=pageSize" json:"page_size,omitempty"`
+	// token returned by the previous call to get the next page, empty for the first page
+	PageToken string `protobuf:"bytes,5,opt,name=page_token,json=pageToken" json:"page_token,omitempty"`
+}
+
+func (m *SearchServicesRequest) Reset()                    { *m = SearchServicesRequest{} }
+func (m *SearchServicesRequest) String() string            { return proto.CompactTextString(m) }
+func (*SearchServicesRequest) ProtoMessage()               {}
+func (*SearchServicesRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+
+func (m *SearchServicesRequest) GetLabelSelector() string {
+	if m != nil {
+		return m.LabelSelector
+	}
+	return ""
+}
+
+func (m *SearchServicesRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *SearchServicesRequest) GetQuery() string {
+	if m != nil {
+		return m.Query
+	}
+	return ""
+}
+
+func (m *SearchServicesRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *SearchServicesRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
+type SearchServicesResponse struct {
+	// descriptors of the services in the page
+	Services []*ServiceDescriptor `protobuf:"bytes,1,rep,name=services" json:"services,omitempty"`
+	// token to get the next page, empty if this is the last page
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken" json:"next_page_token,omitempty"`
+	// number of services matching the search
+	TotalSize int32 `protobuf:"varint,3,opt,name=total_size,json=totalSize" json:"total_size,omitempty"`
+}
+
+func (m *SearchServicesResponse) Reset()                    { *m = SearchServicesResponse{} }
+func (m *SearchServicesResponse) String() string            { return proto.CompactTextString(m) }
+func (*SearchServicesResponse) ProtoMessage()               {}
+func (*SearchServicesResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+
+func (m *SearchServicesResponse) GetServices() []*ServiceDescriptor {
+	if m != nil {
+		return m.Services
+	}
+	return nil
+}
+
+func (m *SearchServicesResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
+func (m *SearchServicesResponse) GetTotalSize() int32 {
+	if m != nil {
+		return m.TotalSize
+	}
+	return 0
+}
+
+type WatchServicesRequest struct {
+	// names of the services to watch, all services are watched if empty
+	SvcNames []string `protobuf:"bytes,1,rep,name=svc_names,json=svcNames" json:"svc_names,omitempty"`
+	// token of the last event received by a previous watch to resume from, empty to start a new watch
+	ResumeToken string `protobuf:"bytes,2,opt,name=resume_token,json=resumeToken" json:"resume_token,omitempty"`
+	// whether to first receive an ADDED event for every current service when starting a new watch
+	SendInitial bool `protobuf:"varint,3,opt,name=send_initial,json=sendInitial" json:"send_initial,omitempty"`
+}
+
+func (m *WatchServicesRequest) Reset()                    { *m = WatchServicesRequest{} }
+func (m *WatchServicesRequest) String() string            { return proto.CompactTextString(m) }
+func (*WatchServicesRequest) ProtoMessage()               {}
+func (*WatchServicesRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+
+func (m *WatchServicesRequest) GetSvcNames() []string {
+	if m != nil {
+		return m.SvcNames
+	}
+	return nil
+}
+
+func (m *WatchServicesRequest) GetResumeToken() string {
+	if m != nil {
+		return m.ResumeToken
+	}
+	return ""
+}
+
+func (m *WatchServicesRequest) GetSendInitial() bool {
+	if m != nil {
+		return m.SendInitial
+	}
+	return false
+}
+
+type ServiceEvent struct {
+	// type of the modification
+	Type EventType `protobuf:"varint,1,opt,name=type,enum=mygrpc.EventType" json:"type,omitempty"`
+	// new descriptor of the service, or the last one when deleted, empty for PROGRESS
+	Service *ServiceDescriptor `protobuf:"bytes,2,opt,name=service" json:"service,omitempty"`
+	// token to resume a watch right after this event
+	ResumeToken string `protobuf:"bytes,3,opt,name=resume_token,json=resumeToken" json:"resume_token,omitempty"`
+}
+
+func (m *ServiceEvent) Reset()                    { *m = ServiceEvent{} }
+func (m *ServiceEvent) String() string            { return proto.CompactTextString(m) }
+func (*ServiceEvent) ProtoMessage()               {}
+func (*ServiceEvent) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+
+func (m *ServiceEvent) GetType() EventType {
+	if m != nil {
+		return m.Type
+	}
+	return EventType_UNKNOWN_EVENT
+}
+
+func (m *ServiceEvent) GetService() *ServiceDescriptor {
+	if m != nil {
+		return m.Service
+	}
+	return nil
+}
+
+func (m *ServiceEvent) GetResumeToken() string {
+	if m != nil {
+		return m.ResumeToken
+	}
+	return ""
+}
+
+type WatchChainRequest struct {
+	// service chain to watch, identified by its chain_id in the events
+	Chain *ServiceChain `protobuf:"bytes,1,opt,name=chain" json:"chain,omitempty"`
+	// token of the last event received by a previous watch to resume from, empty to start a new watch
+	ResumeToken string `protobuf:"bytes,2,opt,name=resume_token,json=resumeToken" json:"resume_token,omitempty"`
+}
+
+func (m *WatchChainRequest) Reset()                    { *m = WatchChainRequest{} }
+func (m *WatchChainRequest) String() string            { return proto.CompactTextString(m) }
+func (*WatchChainRequest) ProtoMessage()               {}
+func (*WatchChainRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+
+func (m *WatchChainRequest) GetChain() *ServiceChain {
+	if m != nil {
+		return m.Chain
+	}
+	return nil
+}
+
+func (m *WatchChainRequest) GetResumeToken() string {
+	if m != nil {
+		return m.ResumeToken
+	}
+	return ""
+}
+
+type ChainEvent struct {
+	// ADDED for the first event of a new watch, PROGRESS when only the resume_token
+	// advances, else UPDATED
+	Type EventType `protobuf:"varint,1,opt,name=type,enum=mygrpc.EventType" json:"type,omitempty"`
+	// unique identifier of the service chain
+	ChainId int32 `protobuf:"varint,2,opt,name=chain_id,json=chainId" json:"chain_id,omitempty"`
+	// descriptor of the service chain as of the event, empty if it cannot be resolved or for PROGRESS
+	ChainDesc *ServiceChainDescriptor `protobuf:"bytes,3,opt,name=chain_desc,json=chainDesc" json:"chain_desc,omitempty"`
+	// reason the service chain cannot be resolved
+	Error string `protobuf:"bytes,4,opt,name=error" json:"error,omitempty"`
+	// modification of the service causing this event, empty for the first event
+	Cause *ServiceEvent `protobuf:"bytes,5,opt,name=cause" json:"cause,omitempty"`
+	// token to resume a watch right after this event
+	ResumeToken string `protobuf:"bytes,6,opt,name=resume_token,json=resumeToken" json:"resume_token,omitempty"`
+	// modification of a stored chain referenced by the watched chain causing this event,
+	// empty for the first event or when a service is modified
+	ChainCause *StoredChainEvent `protobuf:"bytes,7,opt,name=chain_cause,json=chainCause" json:"chain_cause,omitempty"`
+}
+
+func (m *ChainEvent) Reset()                    { *m = ChainEvent{} }
+func (m *ChainEvent) String() string            { return proto.CompactTextString(m) }
+func (*ChainEvent) ProtoMessage()               {}
+func (*ChainEvent) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
+
+func (m *ChainEvent) GetType() EventType {
+	if m != nil {
+		return m.Type
+	}
+	return EventType_UNKNOWN_EVENT
+}
+
+func (m *ChainEvent) GetChainId() int32 {
+	if m != nil {
+		return m.ChainId
+	}
+	return 0
+}
+
+func (m *ChainEvent) GetChainDesc() *ServiceChainDescriptor {
+	if m != nil {
+		return m.ChainDesc
+	}
+	return nil
+}
+
+func (m *ChainEvent) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *ChainEvent) GetCause() *ServiceEvent {
+	if m != nil {
+		return m.Cause
+	}
+	return nil
+}
+
+func (m *ChainEvent) GetResumeToken() string {
+	if m != nil {
+		return m.ResumeToken
+	}
+	return ""
+}
+
+func (m *ChainEvent) GetChainCause() *StoredChainEvent {
+	if m != nil {
+		return m.ChainCause
+	}
+	return nil
+}
+
+type StoredChainEvent struct {
+	// type of the modification
+	Type EventType `protobuf:"varint,1,opt,name=type,enum=mygrpc.EventType" json:"type,omitempty"`
+	// new stored chain, or the last one when deleted
+	Chain *StoredChain `protobuf:"bytes,2,opt,name=chain" json:"chain,omitempty"`
+}
+
+func (m *StoredChainEvent) Reset()                    { *m = StoredChainEvent{} }
+func (m *StoredChainEvent) String() string            { return proto.CompactTextString(m) }
+func (*StoredChainEvent) ProtoMessage()               {}
+func (*StoredChainEvent) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
+
+func (m *StoredChainEvent) GetType() EventType {
+	if m != nil {
+		return m.Type
+	}
+	return EventType_UNKNOWN_EVENT
+}
+
+func (m *StoredChainEvent) GetChain() *StoredChain {
+	if m != nil {
+		return m.Chain
+	}
+	return nil
+}
+
+type FaultDelay struct {
+	// distribution of the delays
+	Distribution DelayDistribution `protobuf:"varint,1,opt,name=distribution,enum=mygrpc.DelayDistribution" json:"distribution,omitempty"`
+	// the fixed delay, the minimum of the uniform distribution or the mean of the others, in milliseconds
+	DelayMs int64 `protobuf:"varint,2,opt,name=delay_ms,json=delayMs" json:"delay_ms,omitempty"`
+	// the maximum of the uniform distribution in milliseconds
+	MaxMs int64 `protobuf:"varint,3,opt,name=max_ms,json=maxMs" json:"max_ms,omitempty"`
+	// the standard deviation of the normal distribution in milliseconds
+	StddevMs int64 `protobuf:"varint,4,opt,name=stddev_ms,json=stddevMs" json:"stddev_ms,omitempty"`
+	// fraction of the requests delayed between 0 and 1, all of them if 0
+	Fraction float64 `protobuf:"fixed64,5,opt,name=fraction" json:"fraction,omitempty"`
+}
+
+func (m *FaultDelay) Reset()                    { *m = FaultDelay{} }
+func (m *FaultDelay) String() string            { return proto.CompactTextString(m) }
+func (*FaultDelay) ProtoMessage()               {}
+func (*FaultDelay) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
+
+func (m *FaultDelay) GetDistribution() DelayDistribution {
+	if m != nil {
+		return m.Distribution
+	}
+	return DelayDistribution_DELAY_FIXED
+}
+
+func (m *FaultDelay) GetDelayMs() int64 {
+	if m != nil {
+		return m.DelayMs
+	}
+	return 0
+}
+
+func (m *FaultDelay) GetMaxMs() int64 {
+	if m != nil {
+		return m.MaxMs
+	}
+	return 0
+}
+
+func (m *FaultDelay) GetStddevMs() int64 {
+	if m != nil {
+		return m.StddevMs
+	}
+	return 0
+}
+
+func (m *FaultDelay) GetFraction() float64 {
+	if m != nil {
+		return m.Fraction
+	}
+	return 0
+}
+
+type FaultRule struct {
+	// full rpc methods the rule applies to as glob patterns, e.g. /mygrpc.MyGrpc/Get*, all if empty
+	Methods []string `protobuf:"bytes,1,rep,name=methods" json:"methods,omitempty"`
+	// names of the services of the servers the rule applies to as glob patterns, all if empty
+	SvcNames []string `protobuf:"bytes,2,rep,name=svc_names,json=svcNames" json:"svc_names,omitempty"`
+	// ids of the service chains the rule applies to, all if empty
+	ChainIds []int32 `protobuf:"varint,3,rep,name=chain_ids,json=chainIds,packed" json:"chain_ids,omitempty"`
+	// delay added before handling a request, including every request of a stream
+	Delay *FaultDelay `protobuf:"bytes,4,opt,name=delay" json:"delay,omitempty"`
+	// fraction of the requests failed between 0 and 1
+	ErrorRate float64 `protobuf:"fixed64,5,opt,name=error_rate,json=errorRate" json:"error_rate,omitempty"`
+	// grpc status code of the failed requests and aborted streams, UNAVAILABLE if 0
+	ErrorCode int32 `protobuf:"varint,6,opt,name=error_code,json=errorCode" json:"error_code,omitempty"`
+	// message of the failed requests and aborted streams
+	ErrorMessage string `protobuf:"bytes,7,opt,name=error_message,json=errorMessage" json:"error_message,omitempty"`
+	// number of messages a stream sends before it is aborted, never aborted if 0
+	AbortAfter int32 `protobuf:"varint,8,opt,name=abort_after,json=abortAfter" json:"abort_after,omitempty"`
+	// delay added before every message sent on a stream in milliseconds
+	SendDelayMs int64 `protobuf:"varint,9,opt,name=send_delay_ms,json=sendDelayMs" json:"send_delay_ms,omitempty"`
+}
+
+func (m *FaultRule) Reset()                    { *m = FaultRule{} }
+func (m *FaultRule) String() string            { return proto.CompactTextString(m) }
+func (*FaultRule) ProtoMessage()               {}
+func (*FaultRule) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
+
+func (m *FaultRule) GetMethods() []string {
+	if m != nil {
+		return m.Methods
+	}
+	return nil
+}
+
+func (m *FaultRule) GetSvcNames() []string {
+	if m != nil {
+		return m.SvcNames
+	}
+	return nil
+}
+
+func (m *FaultRule) GetChainIds() []int32 {
+	if m != nil {
+		return m.ChainIds
+	}
+	return nil
+}
+
+func (m *FaultRule) GetDelay() *FaultDelay {
+	if m != nil {
+		return m.Delay
+	}
+	return nil
+}
+
+func (m *FaultRule) GetErrorRate() float64 {
+	if m != nil {
+		return m.ErrorRate
+	}
+	return 0
+}
+
+func (m *FaultRule) GetErrorCode() int32 {
+	if m != nil {
+		return m.ErrorCode
+	}
+	return 0
+}
+
+func (m *FaultRule) GetErrorMessage() string {
+	if m != nil {
+		return m.ErrorMessage
+	}
+	return ""
+}
+
+func (m *FaultRule) GetAbortAfter() int32 {
+	if m != nil {
+		return m.AbortAfter
+	}
+	return 0
+}
+
+func (m *FaultRule) GetSendDelayMs() int64 {
+	if m != nil {
+		return m.SendDelayMs
+	}
+	return 0
+}
+
+type FaultConfig struct {
+	// rules of the faults, the first rule applying to a request deciding its faults
+	Rules []*FaultRule `protobuf:"bytes,1,rep,name=rules" json:"rules,omitempty"`
+}
+
+func (m *FaultConfig) Reset()                    { *m = FaultConfig{} }
+func (m *FaultConfig) String() string            { return proto.CompactTextString(m) }
+func (*FaultConfig) ProtoMessage()               {}
+func (*FaultConfig) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
+
+func (m *FaultConfig) GetRules() []*FaultRule {
+	if m != nil {
+		return m.Rules
+	}
+	return nil
+}
+
+type GetFaultsRequest struct {
+}
+
+func (m *GetFaultsRequest) Reset()                    { *m = GetFaultsRequest{} }
+func (m *GetFaultsRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetFaultsRequest) ProtoMessage()               {}
+func (*GetFaultsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{26} }
+
+func init() {
+	proto.RegisterType((*Service)(nil), "mygrpc.Service")
+	proto.RegisterType((*ServiceChain)(nil), "mygrpc.ServiceChain")
+	proto.RegisterType((*StoredChain)(nil), "mygrpc.StoredChain")
+	proto.RegisterType((*ChainRequest)(nil), "mygrpc.ChainRequest")
+	proto.RegisterType((*ListChainsRequest)(nil), "mygrpc.ListChainsRequest")
+	proto.RegisterType((*ListChainsResponse)(nil), "mygrpc.ListChainsResponse")
+	proto.RegisterType((*ServiceChains)(nil), "mygrpc.ServiceChains")
+	proto.RegisterType((*ServiceDescriptor)(nil), "mygrpc.ServiceDescriptor")
+	proto.RegisterType((*ServiceChainDescriptor)(nil), "mygrpc.ServiceChainDescriptor")
+	proto.RegisterType((*Hop)(nil), "mygrpc.Hop")
+	proto.RegisterType((*ChainStatus)(nil), "mygrpc.ChainStatus")
+	proto.RegisterType((*FieldViolation)(nil), "mygrpc.FieldViolation")
+	proto.RegisterType((*ServiceChainDescriptors)(nil), "mygrpc.ServiceChainDescriptors")
+	proto.RegisterType((*ServiceRequest)(nil), "mygrpc.ServiceRequest")
+	proto.RegisterType((*ListServicesRequest)(nil), "mygrpc.ListServicesRequest")
+	proto.RegisterType((*ListServicesResponse)(nil), "mygrpc.ListServicesResponse")
+	proto.RegisterType((*SearchServicesRequest)(nil), "mygrpc.SearchServicesRequest")
+	proto.RegisterType((*SearchServicesResponse)(nil), "mygrpc.SearchServicesResponse")
+	proto.RegisterType((*WatchServicesRequest)(nil), "mygrpc.WatchServicesRequest")
+	proto.RegisterType((*ServiceEvent)(nil), "mygrpc.ServiceEvent")
+	proto.RegisterType((*WatchChainRequest)(nil), "mygrpc.WatchChainRequest")
+	proto.RegisterType((*ChainEvent)(nil), "mygrpc.ChainEvent")
+	proto.RegisterType((*StoredChainEvent)(nil), "mygrpc.StoredChainEvent")
+	proto.RegisterType((*FaultDelay)(nil), "mygrpc.FaultDelay")
+	proto.RegisterType((*FaultRule)(nil), "mygrpc.FaultRule")
+	proto.RegisterType((*FaultConfig)(nil), "mygrpc.FaultConfig")
+	proto.RegisterType((*GetFaultsRequest)(nil), "mygrpc.GetFaultsRequest")
+	proto.RegisterEnum("mygrpc.EventType", EventType_name, EventType_value)
+	proto.RegisterEnum("mygrpc.DelayDistribution", DelayDistribution_name, DelayDistribution_value)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for MyGrpc service
+
+type MyGrpcClient interface {
+	// Get a service chain descriptor according to a request indicating to a single chain
+	GetChainReqResp(ctx context.Context, in *ServiceChain, opts ...grpc.CallOption) (*ServiceChainDescriptor, error)
+	// Get service chain descriptors according to a request indicating to multiple chains
+	GetChainsReqResps(ctx context.Context, in *ServiceChains, opts ...grpc.CallOption) (MyGrpc_GetChainsReqRespsClient, error)
+	// Get a descriptor describing multiple service chains according to requests of which indicating to a single chain each
+	GetChainsReqsResp(ctx context.Context, opts ...grpc.CallOption) (MyGrpc_GetChainsReqsRespClient, error)
+	// Get multiple service chain descriptors according to requests of which indicating to a single chain each
+	GetChainsReqsResps(ctx context.Context, opts ...grpc.CallOption) (MyGrpc_GetChainsReqsRespsClient, error)
+	// Watch the modifications of services
+	WatchServices(ctx context.Context, in *WatchServicesRequest, opts ...grpc.CallOption) (MyGrpc_WatchServicesClient, error)
+	// Watch the descriptor of a service chain, which is sent again whenever one of its services changes
+	WatchChain(ctx context.Context, in *WatchChainRequest, opts ...grpc.CallOption) (MyGrpc_WatchChainClient, error)
+	// Search the descriptors of services by labels, name and description page by page, ordered by service name
+	SearchServices(ctx context.Context, in *SearchServicesRequest, opts ...grpc.CallOption) (*SearchServicesResponse, error)
+}
+
+type myGrpcClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewMyGrpcClient(cc *grpc.ClientConn) MyGrpcClient {
+	return &myGrpcClient{cc}
+}
+
+func (c *myGrpcClient) GetChainReqResp(ctx context.Context, in *ServiceChain, opts ...grpc.CallOption) (*ServiceChainDescriptor, error) {
+	out := new(ServiceChainDescriptor)
+	err := grpc.Invoke(ctx, "/mygrpc.MyGrpc/GetChainReqResp", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *myGrpcClient) GetChainsReqResps(ctx context.Context, in *ServiceChains, opts ...grpc.CallOption) (MyGrpc_GetChainsReqRespsClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_MyGrpc_serviceDesc.Streams[0], c.cc, "/mygrpc.MyGrpc/GetChainsReqResps", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &myGrpcGetChainsReqRespsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type MyGrpc_GetChainsReqRespsClient interface {
+	Recv() (*ServiceChainDescriptor, error)
+	grpc.ClientStream
+}
+
+type myGrpcGetChainsReqRespsClient struct {
+	grpc.ClientStream
+}
+
+func (x *myGrpcGetChainsReqRespsClient) Recv() (*ServiceChainDescriptor, error) {
+	m := new(ServiceChainDescriptor)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *myGrpcClient) GetChainsReqsResp(ctx context.Context, opts ...grpc.CallOption) (MyGrpc_GetChainsReqsRespClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_MyGrpc_serviceDesc.Streams[1], c.cc, "/mygrpc.MyGrpc/GetChainsReqsResp", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &myGrpcGetChainsReqsRespClient{stream}
+	return x, nil
+}
+
+type MyGrpc_GetChainsReqsRespClient interface {
+	Send(*ServiceChain) error
+	CloseAndRecv() (*ServiceChainDescriptors, error)
+	grpc.ClientStream
+}
+
+type myGrpcGetChainsReqsRespClient struct {
+	grpc.ClientStream
+}
+
+func (x *myGrpcGetChainsReqsRespClient) Send(m *ServiceChain) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *myGrpcGetChainsReqsRespClient) CloseAndRecv() (*ServiceChainDescriptors, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ServiceChainDescriptors)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *myGrpcClient) GetChainsReqsResps(ctx context.Context, opts ...grpc.CallOption) (MyGrpc_GetChainsReqsRespsClient, error) {
 	stream, err := grpc.NewClientStream(ctx, &_MyGrpc_serviceDesc.Streams[2], c.cc, "/mygrpc.MyGrpc/GetChainsReqsResps", opts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &myGrpcGetChainsReqsRespsClient{stream}
-	return x, nil
+	x := &myGrpcGetChainsReqsRespsClient{stream}
+	return x, nil
+}
+
+type MyGrpc_GetChainsReqsRespsClient interface {
+	Send(*ServiceChain) error
+	Recv() (*ServiceChainDescriptor, error)
+	grpc.ClientStream
+}
+
+type myGrpcGetChainsReqsRespsClient struct {
+	grpc.ClientStream
+}
+
+func (x *myGrpcGetChainsReqsRespsClient) Send(m *ServiceChain) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *myGrpcGetChainsReqsRespsClient) Recv() (*ServiceChainDescriptor, error) {
+	m := new(ServiceChainDescriptor)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *myGrpcClient) WatchServices(ctx context.Context, in *WatchServicesRequest, opts ...grpc.CallOption) (MyGrpc_WatchServicesClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_MyGrpc_serviceDesc.Streams[3], c.cc, "/mygrpc.MyGrpc/WatchServices", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &myGrpcWatchServicesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type MyGrpc_WatchServicesClient interface {
+	Recv() (*ServiceEvent, error)
+	grpc.ClientStream
+}
+
+type myGrpcWatchServicesClient struct {
+	grpc.ClientStream
+}
+
+func (x *myGrpcWatchServicesClient) Recv() (*ServiceEvent, error) {
+	m := new(ServiceEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *myGrpcClient) WatchChain(ctx context.Context, in *WatchChainRequest, opts ...grpc.CallOption) (MyGrpc_WatchChainClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_MyGrpc_serviceDesc.Streams[4], c.cc, "/mygrpc.MyGrpc/WatchChain", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &myGrpcWatchChainClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type MyGrpc_WatchChainClient interface {
+	Recv() (*ChainEvent, error)
+	grpc.ClientStream
+}
+
+type myGrpcWatchChainClient struct {
+	grpc.ClientStream
+}
+
+func (x *myGrpcWatchChainClient) Recv() (*ChainEvent, error) {
+	m := new(ChainEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *myGrpcClient) SearchServices(ctx context.Context, in *SearchServicesRequest, opts ...grpc.CallOption) (*SearchServicesResponse, error) {
+	out := new(SearchServicesResponse)
+	err := grpc.Invoke(ctx, "/mygrpc.MyGrpc/SearchServices", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for MyGrpc service
+
+type MyGrpcServer interface {
+	// Get a service chain descriptor according to a request indicating to a single chain
+	GetChainReqResp(context.Context, *ServiceChain) (*ServiceChainDescriptor, error)
+	// Get service chain descriptors according to a request indicating to multiple chains
+	GetChainsReqResps(*ServiceChains, MyGrpc_GetChainsReqRespsServer) error
+	// Get a descriptor describing multiple service chains according to requests of which indicating to a single chain each
+	GetChainsReqsResp(MyGrpc_GetChainsReqsRespServer) error
+	// Get multiple service chain descriptors according to requests of which indicating to a single chain each
+	GetChainsReqsResps(MyGrpc_GetChainsReqsRespsServer) error
+	// Watch the modifications of services
+	WatchServices(*WatchServicesRequest, MyGrpc_WatchServicesServer) error
+	// Watch the descriptor of a service chain, which is sent again whenever one of its services changes
+	WatchChain(*WatchChainRequest, MyGrpc_WatchChainServer) error
+	// Search the descriptors of services by labels, name and description page by page, ordered by service name
+	SearchServices(context.Context, *SearchServicesRequest) (*SearchServicesResponse, error)
+}
+
+func RegisterMyGrpcServer(s *grpc.Server, srv MyGrpcServer) {
+	s.RegisterService(&_MyGrpc_serviceDesc, srv)
+}
+
+func _MyGrpc_GetChainReqResp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ServiceChain)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MyGrpcServer).GetChainReqResp(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/mygrpc.MyGrpc/GetChainReqResp",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MyGrpcServer).GetChainReqResp(ctx, req.(*ServiceChain))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MyGrpc_GetChainsReqResps_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ServiceChains)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MyGrpcServer).GetChainsReqResps(m, &myGrpcGetChainsReqRespsServer{stream})
+}
+
+type MyGrpc_GetChainsReqRespsServer interface {
+	Send(*ServiceChainDescriptor) error
+	grpc.ServerStream
+}
+
+type myGrpcGetChainsReqRespsServer struct {
+	grpc.ServerStream
+}
+
+func (x *myGrpcGetChainsReqRespsServer) Send(m *ServiceChainDescriptor) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _MyGrpc_GetChainsReqsResp_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(MyGrpcServer).GetChainsReqsResp(&myGrpcGetChainsReqsRespServer{stream})
+}
+
+type MyGrpc_GetChainsReqsRespServer interface {
+	SendAndClose(*ServiceChainDescriptors) error
+	Recv() (*ServiceChain, error)
+	grpc.ServerStream
+}
+
+type myGrpcGetChainsReqsRespServer struct {
+	grpc.ServerStream
+}
+
+func (x *myGrpcGetChainsReqsRespServer) SendAndClose(m *ServiceChainDescriptors) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *myGrpcGetChainsReqsRespServer) Recv() (*ServiceChain, error) {
+	m := new(ServiceChain)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _MyGrpc_GetChainsReqsResps_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(MyGrpcServer).GetChainsReqsResps(&myGrpcGetChainsReqsRespsServer{stream})
+}
+
+type MyGrpc_GetChainsReqsRespsServer interface {
+	Send(*ServiceChainDescriptor) error
+	Recv() (*ServiceChain, error)
+	grpc.ServerStream
+}
+
+type myGrpcGetChainsReqsRespsServer struct {
+	grpc.ServerStream
+}
+
+func (x *myGrpcGetChainsReqsRespsServer) Send(m *ServiceChainDescriptor) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *myGrpcGetChainsReqsRespsServer) Recv() (*ServiceChain, error) {
+	m := new(ServiceChain)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _MyGrpc_WatchServices_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchServicesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MyGrpcServer).WatchServices(m, &myGrpcWatchServicesServer{stream})
+}
+
+type MyGrpc_WatchServicesServer interface {
+	Send(*ServiceEvent) error
+	grpc.ServerStream
+}
+
+type myGrpcWatchServicesServer struct {
+	grpc.ServerStream
+}
+
+func (x *myGrpcWatchServicesServer) Send(m *ServiceEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _MyGrpc_WatchChain_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchChainRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MyGrpcServer).WatchChain(m, &myGrpcWatchChainServer{stream})
+}
+
+type MyGrpc_WatchChainServer interface {
+	Send(*ChainEvent) error
+	grpc.ServerStream
+}
+
+type myGrpcWatchChainServer struct {
+	grpc.ServerStream
+}
+
+func (x *myGrpcWatchChainServer) Send(m *ChainEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _MyGrpc_SearchServices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchServicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MyGrpcServer).SearchServices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/mygrpc.MyGrpc/SearchServices",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MyGrpcServer).SearchServices(ctx, req.(*SearchServicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _MyGrpc_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "mygrpc.MyGrpc",
+	HandlerType: (*MyGrpcServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetChainReqResp",
+			Handler:    _MyGrpc_GetChainReqResp_Handler,
+		},
+		{
+			MethodName: "SearchServices",
+			Handler:    _MyGrpc_SearchServices_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GetChainsReqResps",
+			Handler:       _MyGrpc_GetChainsReqResps_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GetChainsReqsResp",
+			Handler:       _MyGrpc_GetChainsReqsResp_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "GetChainsReqsResps",
+			Handler:       _MyGrpc_GetChainsReqsResps_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "WatchServices",
+			Handler:       _MyGrpc_WatchServices_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchChain",
+			Handler:       _MyGrpc_WatchChain_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "mygrpc.proto",
+}
+
+// Client API for MyGrpcAdmin service
+
+type MyGrpcAdminClient interface {
+	// Register a new service, fails if a service with the same name exists
+	RegisterService(ctx context.Context, in *ServiceDescriptor, opts ...grpc.CallOption) (*ServiceDescriptor, error)
+	// Update the descriptor of an existing service
+	UpdateService(ctx context.Context, in *ServiceDescriptor, opts ...grpc.CallOption) (*ServiceDescriptor, error)
+	// Deregister a service, returning its last descriptor
+	DeregisterService(ctx context.Context, in *ServiceRequest, opts ...grpc.CallOption) (*ServiceDescriptor, error)
+	// Get the descriptor of a service
+	GetService(ctx context.Context, in *ServiceRequest, opts ...grpc.CallOption) (*ServiceDescriptor, error)
+	// List the descriptors of services page by page, ordered by service name
+	ListServices(ctx context.Context, in *ListServicesRequest, opts ...grpc.CallOption) (*ListServicesResponse, error)
+	// Store a new service chain, fails if a chain with the same id exists
+	CreateChain(ctx context.Context, in *StoredChain, opts ...grpc.CallOption) (*StoredChain, error)
+	// Update an existing stored service chain
+	UpdateChain(ctx context.Context, in *StoredChain, opts ...grpc.CallOption) (*StoredChain, error)
+	// Delete a stored service chain, returning its last definition
+	DeleteChain(ctx context.Context, in *ChainRequest, opts ...grpc.CallOption) (*StoredChain, error)
+	// Get a stored service chain
+	GetChain(ctx context.Context, in *ChainRequest, opts ...grpc.CallOption) (*StoredChain, error)
+	// List the stored service chains page by page, ordered by chain id
+	ListChains(ctx context.Context, in *ListChainsRequest, opts ...grpc.CallOption) (*ListChainsResponse, error)
+}
+
+type myGrpcAdminClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewMyGrpcAdminClient(cc *grpc.ClientConn) MyGrpcAdminClient {
+	return &myGrpcAdminClient{cc}
+}
+
+func (c *myGrpcAdminClient) RegisterService(ctx context.Context, in *ServiceDescriptor, opts ...grpc.CallOption) (*ServiceDescriptor, error) {
+	out := new(ServiceDescriptor)
+	err := grpc.Invoke(ctx, "/mygrpc.MyGrpcAdmin/RegisterService", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *myGrpcAdminClient) UpdateService(ctx context.Context, in *ServiceDescriptor, opts ...grpc.CallOption) (*ServiceDescriptor, error) {
+	out := new(ServiceDescriptor)
+	err := grpc.Invoke(ctx, "/mygrpc.MyGrpcAdmin/UpdateService", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *myGrpcAdminClient) DeregisterService(ctx context.Context, in *ServiceRequest, opts ...grpc.CallOption) (*ServiceDescriptor, error) {
+	out := new(ServiceDescriptor)
+	err := grpc.Invoke(ctx, "/mygrpc.MyGrpcAdmin/DeregisterService", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *myGrpcAdminClient) GetService(ctx context.Context, in *ServiceRequest, opts ...grpc.CallOption) (*ServiceDescriptor, error) {
+	out := new(ServiceDescriptor)
+	err := grpc.Invoke(ctx, "/mygrpc.MyGrpcAdmin/GetService", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *myGrpcAdminClient) ListServices(ctx context.Context, in *ListServicesRequest, opts ...grpc.CallOption) (*ListServicesResponse, error) {
+	out := new(ListServicesResponse)
+	err := grpc.Invoke(ctx, "/mygrpc.MyGrpcAdmin/ListServices", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *myGrpcAdminClient) CreateChain(ctx context.Context, in *StoredChain, opts ...grpc.CallOption) (*StoredChain, error) {
+	out := new(StoredChain)
+	err := grpc.Invoke(ctx, "/mygrpc.MyGrpcAdmin/CreateChain", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *myGrpcAdminClient) UpdateChain(ctx context.Context, in *StoredChain, opts ...grpc.CallOption) (*StoredChain, error) {
+	out := new(StoredChain)
+	err := grpc.Invoke(ctx, "/mygrpc.MyGrpcAdmin/UpdateChain", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *myGrpcAdminClient) DeleteChain(ctx context.Context, in *ChainRequest, opts ...grpc.CallOption) (*StoredChain, error) {
+	out := new(StoredChain)
+	err := grpc.Invoke(ctx, "/mygrpc.MyGrpcAdmin/DeleteChain", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-type MyGrpc_GetChainsReqsRespsClient interface {
-	Send(*ServiceChain) error
-	Recv() (*ServiceChainDescriptor, error)
-	grpc.ClientStream
+func (c *myGrpcAdminClient) GetChain(ctx context.Context, in *ChainRequest, opts ...grpc.CallOption) (*StoredChain, error) {
+	out := new(StoredChain)
+	err := grpc.Invoke(ctx, "/mygrpc.MyGrpcAdmin/GetChain", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-type myGrpcGetChainsReqsRespsClient struct {
-	grpc.ClientStream
+func (c *myGrpcAdminClient) ListChains(ctx context.Context, in *ListChainsRequest, opts ...grpc.CallOption) (*ListChainsResponse, error) {
+	out := new(ListChainsResponse)
+	err := grpc.Invoke(ctx, "/mygrpc.MyGrpcAdmin/ListChains", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (x *myGrpcGetChainsReqsRespsClient) Send(m *ServiceChain) error {
-	return x.ClientStream.SendMsg(m)
+// Server API for MyGrpcAdmin service
+
+type MyGrpcAdminServer interface {
+	// Register a new service, fails if a service with the same name exists
+	RegisterService(context.Context, *ServiceDescriptor) (*ServiceDescriptor, error)
+	// Update the descriptor of an existing service
+	UpdateService(context.Context, *ServiceDescriptor) (*ServiceDescriptor, error)
+	// Deregister a service, returning its last descriptor
+	DeregisterService(context.Context, *ServiceRequest) (*ServiceDescriptor, error)
+	// Get the descriptor of a service
+	GetService(context.Context, *ServiceRequest) (*ServiceDescriptor, error)
+	// List the descriptors of services page by page, ordered by service name
+	ListServices(context.Context, *ListServicesRequest) (*ListServicesResponse, error)
+	// Store a new service chain, fails if a chain with the same id exists
+	CreateChain(context.Context, *StoredChain) (*StoredChain, error)
+	// Update an existing stored service chain
+	UpdateChain(context.Context, *StoredChain) (*StoredChain, error)
+	// Delete a stored service chain, returning its last definition
+	DeleteChain(context.Context, *ChainRequest) (*StoredChain, error)
+	// Get a stored service chain
+	GetChain(context.Context, *ChainRequest) (*StoredChain, error)
+	// List the stored service chains page by page, ordered by chain id
+	ListChains(context.Context, *ListChainsRequest) (*ListChainsResponse, error)
 }
 
-func (x *myGrpcGetChainsReqsRespsClient) Recv() (*ServiceChainDescriptor, error) {
-	m := new(ServiceChainDescriptor)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
+func RegisterMyGrpcAdminServer(s *grpc.Server, srv MyGrpcAdminServer) {
+	s.RegisterService(&_MyGrpcAdmin_serviceDesc, srv)
+}
+
+func _MyGrpcAdmin_RegisterService_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ServiceDescriptor)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return m, nil
+	if interceptor == nil {
+		return srv.(MyGrpcAdminServer).RegisterService(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/mygrpc.MyGrpcAdmin/RegisterService",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MyGrpcAdminServer).RegisterService(ctx, req.(*ServiceDescriptor))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-// Server API for MyGrpc service
+func _MyGrpcAdmin_UpdateService_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ServiceDescriptor)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MyGrpcAdminServer).UpdateService(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/mygrpc.MyGrpcAdmin/UpdateService",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MyGrpcAdminServer).UpdateService(ctx, req.(*ServiceDescriptor))
+	}
+	return interceptor(ctx, in, info, handler)
+}
 
-type MyGrpcServer interface {
-	// Get a service chain descriptor according to a request indicating to a single chain
-	GetChainReqResp(context.Context, *ServiceChain) (*ServiceChainDescriptor, error)
-	// Get service chain descriptors according to a request indicating to multiple chains
-	GetChainsReqResps(*ServiceChains, MyGrpc_GetChainsReqRespsServer) error
-	// Get a descriptor describing multiple service chains according to requests of which indicating to a single chain each
-	GetChainsReqsResp(MyGrpc_GetChainsReqsRespServer) error
-	// Get multiple service chain descriptors according to requests of which indicating to a single chain each
-	GetChainsReqsResps(MyGrpc_GetChainsReqsRespsServer) error
+func _MyGrpcAdmin_DeregisterService_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ServiceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MyGrpcAdminServer).DeregisterService(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/mygrpc.MyGrpcAdmin/DeregisterService",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MyGrpcAdminServer).DeregisterService(ctx, req.(*ServiceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func RegisterMyGrpcServer(s *grpc.Server, srv MyGrpcServer) {
-	s.RegisterService(&_MyGrpc_serviceDesc, srv)
+func _MyGrpcAdmin_GetService_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ServiceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MyGrpcAdminServer).GetService(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/mygrpc.MyGrpcAdmin/GetService",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MyGrpcAdminServer).GetService(ctx, req.(*ServiceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _MyGrpc_GetChainReqResp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ServiceChain)
+func _MyGrpcAdmin_ListServices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListServicesRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(MyGrpcServer).GetChainReqResp(ctx, in)
+		return srv.(MyGrpcAdminServer).ListServices(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/mygrpc.MyGrpc/GetChainReqResp",
+		FullMethod: "/mygrpc.MyGrpcAdmin/ListServices",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(MyGrpcServer).GetChainReqResp(ctx, req.(*ServiceChain))
+		return srv.(MyGrpcAdminServer).ListServices(ctx, req.(*ListServicesRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _MyGrpc_GetChainsReqResps_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(ServiceChains)
-	if err := stream.RecvMsg(m); err != nil {
-		return err
+func _MyGrpcAdmin_CreateChain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StoredChain)
+	if err := dec(in); err != nil {
+		return nil, err
 	}
-	return srv.(MyGrpcServer).GetChainsReqResps(m, &myGrpcGetChainsReqRespsServer{stream})
+	if interceptor == nil {
+		return srv.(MyGrpcAdminServer).CreateChain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/mygrpc.MyGrpcAdmin/CreateChain",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MyGrpcAdminServer).CreateChain(ctx, req.(*StoredChain))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-type MyGrpc_GetChainsReqRespsServer interface {
-	Send(*ServiceChainDescriptor) error
-	grpc.ServerStream
+func _MyGrpcAdmin_UpdateChain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StoredChain)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MyGrpcAdminServer).UpdateChain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/mygrpc.MyGrpcAdmin/UpdateChain",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MyGrpcAdminServer).UpdateChain(ctx, req.(*StoredChain))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-type myGrpcGetChainsReqRespsServer struct {
-	grpc.ServerStream
+func _MyGrpcAdmin_DeleteChain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MyGrpcAdminServer).DeleteChain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/mygrpc.MyGrpcAdmin/DeleteChain",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MyGrpcAdminServer).DeleteChain(ctx, req.(*ChainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (x *myGrpcGetChainsReqRespsServer) Send(m *ServiceChainDescriptor) error {
-	return x.ServerStream.SendMsg(m)
+func _MyGrpcAdmin_GetChain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MyGrpcAdminServer).GetChain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/mygrpc.MyGrpcAdmin/GetChain",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MyGrpcAdminServer).GetChain(ctx, req.(*ChainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _MyGrpc_GetChainsReqsResp_Handler(srv interface{}, stream grpc.ServerStream) error {
-	return srv.(MyGrpcServer).GetChainsReqsResp(&myGrpcGetChainsReqsRespServer{stream})
+func _MyGrpcAdmin_ListChains_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListChainsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MyGrpcAdminServer).ListChains(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/mygrpc.MyGrpcAdmin/ListChains",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MyGrpcAdminServer).ListChains(ctx, req.(*ListChainsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-type MyGrpc_GetChainsReqsRespServer interface {
-	SendAndClose(*ServiceChainDescriptors) error
-	Recv() (*ServiceChain, error)
-	grpc.ServerStream
+var _MyGrpcAdmin_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "mygrpc.MyGrpcAdmin",
+	HandlerType: (*MyGrpcAdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RegisterService",
+			Handler:    _MyGrpcAdmin_RegisterService_Handler,
+		},
+		{
+			MethodName: "UpdateService",
+			Handler:    _MyGrpcAdmin_UpdateService_Handler,
+		},
+		{
+			MethodName: "DeregisterService",
+			Handler:    _MyGrpcAdmin_DeregisterService_Handler,
+		},
+		{
+			MethodName: "GetService",
+			Handler:    _MyGrpcAdmin_GetService_Handler,
+		},
+		{
+			MethodName: "ListServices",
+			Handler:    _MyGrpcAdmin_ListServices_Handler,
+		},
+		{
+			MethodName: "CreateChain",
+			Handler:    _MyGrpcAdmin_CreateChain_Handler,
+		},
+		{
+			MethodName: "UpdateChain",
+			Handler:    _MyGrpcAdmin_UpdateChain_Handler,
+		},
+		{
+			MethodName: "DeleteChain",
+			Handler:    _MyGrpcAdmin_DeleteChain_Handler,
+		},
+		{
+			MethodName: "GetChain",
+			Handler:    _MyGrpcAdmin_GetChain_Handler,
+		},
+		{
+			MethodName: "ListChains",
+			Handler:    _MyGrpcAdmin_ListChains_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "mygrpc.proto",
 }
 
-type myGrpcGetChainsReqsRespServer struct {
-	grpc.ServerStream
+// Client API for MyGrpcFault service
+
+type MyGrpcFaultClient interface {
+	// Replace the rules of the faults injected into the rpcs of MyGrpc, returning the rules in effect
+	SetFaults(ctx context.Context, in *FaultConfig, opts ...grpc.CallOption) (*FaultConfig, error)
+	// Get the rules of the faults injected into the rpcs of MyGrpc
+	GetFaults(ctx context.Context, in *GetFaultsRequest, opts ...grpc.CallOption) (*FaultConfig, error)
 }
 
-func (x *myGrpcGetChainsReqsRespServer) SendAndClose(m *ServiceChainDescriptors) error {
-	return x.ServerStream.SendMsg(m)
+type myGrpcFaultClient struct {
+	cc *grpc.ClientConn
 }
 
-func (x *myGrpcGetChainsReqsRespServer) Recv() (*ServiceChain, error) {
-	m := new(ServiceChain)
-	if err := x.ServerStream.RecvMsg(m); err != nil {
+func NewMyGrpcFaultClient(cc *grpc.ClientConn) MyGrpcFaultClient {
+	return &myGrpcFaultClient{cc}
+}
+
+func (c *myGrpcFaultClient) SetFaults(ctx context.Context, in *FaultConfig, opts ...grpc.CallOption) (*FaultConfig, error) {
+	out := new(FaultConfig)
+	err := grpc.Invoke(ctx, "/mygrpc.MyGrpcFault/SetFaults", in, out, c.cc, opts...)
+	if err != nil {
 		return nil, err
 	}
-	return m, nil
+	return out, nil
 }
 
-func _MyGrpc_GetChainsReqsResps_Handler(srv interface{}, stream grpc.ServerStream) error {
-	return srv.(MyGrpcServer).GetChainsReqsResps(&myGrpcGetChainsReqsRespsServer{stream})
+func (c *myGrpcFaultClient) GetFaults(ctx context.Context, in *GetFaultsRequest, opts ...grpc.CallOption) (*FaultConfig, error) {
+	out := new(FaultConfig)
+	err := grpc.Invoke(ctx, "/mygrpc.MyGrpcFault/GetFaults", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-type MyGrpc_GetChainsReqsRespsServer interface {
-	Send(*ServiceChainDescriptor) error
-	Recv() (*ServiceChain, error)
-	grpc.ServerStream
+// Server API for MyGrpcFault service
+
+type MyGrpcFaultServer interface {
+	// Replace the rules of the faults injected into the rpcs of MyGrpc, returning the rules in effect
+	SetFaults(context.Context, *FaultConfig) (*FaultConfig, error)
+	// Get the rules of the faults injected into the rpcs of MyGrpc
+	GetFaults(context.Context, *GetFaultsRequest) (*FaultConfig, error)
 }
 
-type myGrpcGetChainsReqsRespsServer struct {
-	grpc.ServerStream
+func RegisterMyGrpcFaultServer(s *grpc.Server, srv MyGrpcFaultServer) {
+	s.RegisterService(&_MyGrpcFault_serviceDesc, srv)
 }
 
-func (x *myGrpcGetChainsReqsRespsServer) Send(m *ServiceChainDescriptor) error {
-	return x.ServerStream.SendMsg(m)
+func _MyGrpcFault_SetFaults_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FaultConfig)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MyGrpcFaultServer).SetFaults(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/mygrpc.MyGrpcFault/SetFaults",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MyGrpcFaultServer).SetFaults(ctx, req.(*FaultConfig))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (x *myGrpcGetChainsReqsRespsServer) Recv() (*ServiceChain, error) {
-	m := new(ServiceChain)
-	if err := x.ServerStream.RecvMsg(m); err != nil {
+func _MyGrpcFault_GetFaults_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFaultsRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return m, nil
+	if interceptor == nil {
+		return srv.(MyGrpcFaultServer).GetFaults(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/mygrpc.MyGrpcFault/GetFaults",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MyGrpcFaultServer).GetFaults(ctx, req.(*GetFaultsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-var _MyGrpc_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "mygrpc.MyGrpc",
-	HandlerType: (*MyGrpcServer)(nil),
+var _MyGrpcFault_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "mygrpc.MyGrpcFault",
+	HandlerType: (*MyGrpcFaultServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "GetChainReqResp",
-			Handler:    _MyGrpc_GetChainReqResp_Handler,
-		},
-	},
-	Streams: []grpc.StreamDesc{
-		{
-			StreamName:    "GetChainsReqResps",
-			Handler:       _MyGrpc_GetChainsReqResps_Handler,
-			ServerStreams: true,
-		},
-		{
-			StreamName:    "GetChainsReqsResp",
-			Handler:       _MyGrpc_GetChainsReqsResp_Handler,
-			ClientStreams: true,
+			MethodName: "SetFaults",
+			Handler:    _MyGrpcFault_SetFaults_Handler,
 		},
 		{
-			StreamName:    "GetChainsReqsResps",
-			Handler:       _MyGrpc_GetChainsReqsResps_Handler,
-			ServerStreams: true,
-			ClientStreams: true,
+			MethodName: "GetFaults",
+			Handler:    _MyGrpcFault_GetFaults_Handler,
 		},
 	},
+	Streams:  []grpc.StreamDesc{},
 	Metadata: "mygrpc.proto",
 }
 
 func init() { proto.RegisterFile("mygrpc.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 362 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x93, 0x5f, 0x4b, 0x32, 0x41,
-	0x14, 0xc6, 0x1d, 0xc5, 0xf5, 0xf5, 0xe8, 0x8b, 0x78, 0xa8, 0x5c, 0x0b, 0x4a, 0x16, 0x82, 0xbd,
-	0x08, 0x11, 0xbb, 0xe9, 0x46, 0xba, 0x28, 0x10, 0xa1, 0x44, 0xa6, 0xbb, 0x6e, 0x44, 0xc7, 0xa1,
-	0x16, 0xda, 0x3f, 0xed, 0x91, 0x05, 0xbf, 0x42, 0xdf, 0xab, 0xef, 0x15, 0x3b, 0x33, 0xe6, 0x6a,
-	0x8b, 0x82, 0x77, 0x33, 0xf3, 0x9c, 0xf3, 0x3b, 0xcf, 0x79, 0x96, 0x85, 0xba, 0xbf, 0x7a, 0x8b,
-	0x23, 0xd1, 0x8d, 0xe2, 0x70, 0x19, 0xa2, 0xa5, 0x6f, 0xce, 0x00, 0x2a, 0x2f, 0x32, 0x4e, 0x3c,
-	0x21, 0xb1, 0x0d, 0xff, 0x28, 0x11, 0xd3, 0x60, 0xe6, 0x4b, 0x9b, 0x75, 0x98, 0x5b, 0xe5, 0x15,
-	0x4a, 0xc4, 0x78, 0xe6, 0x4b, 0x6c, 0x41, 0x7a, 0x9c, 0x46, 0x21, 0xd9, 0xc5, 0x0e, 0x73, 0xcb,
-	0xdc, 0xa2, 0x44, 0x4c, 0x42, 0x72, 0x7c, 0xa8, 0x9b, 0xf6, 0x87, 0xf7, 0x99, 0x17, 0xa4, 0x0c,
-	0x91, 0x1e, 0xa6, 0xde, 0x42, 0x31, 0xca, 0xbc, 0xa2, 0xee, 0xa3, 0x05, 0x5e, 0x40, 0x55, 0x4b,
-	0x1f, 0x32, 0x30, 0x14, 0x5d, 0xfb, 0x24, 0x03, 0xbc, 0x86, 0xb2, 0x3a, 0xdb, 0xa5, 0x4e, 0xc9,
-	0xad, 0xf5, 0x1b, 0x5d, 0x63, 0xd6, 0xc0, 0xb9, 0x56, 0x9d, 0x01, 0xfc, 0xcf, 0x8e, 0x23, 0xbc,
-	0x01, 0x4b, 0x29, 0x64, 0x33, 0xd5, 0x78, 0xb2, 0xd3, 0xa8, 0xca, 0xb8, 0xa9, 0x71, 0xe6, 0xd0,
-	0x34, 0xef, 0x8f, 0x92, 0x44, 0xec, 0x45, 0xcb, 0x30, 0xde, 0xb7, 0xb6, 0x91, 0x16, 0x92, 0x84,
-	0x72, 0xac, 0xa5, 0xb4, 0x37, 0x9b, 0x48, 0x69, 0x2b, 0x91, 0x2f, 0x06, 0x67, 0xd9, 0xe1, 0xdb,
-	0x93, 0x8e, 0x0a, 0xe7, 0x0e, 0x40, 0x8b, 0xca, 0x88, 0x4e, 0xa8, 0xbd, 0xb3, 0xe8, 0x66, 0x0c,
-	0xd7, 0xa4, 0xf4, 0xc1, 0x79, 0x85, 0x56, 0xbe, 0x17, 0xc2, 0x7b, 0xa8, 0x6d, 0xa0, 0xeb, 0xf8,
-	0x2e, 0xf3, 0xe2, 0xcb, 0xa0, 0xe1, 0x17, 0x4d, 0xfd, 0xef, 0x22, 0x58, 0xcf, 0xab, 0x61, 0x1c,
-	0x09, 0x1c, 0x41, 0x63, 0x28, 0x97, 0x3a, 0x6b, 0xf9, 0xc9, 0x25, 0x45, 0x98, 0xfb, 0x21, 0xce,
-	0x0f, 0xf0, 0x9d, 0x02, 0x8e, 0xa1, 0xb9, 0x46, 0x91, 0x61, 0x11, 0x9e, 0xe6, 0xb5, 0xd1, 0x61,
-	0x5a, 0x8f, 0xed, 0xf2, 0x68, 0x8f, 0xb9, 0xab, 0xfd, 0x38, 0x72, 0x0a, 0x2e, 0xc3, 0x09, 0xe0,
-	0x1f, 0x1e, 0x1d, 0xbb, 0xad, 0xcb, 0x7a, 0x6c, 0x6e, 0xa9, 0x1f, 0xf2, 0xf6, 0x27, 0x00, 0x00,
-	0xff, 0xff, 0x23, 0x9e, 0x47, 0xdc, 0xa0, 0x03, 0x00, 0x00,
+	// 1835 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xcc, 0x58, 0x4f, 0x6f, 0x1b, 0xc7,
+	0x15, 0xd7, 0x92, 0x5a, 0x92, 0xfb, 0x96, 0x92, 0xc8, 0x89, 0xec, 0xd0, 0xb4, 0x13, 0x2b, 0x5b,
+	0xb8, 0x51, 0x9c, 0x42, 0x09, 0x14, 0x34, 0x89, 0x83, 0x1a, 0x01, 0x21, 0xd2, 0xb2, 0x10, 0x89,
+	0x92, 0x87, 0x72, 0x92, 0xe6, 0xc2, 0x8e, 0xb8, 0x23, 0x69, 0x91, 0xe5, 0xee, 0x66, 0x67, 0xc8,
+	0x9a, 0x39, 0xf7, 0x50, 0xa0, 0x68, 0x4f, 0x45, 0x3f, 0x40, 0xd1, 0x6b, 0x0e, 0xbd, 0xf4, 0xda,
+	0xaf, 0xd1, 0x7e, 0x9b, 0x62, 0xfe, 0x2d, 0xb9, 0x24, 0x25, 0xd9, 0x8e, 0x0f, 0xbd, 0xed, 0xfc,
+	0xde, 0x9f, 0x79, 0xff, 0xe6, 0xbd, 0x47, 0x42, 0x75, 0x38, 0xb9, 0x48, 0x93, 0xc1, 0x4e, 0x92,
+	0xc6, 0x3c, 0x46, 0x25, 0x75, 0x6a, 0xde, 0xbf, 0x88, 0xe3, 0x8b, 0x90, 0x7e, 0x24, 0xd1, 0xb3,
+	0xd1, 0xf9, 0x47, 0x3c, 0x18, 0x52, 0xc6, 0xc9, 0x30, 0x51, 0x8c, 0xde, 0x77, 0x50, 0xee, 0xd1,
+	0x74, 0x1c, 0x0c, 0x28, 0xba, 0x03, 0x15, 0x36, 0x1e, 0xf4, 0x23, 0x32, 0xa4, 0x0d, 0x6b, 0xcb,
+	0xda, 0x76, 0x70, 0x99, 0x8d, 0x07, 0x5d, 0x32, 0xa4, 0xe8, 0x6d, 0x10, 0x9f, 0xfd, 0x24, 0x66,
+	0x8d, 0xc2, 0x96, 0xb5, 0x6d, 0xe3, 0x12, 0x1b, 0x0f, 0x4e, 0x62, 0x86, 0xee, 0x82, 0x33, 0xb8,
+	0x24, 0x41, 0xd4, 0x4f, 0xe9, 0x79, 0xa3, 0x28, 0x49, 0x15, 0x09, 0x60, 0x7a, 0xee, 0x0d, 0xa1,
+	0xaa, 0x75, 0xef, 0x09, 0x48, 0x5c, 0xa0, 0x98, 0x03, 0x5f, 0x5e, 0x60, 0xe3, 0xb2, 0x3c, 0x1f,
+	0xf8, 0x53, 0x3d, 0x21, 0x8d, 0xf4, 0x15, 0x8a, 0xf7, 0x90, 0x46, 0xe8, 0x01, 0xd8, 0xf2, 0xbb,
+	0x51, 0xdc, 0x2a, 0x6e, 0xbb, 0xbb, 0x1b, 0x3b, 0xda, 0x55, 0xad, 0x1c, 0x2b, 0xaa, 0xf7, 0x5f,
+	0x0b, 0xdc, 0x1e, 0x8f, 0x53, 0xea, 0xdf, 0x78, 0x1d, 0x82, 0x55, 0xe9, 0x66, 0x41, 0xba, 0x29,
+	0xbf, 0x5f, 0xf2, 0x16, 0xf4, 0x08, 0x60, 0x90, 0x52, 0xc2, 0xa9, 0xdf, 0x27, 0xbc, 0xb1, 0xba,
+	0x65, 0x6d, 0xbb, 0xbb, 0xcd, 0x1d, 0x15, 0xe6, 0x1d, 0x13, 0xe6, 0x9d, 0x53, 0x13, 0x66, 0xec,
+	0x68, 0xee, 0x16, 0x17, 0xa2, 0xa3, 0xc4, 0x37, 0xa2, 0xf6, 0xcd, 0xa2, 0x9a, 0xbb, 0xc5, 0xbd,
+	0x0f, 0xa0, 0xba, 0xa7, 0xc2, 0xfa, 0xc3, 0x88, 0x32, 0x7e, 0x8d, 0x6f, 0xde, 0x31, 0xd4, 0x0f,
+	0x03, 0xc6, 0x25, 0x3b, 0x33, 0xfc, 0x77, 0xc1, 0x49, 0xc8, 0x05, 0xed, 0xb3, 0xe0, 0x47, 0xaa,
+	0x05, 0x2a, 0x02, 0xe8, 0x05, 0x3f, 0x52, 0xf4, 0x0e, 0x80, 0x24, 0xf2, 0xf8, 0x7b, 0x1d, 0x7d,
+	0x07, 0x4b, 0xf6, 0x53, 0x01, 0x78, 0x7f, 0xb4, 0x00, 0xcd, 0x6a, 0x64, 0x49, 0x1c, 0x31, 0x8a,
+	0x3e, 0x84, 0x92, 0xbc, 0x92, 0x35, 0x2c, 0x19, 0xb0, 0xb7, 0xb2, 0x80, 0x4d, 0x73, 0x80, 0x35,
+	0x0b, 0xfa, 0x25, 0x6c, 0x44, 0xf4, 0x05, 0xef, 0x2f, 0xdc, 0xb3, 0x26, 0xe0, 0x13, 0x73, 0x97,
+	0x30, 0x85, 0xc7, 0x9c, 0x84, 0xca, 0x50, 0x55, 0x50, 0x8e, 0x44, 0x84, 0xa5, 0xde, 0x39, 0xac,
+	0xcd, 0x56, 0x14, 0x43, 0xbf, 0x9a, 0x33, 0x62, 0x73, 0x2e, 0x6b, 0x79, 0x2b, 0xde, 0x87, 0x8d,
+	0x84, 0xa4, 0x3c, 0x20, 0x61, 0x3f, 0xa5, 0x6c, 0x14, 0x72, 0x55, 0xce, 0x15, 0xbc, 0xae, 0x61,
+	0xac, 0x50, 0xef, 0xdf, 0x45, 0xa8, 0x6b, 0x0d, 0x6d, 0xca, 0x06, 0x69, 0x90, 0xf0, 0x38, 0xbd,
+	0xee, 0x81, 0x68, 0x92, 0x4f, 0xd9, 0xa0, 0x51, 0xc8, 0x48, 0x42, 0x76, 0xf6, 0xed, 0x14, 0x73,
+	0x6f, 0xa7, 0x01, 0xe5, 0x31, 0x4d, 0x59, 0x10, 0x47, 0xb2, 0x8c, 0x1c, 0x6c, 0x8e, 0xe8, 0x1e,
+	0x38, 0x34, 0xf2, 0x93, 0x38, 0x88, 0x38, 0x6b, 0xd8, 0x5b, 0x45, 0x91, 0x8f, 0x0c, 0x40, 0x8f,
+	0xa1, 0x14, 0x92, 0x33, 0x1a, 0xb2, 0x46, 0x49, 0xfa, 0xfc, 0x60, 0xce, 0xe7, 0xa9, 0xc5, 0x3b,
+	0x87, 0x92, 0xaf, 0x13, 0xf1, 0x74, 0x82, 0xb5, 0x10, 0xda, 0x04, 0x3b, 0xfe, 0x7d, 0x44, 0xd3,
+	0x46, 0x59, 0x5e, 0xaa, 0x0e, 0x73, 0x65, 0x5d, 0x79, 0xfd, 0xb2, 0x76, 0x5e, 0xa1, 0xac, 0x45,
+	0xba, 0x55, 0x19, 0x27, 0x84, 0x5f, 0x36, 0x60, 0xab, 0x28, 0xd2, 0x2d, 0x91, 0x13, 0xc2, 0x2f,
+	0x9b, 0x8f, 0xc0, 0x9d, 0xf1, 0x00, 0xd5, 0xa0, 0xf8, 0x3d, 0x9d, 0xe8, 0xd0, 0x8b, 0x4f, 0xe1,
+	0xcb, 0x98, 0x84, 0x23, 0xf3, 0x90, 0xd5, 0xe1, 0x8b, 0xc2, 0xe7, 0x96, 0xf7, 0x1f, 0x0b, 0x6e,
+	0xcf, 0xd6, 0x40, 0x3e, 0x8d, 0xaf, 0xd5, 0x86, 0x3e, 0x37, 0xc6, 0xca, 0x2c, 0xab, 0x2e, 0x71,
+	0xe7, 0xca, 0xd8, 0x6b, 0x3f, 0x04, 0x20, 0x9e, 0x0a, 0xe3, 0x84, 0x8f, 0x98, 0xee, 0x17, 0xd9,
+	0x53, 0x91, 0xa6, 0xf5, 0x24, 0x09, 0x6b, 0x16, 0x74, 0x1f, 0x56, 0x2f, 0xe3, 0x44, 0xe5, 0xdd,
+	0xdd, 0x75, 0x0d, 0xeb, 0xd3, 0x38, 0xc1, 0x92, 0xe0, 0xfd, 0xc5, 0x82, 0xe2, 0xd3, 0x38, 0x79,
+	0xad, 0x7e, 0x5d, 0x83, 0x62, 0x12, 0xfb, 0xb2, 0x10, 0x1d, 0x2c, 0x3e, 0x91, 0x07, 0x6b, 0x8c,
+	0x93, 0x94, 0xf7, 0xc5, 0x64, 0xe8, 0x47, 0xca, 0xc4, 0x22, 0x76, 0x25, 0x28, 0x92, 0xd6, 0x65,
+	0x22, 0x4d, 0x34, 0x24, 0x09, 0xa3, 0xbe, 0x60, 0xb0, 0x25, 0x83, 0xa3, 0x91, 0x2e, 0xf3, 0xfe,
+	0x64, 0x81, 0x3b, 0xe3, 0x89, 0xe8, 0xae, 0x83, 0xd8, 0x37, 0x7d, 0x46, 0x7e, 0x8b, 0x62, 0x1f,
+	0x52, 0xc6, 0xc8, 0x85, 0xc9, 0x95, 0x39, 0xa2, 0xdb, 0x50, 0x4a, 0x29, 0x61, 0x71, 0xa4, 0xad,
+	0xd2, 0x27, 0xf4, 0x29, 0xc0, 0x38, 0x88, 0x43, 0xc2, 0x83, 0x58, 0x5a, 0x25, 0xa2, 0x71, 0xdb,
+	0x44, 0xe3, 0x49, 0x40, 0x43, 0xff, 0x6b, 0x43, 0xc6, 0x33, 0x9c, 0xde, 0x53, 0x58, 0xcf, 0x53,
+	0x45, 0x95, 0x9c, 0x0b, 0x44, 0x47, 0x49, 0x1d, 0xd0, 0x16, 0xb8, 0xbe, 0xce, 0x96, 0x78, 0x82,
+	0xca, 0xaa, 0x59, 0xc8, 0xfb, 0x0e, 0xde, 0x5e, 0x5e, 0x42, 0x0c, 0x7d, 0x09, 0xee, 0xb4, 0x16,
+	0x4c, 0xf3, 0x79, 0x77, 0x59, 0xf3, 0x99, 0x4a, 0x61, 0xc8, 0x2a, 0x82, 0x79, 0x1f, 0xc2, 0xba,
+	0xe6, 0x9a, 0x69, 0xe9, 0x57, 0xa4, 0xd3, 0x7b, 0x06, 0x6f, 0x89, 0x06, 0xac, 0x05, 0xde, 0x48,
+	0x53, 0xff, 0xab, 0x05, 0x9b, 0x79, 0x9d, 0xba, 0xad, 0xff, 0x1a, 0x2a, 0x4c, 0x63, 0x0d, 0xeb,
+	0xa6, 0x1a, 0xcf, 0x58, 0xdf, 0x54, 0x83, 0xff, 0x87, 0x05, 0xb7, 0x7a, 0x94, 0xa4, 0x83, 0xcb,
+	0x79, 0x67, 0x1f, 0xc0, 0xba, 0x6c, 0x60, 0x7d, 0x46, 0x43, 0x3a, 0xe0, 0x71, 0xaa, 0x83, 0xb4,
+	0x26, 0xd1, 0x9e, 0x06, 0x97, 0x4e, 0xf6, 0x4d, 0xb0, 0x7f, 0x18, 0xd1, 0x74, 0xa2, 0x0b, 0x4c,
+	0x1d, 0xf2, 0xd1, 0x5b, 0xbd, 0x36, 0x7a, 0xf6, 0x7c, 0xf4, 0xfe, 0x26, 0xbb, 0x4b, 0xde, 0xcc,
+	0xff, 0x8b, 0xf8, 0x4d, 0x60, 0xf3, 0x1b, 0xc2, 0x17, 0xa3, 0x77, 0x17, 0x1c, 0x53, 0x5c, 0xca,
+	0x2c, 0x07, 0x57, 0x74, 0x75, 0x31, 0xf4, 0x1e, 0x54, 0xc5, 0x38, 0x1c, 0xe6, 0x2f, 0x76, 0x15,
+	0xa6, 0xae, 0x7d, 0x0f, 0xaa, 0x8c, 0x46, 0x7e, 0x3f, 0x88, 0x02, 0x31, 0x27, 0xe5, 0xc5, 0x15,
+	0xec, 0x0a, 0xec, 0x40, 0x41, 0xde, 0x9f, 0xad, 0x6c, 0xdd, 0xeb, 0x8c, 0x69, 0x24, 0x32, 0xb6,
+	0xca, 0x27, 0x89, 0xaa, 0xcc, 0xf5, 0xdd, 0xba, 0x89, 0x82, 0x24, 0x9e, 0x4e, 0x12, 0x8a, 0x25,
+	0x19, 0x7d, 0x02, 0x65, 0x1d, 0x05, 0x79, 0xf1, 0xb5, 0xf1, 0x32, 0x9c, 0x0b, 0x26, 0x17, 0x17,
+	0x4c, 0xf6, 0xce, 0xa0, 0x2e, 0x43, 0x91, 0xdb, 0x9b, 0x1e, 0x9a, 0x25, 0xcf, 0xda, 0xb2, 0xae,
+	0x5c, 0x17, 0x14, 0xcb, 0x4b, 0x84, 0xc5, 0xfb, 0xa9, 0x00, 0x20, 0x65, 0x5e, 0xc9, 0xe3, 0xd9,
+	0x01, 0x54, 0xc8, 0x0f, 0xa0, 0xc7, 0x73, 0x33, 0xc6, 0x7a, 0x89, 0xb6, 0x32, 0x33, 0x68, 0x36,
+	0xc1, 0xa6, 0x69, 0x1a, 0xa7, 0x7a, 0xa1, 0x50, 0x07, 0xe9, 0x34, 0x19, 0x31, 0xda, 0xb0, 0x97,
+	0x3a, 0x2d, 0xcd, 0xc3, 0x8a, 0x65, 0xc1, 0xe9, 0xd2, 0x62, 0x2d, 0x3c, 0x32, 0xbd, 0x4f, 0x29,
+	0x2d, 0x4b, 0xa5, 0x8d, 0x25, 0xdb, 0x9f, 0x52, 0xac, 0x1c, 0xda, 0x13, 0xbc, 0x9e, 0x0f, 0xb5,
+	0x79, 0xfa, 0xcb, 0x06, 0xed, 0x03, 0x93, 0xb9, 0x42, 0x7e, 0x84, 0xce, 0xe8, 0x33, 0x3f, 0x04,
+	0xfe, 0x65, 0x01, 0x3c, 0x21, 0xa3, 0x90, 0xb7, 0x69, 0x48, 0x26, 0xe8, 0x31, 0x54, 0xfd, 0x80,
+	0xf1, 0x34, 0x38, 0x1b, 0xc9, 0x4e, 0xaf, 0x2e, 0xca, 0xaa, 0x4c, 0x32, 0xb5, 0x67, 0x18, 0x70,
+	0x8e, 0x5d, 0x64, 0xcb, 0x17, 0x2c, 0xfd, 0xa1, 0x1a, 0xa6, 0x45, 0x5c, 0x96, 0xe7, 0x23, 0x86,
+	0x6e, 0x41, 0x69, 0x48, 0x5e, 0x08, 0x42, 0x51, 0x12, 0xec, 0x21, 0x79, 0x71, 0x24, 0x7f, 0x14,
+	0x31, 0xee, 0xfb, 0x74, 0x2c, 0x28, 0x6a, 0x9c, 0x56, 0x14, 0x70, 0xc4, 0x50, 0x13, 0x2a, 0xe7,
+	0x29, 0x19, 0x48, 0x4b, 0x44, 0x3e, 0x2c, 0x9c, 0x9d, 0xbd, 0x7f, 0x16, 0xc0, 0x91, 0x86, 0xe3,
+	0x51, 0xa8, 0x47, 0x26, 0xbf, 0x8c, 0x7d, 0xf3, 0x62, 0xcd, 0x31, 0xff, 0x9a, 0x0b, 0x73, 0xaf,
+	0x39, 0xdb, 0x61, 0x02, 0x9f, 0xc9, 0x2d, 0xc5, 0xec, 0x30, 0x07, 0x3e, 0x43, 0xdb, 0x60, 0x4b,
+	0xe3, 0xf5, 0x22, 0x82, 0xb2, 0x79, 0x9a, 0x85, 0x0b, 0x2b, 0x06, 0x39, 0xf3, 0x45, 0xf5, 0xf4,
+	0x53, 0xc2, 0xa9, 0xb6, 0xd4, 0x91, 0x08, 0x26, 0x9c, 0x4e, 0xc9, 0x72, 0xd2, 0x97, 0x54, 0x1f,
+	0x92, 0xc8, 0x9e, 0x18, 0xf7, 0xbf, 0x80, 0x35, 0x45, 0x36, 0x43, 0x5f, 0x2d, 0x9b, 0x55, 0x09,
+	0x1e, 0x29, 0x0c, 0xdd, 0x07, 0x97, 0x9c, 0xc5, 0x29, 0xef, 0x93, 0x73, 0x4e, 0x53, 0xb9, 0x74,
+	0xda, 0x18, 0x24, 0xd4, 0x12, 0x88, 0xdc, 0x4d, 0x44, 0xd7, 0xc9, 0xe2, 0xef, 0xe8, 0xdd, 0x84,
+	0x46, 0x7e, 0x5b, 0xe5, 0xc0, 0xfb, 0x14, 0x5c, 0x69, 0xfc, 0x5e, 0x1c, 0x9d, 0x07, 0x17, 0xe8,
+	0x7d, 0xb0, 0xd3, 0x51, 0x98, 0xf5, 0xde, 0x7a, 0xce, 0x41, 0x11, 0x56, 0xac, 0xe8, 0x1e, 0x82,
+	0xda, 0x3e, 0xe5, 0x12, 0x36, 0x5d, 0xf2, 0xe1, 0x33, 0x70, 0xb2, 0xb2, 0x43, 0x75, 0x58, 0x7b,
+	0xde, 0xfd, 0xaa, 0x7b, 0xfc, 0x4d, 0xb7, 0xdf, 0xf9, 0xba, 0xd3, 0x3d, 0xad, 0xad, 0x20, 0x07,
+	0xec, 0x56, 0xbb, 0xdd, 0x69, 0xd7, 0x2c, 0xe4, 0x42, 0xf9, 0xf9, 0x49, 0xbb, 0x75, 0xda, 0x69,
+	0xd7, 0x0a, 0xe2, 0xd0, 0xee, 0x1c, 0x76, 0xc4, 0xa1, 0x88, 0xaa, 0x50, 0x39, 0xc1, 0xc7, 0xfb,
+	0xb8, 0xd3, 0xeb, 0xd5, 0x56, 0x1f, 0xfe, 0x0e, 0xea, 0x0b, 0x05, 0x86, 0x36, 0xc0, 0x6d, 0x77,
+	0x0e, 0x5b, 0xbf, 0xed, 0x3f, 0x39, 0xf8, 0xb6, 0xd3, 0xae, 0xad, 0x88, 0xbb, 0x14, 0xf0, 0xbc,
+	0x7b, 0xf0, 0xe4, 0x18, 0x1f, 0xd5, 0x2c, 0x74, 0x0b, 0xea, 0x0a, 0xea, 0x7c, 0x7b, 0x72, 0xdc,
+	0xed, 0x74, 0x4f, 0x0f, 0x5a, 0x87, 0xb5, 0x02, 0xaa, 0x41, 0x55, 0xc1, 0xdd, 0x63, 0x7c, 0xd4,
+	0x3a, 0xac, 0x15, 0x77, 0xff, 0xbe, 0x0a, 0xa5, 0xa3, 0xc9, 0x7e, 0x9a, 0x0c, 0xd0, 0x01, 0x6c,
+	0xec, 0x53, 0x6e, 0x1a, 0x9e, 0x18, 0x49, 0x68, 0x69, 0x87, 0x6b, 0xde, 0xd0, 0x52, 0xbc, 0x15,
+	0xd4, 0x85, 0xba, 0x51, 0xc5, 0xb4, 0x2e, 0x86, 0x6e, 0x2d, 0x13, 0x63, 0x37, 0x6b, 0xfb, 0xd8,
+	0x9a, 0xd7, 0xc7, 0xae, 0x31, 0xee, 0xfe, 0xf5, 0xea, 0x98, 0xb7, 0xb2, 0x6d, 0xa1, 0x13, 0x40,
+	0x0b, 0xfa, 0xd8, 0xeb, 0x7a, 0xbb, 0x6d, 0x7d, 0x6c, 0xa1, 0x7d, 0x58, 0xcb, 0x8d, 0x4e, 0x74,
+	0xcf, 0x88, 0x2d, 0x9b, 0xa8, 0xcd, 0xa5, 0x5d, 0x54, 0xba, 0xfa, 0x25, 0xc0, 0x74, 0xf0, 0xa0,
+	0x3b, 0x39, 0x2d, 0xb3, 0xc3, 0xa8, 0x89, 0x72, 0x3f, 0x03, 0xa6, 0x0a, 0x9e, 0xc1, 0x7a, 0x7e,
+	0xb9, 0x40, 0xef, 0x4c, 0x2f, 0x5b, 0xb2, 0x1b, 0x35, 0xdf, 0xbd, 0x8a, 0xac, 0x76, 0x12, 0x6f,
+	0x65, 0xf7, 0x27, 0x1b, 0x5c, 0x55, 0x24, 0x2d, 0x7f, 0x18, 0x44, 0xa2, 0x52, 0x30, 0xbd, 0x08,
+	0x18, 0xa7, 0x69, 0xf6, 0xf7, 0xcf, 0x95, 0x63, 0xb7, 0x79, 0x35, 0xc9, 0x5b, 0x11, 0x71, 0x7b,
+	0x2e, 0x7f, 0xd0, 0xfd, 0x5c, 0x45, 0x4f, 0xc5, 0x53, 0x49, 0xe7, 0xac, 0xba, 0x3d, 0x27, 0x61,
+	0x5c, 0xbe, 0x56, 0x53, 0x0b, 0x60, 0x9f, 0xf2, 0x9f, 0xa5, 0xe2, 0x2b, 0xa8, 0xce, 0xae, 0xc7,
+	0xe8, 0xae, 0x61, 0x5e, 0xb2, 0x88, 0x37, 0xef, 0x2d, 0x27, 0x9a, 0xe8, 0x8b, 0x89, 0xb9, 0x27,
+	0x7f, 0x2e, 0xab, 0x92, 0x58, 0x36, 0xbb, 0x9a, 0xcb, 0x40, 0x25, 0xaa, 0xa2, 0xfb, 0xea, 0xa2,
+	0x5f, 0x80, 0xdb, 0xa6, 0x21, 0x35, 0xa2, 0x9b, 0xb9, 0x6a, 0x33, 0xa6, 0x5f, 0x21, 0xfb, 0x19,
+	0x54, 0xcc, 0xf3, 0x7a, 0x35, 0xc1, 0x0e, 0xc0, 0xf4, 0xbf, 0xa2, 0x69, 0x29, 0x2c, 0xfc, 0x23,
+	0xd5, 0x6c, 0x2e, 0x23, 0x65, 0xf5, 0xfa, 0x07, 0xcb, 0xd4, 0xab, 0xec, 0xd0, 0xe8, 0x33, 0x70,
+	0x7a, 0xa6, 0x5b, 0x4f, 0x83, 0x30, 0xd3, 0xf8, 0x9b, 0xcb, 0x40, 0x6f, 0x05, 0xfd, 0x06, 0x9c,
+	0xac, 0xcd, 0xa3, 0x6c, 0x49, 0x99, 0xef, 0xfc, 0x57, 0x48, 0x9f, 0x95, 0xe4, 0xdf, 0x17, 0x9f,
+	0xfc, 0x6f, 0x00, 0x7f, 0xad, 0x35, 0x9c, 0x5d, 0x15, 0x00, 0x00,
 }
@@ -5,20 +5,32 @@ package main
 import (
     "encoding/json"
     "flag"
-    "log"
+    "fmt"
     "os"
     "io/ioutil"
+    "strings"
     "time"    
     
     pb "mygrpc/mygrpc"
     impl "mygrpc/mygrpcimpl/client"
+    "mygrpc/util/auth"
+    "mygrpc/util/certs"
+    "mygrpc/util/config"
+    "mygrpc/util/logging"
+    "mygrpc/util/tracing"
     val  "mygrpc/util/validate"
+    
+    "google.golang.org/grpc"
 )
 
 var (
-    // tls              = flag.Bool("tls", false, "Connection uses TLS if true, else plain TCP")
+    useTLS              = flag.Bool("tls", false, "Connection uses TLS if true, else plain TCP")
+    certFile            = flag.String("tls_cert", "", "The TLS certificate file of the client for mutual TLS, reloaded when it changes")
+    keyFile             = flag.String("tls_key", "", "The TLS key file of the client for mutual TLS, reloaded when it changes")
+    caFile              = flag.String("tls_ca", "", "The CA file verifying the server certificate, the system roots if empty")
+    serverName          = flag.String("tls_server_name", "", "The name verified in the server certificate, the host of -server if empty")
     useTestFile         = flag.Bool("test_file", true, "Uses the json file containing service chain info as the data source")
-    chainInfoFile       = flag.String("chain_info_file", "/usr/src/grpc/src/mygrpc/testdata/test_data_client.json", "A json file containing service chain info for testing")
+    chainInfoFile       = flag.String("chain_info_file", "/usr/src/grpc/src/mygrpc/testdata/test_data_client.json", "A json file containing service chain info for testing, either a list of the ids of the chains stored by the server or a list of full chains")
     serverAddr          = flag.String("server", "localhost:8082", "The address of the mygrpc server")
     rpcType             = flag.String("rpc", "simple", "The type of RPC will be called, including 'simple', 'server_stream', 'client_stream', 'bi_stream'")
     callInterval        = flag.Int("inv", 1, "The interval between each call in seconds")
@@ -26,30 +38,123 @@ var (
     callNum             = flag.Int("num", 10, "The number of time calling the RPC per goroutine")
     concurNum           = flag.Int("con", 1, "The number of goroutine concurrently calling the RPC per client")
     clientNum           = flag.Int("cli", 1, "The number of client instance running. Each client instance owns an independent tcp connection")
+    partialResults      = flag.Bool("partial", false, "Asks the server to report failing chains in their results instead of ending the streaming RPCs")
+    token               = flag.String("token", "", "The bearer token sent with every rpc, an api key or a JWT")
+    tokenFile           = flag.String("token_file", "", "A file containing the bearer token sent with every rpc, read once at start")
+    trace               = flag.Bool("trace", false, "Traces the rpcs, propagating the trace context in W3C traceparent and B3 headers and logging the trace ids")
+    traceFile           = flag.String("trace_file", "", "A file the spans are appended to as json lines, implies -trace")
+    logFormat           = flag.String("log_format", "text", "The format of the logs, including 'text' (key=value pairs) and 'json'")
+    logLevel            = flag.String("log_level", "info", "The minimal level of the logs, including 'debug', 'info', 'warn' and 'error'. Stream messages and successful unary rpcs are logged at debug")
+    logPayloads         = flag.String("log_payloads", "off", "Logging of the rpc messages, including 'off', 'full' and 'sample:N' (the messages of one rpc in every N). The svc_desc fields are redacted")
     
-    myGrpcLogger        = log.New(os.Stderr, "mygrpc_client_", log.LstdFlags|log.Lshortfile)
+    myGrpcLogger        = logging.Logger("client")
 )
 
+// decode the service chains of the chain info file. a list of ids makes references
+// to the chains stored by the server, which only carry their chain_id
+func unmarshalChainInfo(data []byte) ([]*pb.ServiceChain, error) {
+    var ids []int32
+    if err := json.Unmarshal(data, &ids); err == nil {
+        scs := make([]*pb.ServiceChain, len(ids))
+        for i, id := range ids {
+            scs[i] = &pb.ServiceChain{ChainId: id}
+        }
+        return scs, nil
+    }
+    var scs []*pb.ServiceChain
+    err := json.Unmarshal(data, &scs)
+    return scs, err
+}
+
+// return the options dialing the server according to the flags
+func dialOptions() ([]grpc.DialOption, error) {
+    opts := []grpc.DialOption{grpc.WithInsecure()}
+    if *useTLS {
+        r, err := certs.NewReloader(certs.Files{CertFile: *certFile, KeyFile: *keyFile, CAFile: *caFile, ServerName: *serverName}, false)
+        if err != nil {
+            return nil, err
+        }
+        opts = []grpc.DialOption{grpc.WithTransportCredentials(r.TransportCredentials())}
+    }
+    if *token != "" && *tokenFile != "" {
+        return nil, fmt.Errorf("Only one of -token and -token_file may be given")
+    }
+    if *tokenFile != "" {
+        data, err := ioutil.ReadFile(*tokenFile)
+        if err != nil {
+            return nil, err
+        }
+        *token = strings.TrimSpace(string(data))
+    }
+    if *token != "" {
+        opts = append(opts, grpc.WithPerRPCCredentials(auth.TokenCredentials(*token)))
+    }
+    if *trace || *traceFile != "" {
+        var exporter tracing.Exporter
+        if *traceFile != "" {
+            fe, err := tracing.NewFileExporter(*traceFile)
+            if err != nil {
+                return nil, err
+            }
+            exporter = fe
+            myGrpcLogger.Info("Export spans", "file", *traceFile)
+        }
+        tracer := tracing.NewTracer("mygrpc-client", exporter, myGrpcLogger)
+        opts = append(opts, grpc.WithChainUnaryInterceptor(tracer.UnaryClientInterceptor()), grpc.WithChainStreamInterceptor(tracer.StreamClientInterceptor()))
+    }
+    // inside the tracing so that the logs carry the trace ids
+    payloads, err := logging.ParsePayloads(*logPayloads)
+    if err != nil {
+        return nil, err
+    }
+    opts = append(opts, grpc.WithChainUnaryInterceptor(logging.UnaryClientInterceptor(myGrpcLogger, payloads)),
+                        grpc.WithChainStreamInterceptor(logging.StreamClientInterceptor(myGrpcLogger, payloads)))
+    return opts, nil
+}
+
 func main() {
     
-    flag.Parse()
+    cfg, err := config.Load(flag.CommandLine, os.Args[1:], "token")
+    if err != nil {
+        logging.Fatal(myGrpcLogger, "Failed to load the configuration", "err", err)
+    }
+    if cfg.PrintRequested() {
+        cfg.Print(os.Stdout)
+        return
+    }
+    if err := logging.Setup(os.Stderr, *logFormat, *logLevel); err != nil {
+        logging.Fatal(myGrpcLogger, "Failed to set up the logging", "err", err)
+    }
+    if cfg.File != "" {
+        myGrpcLogger.Info("Use the configuration file", "file", cfg.File)
+    }
     if !val.ValRpcType(*rpcType) {
-        myGrpcLogger.Fatalf("Invalid rpc type: %s", *rpcType)
+        logging.Fatal(myGrpcLogger, "Invalid rpc type", "rpc", *rpcType)
     }
     if *useTestFile {
     
-        myGrpcLogger.Printf("Use service chain info in the json file at %s", *chainInfoFile)
+        myGrpcLogger.Info("Use service chain info in the json file", "file", *chainInfoFile)
         file_data, err := ioutil.ReadFile(*chainInfoFile)
         if err != nil {
-            myGrpcLogger.Fatalf("Failed to load service chain info from %s: %v", *chainInfoFile, err)
+            logging.Fatal(myGrpcLogger, "Failed to load service chain info", "file", *chainInfoFile, "err", err)
+        }
+        chain_info, err := unmarshalChainInfo(file_data)
+        if err != nil {
+            logging.Fatal(myGrpcLogger, "Failed to unmarshal the service chain info", "err", err)
         }
-        var chain_info []*pb.ServiceChain
-        if err := json.Unmarshal(file_data, &chain_info); err != nil {
-            myGrpcLogger.Fatalf("Failed to unmarshal the service chain info: %v", err)
+        if err := val.ValServiceChains(&pb.ServiceChains{Chains: chain_info}); err != nil {
+            if !*partialResults {
+                logging.Fatal(myGrpcLogger, "Invalid service chain info", "file", *chainInfoFile, "err", err)
+            }
+            myGrpcLogger.Warn("Invalid service chain info, left to the server in partial results mode", "file", *chainInfoFile, "err", err)
         }
-        myGrpcLogger.Printf("Successfully load service chain info from the json file at %s", *chainInfoFile)
+        myGrpcLogger.Info("Successfully load service chain info from the json file", "file", *chainInfoFile, "chains", len(chain_info))
         
         
+        opts, err := dialOptions()
+        if err != nil {
+            logging.Fatal(myGrpcLogger, "Failed to set up the connections", "err", err)
+        }
         clientSet := impl.NewMyGrpcClientSet(*useTestFile,
                                              chain_info,
                                              *serverAddr,
@@ -58,17 +163,19 @@ func main() {
                                              time.Duration(*callTimeout * int(time.Second)),
                                              *callNum,
                                              *concurNum,
-                                             *clientNum)
+                                             *clientNum,
+                                             *partialResults,
+                                             opts...)
 
-        myGrpcLogger.Printf("Running MyGrpc grpc client")
+        myGrpcLogger.Info("Running MyGrpc grpc client", "server", *serverAddr, "rpc", *rpcType)
         if err := clientSet.Run(); err != nil {
-            myGrpcLogger.Fatalf("Failed running MyGrpc grpc client: %v", err)
+            logging.Fatal(myGrpcLogger, "Failed running MyGrpc grpc client", "err", err)
         }
         
         return
     
     }
     
-    myGrpcLogger.Fatal("Current implementation only support loading service chain info from json file!")
+    logging.Fatal(myGrpcLogger, "Current implementation only support loading service chain info from json file!")
     
 }
\ No newline at end of file
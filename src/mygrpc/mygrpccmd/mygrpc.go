@@ -0,0 +1,317 @@
+// Entry of the mygrpc command, poking at live mygrpc servers
+//
+// Usage:
+//   mygrpc list [-server addr] [-tls ...] [service]
+//   mygrpc call [-server addr] [-tls ...] [-d json|@file|@-] [-H key:value]... service/method
+//   mygrpc certs [-out dir] [-hosts host,...] [-days n]
+//   mygrpc registry export|import -dir dir [-file file] [-chains_file file]
+
+package main
+
+import (
+    "bytes"
+    "flag"
+    "fmt"
+    "io"
+    "log"
+    "os"
+    "strings"
+    "time"
+
+    "mygrpc/mygrpcimpl/dynamic"
+    reg "mygrpc/mygrpcimpl/registry"
+    "mygrpc/util/certs"
+
+    "golang.org/x/net/context"
+    _ "google.golang.org/genproto/googleapis/rpc/errdetails"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/metadata"
+    "google.golang.org/grpc/status"
+    "google.golang.org/protobuf/encoding/protojson"
+    "google.golang.org/protobuf/proto"
+)
+
+var myGrpcLogger = log.New(os.Stderr, "mygrpc_", log.LstdFlags|log.Lshortfile)
+
+// a command of mygrpc, run with the arguments following its name
+type command struct {
+    usage   string
+    run     func(args []string) error
+}
+
+var commands = map[string]command{
+    "list":     {"list [-server addr] [-tls ...] [service]: list the services of the server, or the methods of a service", runList},
+    "call":     {"call [-server addr] [-tls ...] [-d json|@file|@-] [-H key:value]... service/method: call a method with json requests, one per message of a client stream", runCall},
+    "certs":    {"certs [-out dir] [-hosts host,...] [-days n]: generate a throwaway CA with a server and a client certificate for tests", runCerts},
+    "registry": {"registry export|import -dir dir [-file file] [-chains_file file]: export the persistent registry of a stopped server as json, or replace its services and stored chains by the ones of json files. The file defaults to stdout or stdin, the stored chains are kept on import without a chains file", runRegistry},
+}
+
+// repeatable flag of metadata entries
+type headers []string
+
+func (h *headers) String() string {
+    return strings.Join(*h, ",")
+}
+
+func (h *headers) Set(v string) error {
+    if !strings.Contains(v, ":") {
+        return fmt.Errorf("Malformed header %q, expecting key:value", v)
+    }
+    *h = append(*h, v)
+    return nil
+}
+
+// flags of the connection to the server, shared by the commands calling it
+type connFlags struct {
+    server       *string
+    useTLS       *bool
+    certFile     *string
+    keyFile      *string
+    caFile       *string
+    serverName   *string
+}
+
+func newConnFlags(fs *flag.FlagSet) *connFlags {
+    return &connFlags{
+               server:      fs.String("server", "localhost:8082", "The address of the server"),
+               useTLS:      fs.Bool("tls", false, "Connection uses TLS if true, else plain TCP"),
+               certFile:    fs.String("tls_cert", "", "The TLS certificate file of the client for mutual TLS"),
+               keyFile:     fs.String("tls_key", "", "The TLS key file of the client for mutual TLS"),
+               caFile:      fs.String("tls_ca", "", "The CA file verifying the server certificate, the system roots if empty"),
+               serverName:  fs.String("tls_server_name", "", "The name verified in the server certificate, the host of -server if empty"),
+           }
+}
+
+func (f *connFlags) dial() (*grpc.ClientConn, error) {
+    if !*f.useTLS {
+        return grpc.Dial(*f.server, grpc.WithInsecure())
+    }
+    r, err := certs.NewReloader(certs.Files{CertFile: *f.certFile, KeyFile: *f.keyFile, CAFile: *f.caFile, ServerName: *f.serverName}, false)
+    if err != nil {
+        return nil, err
+    }
+    return grpc.Dial(*f.server, grpc.WithTransportCredentials(r.TransportCredentials()))
+}
+
+func runList(args []string) error {
+    fs := flag.NewFlagSet("list", flag.ExitOnError)
+    cf := newConnFlags(fs)
+    timeout := fs.Duration("timeout", 10 * time.Second, "The maximal time waiting for the server")
+    fs.Parse(args)
+
+    conn, err := cf.dial()
+    if err != nil {
+        return err
+    }
+    defer conn.Close()
+    ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+    defer cancel()
+    client := dynamic.NewClient(conn)
+
+    if fs.NArg() == 0 {
+        names, err := client.ListServices(ctx)
+        if err != nil {
+            return err
+        }
+        for _, name := range names {
+            fmt.Println(name)
+        }
+        return nil
+    }
+
+    sd, err := client.Service(ctx, fs.Arg(0))
+    if err != nil {
+        return err
+    }
+    for i := 0; i < sd.Methods().Len(); i++ {
+        md := sd.Methods().Get(i)
+        in, out := string(md.Input().FullName()), string(md.Output().FullName())
+        if md.IsStreamingClient() {
+            in = "stream " + in
+        }
+        if md.IsStreamingServer() {
+            out = "stream " + out
+        }
+        fmt.Printf("%s/%s(%s) returns (%s)\n", sd.FullName(), md.Name(), in, out)
+    }
+    return nil
+}
+
+// return the requests given to -d, read from stdin for '@-' or from a file for '@path'
+func requestInput(d string) (io.Reader, error) {
+    switch {
+        case d == "@-":
+            return os.Stdin, nil
+        case strings.HasPrefix(d, "@"):
+            return os.Open(d[1:])
+        default:
+            return bytes.NewBufferString(d), nil
+    }
+}
+
+func runCall(args []string) error {
+    fs := flag.NewFlagSet("call", flag.ExitOnError)
+    cf := newConnFlags(fs)
+    data := fs.String("d", "", "The json requests, '@file' to read them from a file or '@-' from stdin. An empty input is an empty request")
+    timeout := fs.Duration("timeout", 0, "The maximal time the call may take, 0 for no limit")
+    var hs headers
+    fs.Var(&hs, "H", "A metadata entry sent with the call as key:value, e.g. mygrpc-partial-results:true. Repeatable")
+    fs.Parse(args)
+    if fs.NArg() != 1 {
+        return fmt.Errorf("Expecting a single method, e.g. mygrpc.MyGrpc/GetChainReqResp")
+    }
+
+    in, err := requestInput(*data)
+    if err != nil {
+        return err
+    }
+    conn, err := cf.dial()
+    if err != nil {
+        return err
+    }
+    defer conn.Close()
+    ctx, cancel := context.Background(), context.CancelFunc(func() {})
+    if *timeout > 0 {
+        ctx, cancel = context.WithTimeout(ctx, *timeout)
+    }
+    defer cancel()
+    for _, h := range hs {
+        kv := strings.SplitN(h, ":", 2)
+        ctx = metadata.AppendToOutgoingContext(ctx, strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1]))
+    }
+
+    client := dynamic.NewClient(conn)
+    md, err := client.Method(ctx, fs.Arg(0))
+    if err != nil {
+        return err
+    }
+    marshaler := protojson.MarshalOptions{Multiline: true}
+    err = client.Call(ctx, md, in, func(resp proto.Message) error {
+              b, err := marshaler.Marshal(resp)
+              if err != nil {
+                  return err
+              }
+              fmt.Println(string(b))
+              return nil
+          })
+    if st, ok := status.FromError(err); ok && err != nil {
+        b, _ := marshaler.Marshal(st.Proto())
+        return fmt.Errorf("Call failed with %s:\n%s", st.Code(), string(b))
+    }
+    return err
+}
+
+func runCerts(args []string) error {
+    fs := flag.NewFlagSet("certs", flag.ExitOnError)
+    out := fs.String("out", "certs", "The directory the certificates are written to")
+    hosts := fs.String("hosts", "localhost,127.0.0.1", "The dns names and ip addresses of the server certificate, comma separated")
+    days := fs.Int("days", 30, "The validity of the certificates in days")
+    fs.Parse(args)
+
+    var hs []string
+    for _, h := range strings.Split(*hosts, ",") {
+        if h = strings.TrimSpace(h); h != "" {
+            hs = append(hs, h)
+        }
+    }
+    if len(hs) == 0 {
+        return fmt.Errorf("The server certificate needs at least one host")
+    }
+    if err := certs.GenerateAll(*out, hs, time.Duration(*days) * 24 * time.Hour); err != nil {
+        return err
+    }
+    myGrpcLogger.Printf("Generated ca.pem, server.pem, server-key.pem, client.pem and client-key.pem in %s for %v", *out, hs)
+    return nil
+}
+
+func runRegistry(args []string) error {
+    if len(args) == 0 || (args[0] != "export" && args[0] != "import") {
+        return fmt.Errorf("Expected export or import")
+    }
+    fs := flag.NewFlagSet("registry", flag.ExitOnError)
+    dir := fs.String("dir", "", "The directory of the persistent registry")
+    file := fs.String("file", "", "The json file of the services exported to or imported from, stdout or stdin if empty")
+    chainsFile := fs.String("chains_file", "", "The json file of the stored chains exported to or imported from, required to export stored chains, the chains are kept on import if empty")
+    fs.Parse(args[1:])
+    if *dir == "" {
+        return fmt.Errorf("The directory of the registry is required")
+    }
+
+    // locked by a running server, which is not to be modified behind its back
+    r, err := reg.OpenFileRegistry(*dir, 0)
+    if err != nil {
+        return err
+    }
+    defer r.Close()
+    if args[0] == "export" {
+        w := io.Writer(os.Stdout)
+        if *file != "" {
+            f, err := os.Create(*file)
+            if err != nil {
+                return err
+            }
+            defer f.Close()
+            w = f
+        }
+        var cw io.Writer
+        if *chainsFile != "" {
+            f, err := os.Create(*chainsFile)
+            if err != nil {
+                return err
+            }
+            defer f.Close()
+            cw = f
+        }
+        return r.Export(w, cw)
+    }
+    rd := io.Reader(os.Stdin)
+    if *file != "" {
+        f, err := os.Open(*file)
+        if err != nil {
+            return err
+        }
+        defer f.Close()
+        rd = f
+    }
+    var crd io.Reader
+    if *chainsFile != "" {
+        f, err := os.Open(*chainsFile)
+        if err != nil {
+            return err
+        }
+        defer f.Close()
+        crd = f
+    }
+    if err := r.Import(rd, crd); err != nil {
+        return err
+    }
+    if err := r.Compact(); err != nil {
+        return err
+    }
+    sds, _ := r.ListServices()
+    cs, _ := r.ListChains()
+    myGrpcLogger.Printf("Imported %d services and %d chains into %s", len(sds), len(cs), *dir)
+    return nil
+}
+
+func usage() {
+    fmt.Fprintf(os.Stderr, "Usage: mygrpc <command> [arguments]\n\nCommands:\n")
+    for _, name := range []string{"list", "call", "certs", "registry"} {
+        fmt.Fprintf(os.Stderr, "  %s\n", commands[name].usage)
+    }
+}
+
+func main() {
+    if len(os.Args) < 2 {
+        usage()
+        os.Exit(2)
+    }
+    cmd, prs := commands[os.Args[1]]
+    if !prs {
+        usage()
+        os.Exit(2)
+    }
+    if err := cmd.run(os.Args[2:]); err != nil {
+        myGrpcLogger.Fatalf("Failed to run %s: %v", os.Args[1], err)
+    }
+}
\ No newline at end of file
@@ -3,20 +3,25 @@
 package client
 
 import (
-    "encoding/json"
     "fmt"
-    "log"
-    "os"
     "io"
+    "log/slog"
+    "sort"
+    "strings"
     "time"
     
     pb "mygrpc/mygrpc"
+    "mygrpc/util/logging"
     
     "golang.org/x/net/context"
+    "google.golang.org/genproto/googleapis/rpc/errdetails"
     "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/metadata"
+    "google.golang.org/grpc/status"
 )
 
-var myGrpcLogger = log.New(os.Stderr, "mygrpc_client_", log.LstdFlags|log.Lshortfile)
+var myGrpcLogger = logging.Logger("client")
 
 type myGrpcClientSet struct {
     useTestFile     bool   // whether use the testing data from a json file
@@ -28,9 +33,11 @@ type myGrpcClientSet struct {
     callNum         int   // number of time calling the RPC per goroutine
     concurNum       int   // number of goroutine concurrently calling the RPC per client
     clientNum       int   // number of client instance running. Each client instance owns an independent tcp connection
+    partialResults  bool   // whether failing chains are reported in their results instead of ending the streaming RPCs
+    dialOpts        []grpc.DialOption   // options dialing the server, including its transport credentials
 }
 
-func NewMyGrpcClientSet(utf bool, tci []*pb.ServiceChain, sa, rt string, ci, ct time.Duration, cn, ccn, clin int) *myGrpcClientSet {
+func NewMyGrpcClientSet(utf bool, tci []*pb.ServiceChain, sa, rt string, ci, ct time.Duration, cn, ccn, clin int, pr bool, opts ...grpc.DialOption) *myGrpcClientSet {
     return &myGrpcClientSet{
                useTestFile: utf,
                testChainInfo: tci,
@@ -41,9 +48,20 @@ func NewMyGrpcClientSet(utf bool, tci []*pb.ServiceChain, sa, rt string, ci, ct
                callNum: cn,
                concurNum: ccn,
                clientNum: clin,
+               partialResults: pr,
+               dialOpts: opts,
            }
 }
 
+// return the context every calling derives from, carrying the opt-in of the partial
+// results mode if asked
+func (c *myGrpcClientSet) baseContext() context.Context {
+    if c.partialResults {
+        return metadata.AppendToOutgoingContext(context.Background(), pb.PartialResultsKey, "true")
+    }
+    return context.Background()
+}
+
 type routineChannel struct {
     clientId         int  // id of the related client istance
     routineId        int  // id of the related goroutine
@@ -59,9 +77,9 @@ func (r *routineChannel) Wait(ci, ct time.Duration, cn int) error {
     
     select {
         case <-r.endChannel:
-            myGrpcLogger.Printf("Processing of goroutine %d in client %d completes", r.routineId, r.clientId)
+            myGrpcLogger.Info("Processing of goroutine completes", "goroutine", r.routineId, "client", r.clientId)
         case <-r.timeoutChannel:
-            myGrpcLogger.Printf("Processing of goroutine %d in client %d timeouts", r.routineId, r.clientId)
+            myGrpcLogger.Warn("Processing of goroutine timeouts", "goroutine", r.routineId, "client", r.clientId)
     }
     
     return nil
@@ -82,12 +100,79 @@ func (e *ChainError) Error() string {
     return fmt.Sprintf("Error for service chain %d: %s", e.ChainId, e.Err.Error())
 }
 
+// describe an error returned by an rpc with its status code and the details
+// attached by the server, so that failures can be classified from the logs
+func describeError(err error) string {
+    st, ok := status.FromError(err)
+    if !ok {
+        return err.Error()
+    }
+    desc := fmt.Sprintf("code = %s, desc = %s", st.Code(), st.Message())
+    for _, d := range st.Details() {
+        switch detail := d.(type) {
+            case *errdetails.ErrorInfo:
+                desc += fmt.Sprintf(", reason = %s, metadata = %v", detail.GetReason(), detail.GetMetadata())
+            case *errdetails.BadRequest:
+                for _, fv := range detail.GetFieldViolations() {
+                    desc += fmt.Sprintf(", violation = %s: %s", fv.GetField(), fv.GetDescription())
+                }
+        }
+    }
+    return desc
+}
+
+// log the services of a resolved service chain at debug, or its failure reported in
+// its result in the partial results mode. the whole results are logged by the
+// logging interceptors
+func logResult(scd *pb.ServiceChainDescriptor, rid, cid, k int) {
+    st := scd.GetStatus()
+    if st == nil || st.GetCode() == 0 {
+        if myGrpcLogger.Enabled(context.Background(), slog.LevelDebug) {
+            svcs := make([]string, len(scd.GetChainDesc()))
+            for i, sd := range scd.GetChainDesc() {
+                svcs[i] = describeService(sd)
+            }
+            myGrpcLogger.Debug("Service chain resolved", "goroutine", rid, "client", cid, "call", k, "chain_id", scd.GetChainId(), "services", svcs)
+        }
+        return
+    }
+    violations := make([]string, len(st.GetViolations()))
+    for i, fv := range st.GetViolations() {
+        violations[i] = fmt.Sprintf("%s: %s", fv.GetField(), fv.GetDescription())
+    }
+    myGrpcLogger.Warn("Service chain failed", "goroutine", rid, "client", cid, "call", k, "chain_id", scd.GetChainId(),
+                      "code", codes.Code(st.GetCode()).String(), "reason", st.GetReason(), "msg", st.GetMessage(), "violations", violations)
+}
+
+// return a one-line summary of a service, e.g. svcA@1.4.2 [svc-a:8082] {tier=backend} owner=team-edge
+func describeService(sd *pb.ServiceDescriptor) string {
+    desc := sd.GetSvcName()
+    if sd.GetVersion() != "" {
+        desc += "@" + sd.GetVersion()
+    }
+    if len(sd.GetEndpoints()) > 0 {
+        desc += " [" + strings.Join(sd.GetEndpoints(), ",") + "]"
+    }
+    if len(sd.GetLabels()) > 0 {
+        labels := make([]string, 0, len(sd.GetLabels()))
+        for k, v := range sd.GetLabels() {
+            labels = append(labels, k + "=" + v)
+        }
+        sort.Strings(labels)
+        desc += " {" + strings.Join(labels, ",") + "}"
+    }
+    if sd.GetOwner() != "" {
+        desc += " owner=" + sd.GetOwner()
+    }
+    return desc
+}
+
 // running the all intances of client
 func (c *myGrpcClientSet) Run() error {
     for i := 0; i < c.clientNum; i++ {
-        conn, err := grpc.Dial(c.serverAddr, grpc.WithInsecure())
+        conn, err := grpc.Dial(c.serverAddr, c.dialOpts...)
         if err != nil {
-            myGrpcLogger.Fatalf("fail to dial: %v", err)
+            logging.Fatal(myGrpcLogger, "Failed to dial", "server", c.serverAddr, "err", err)
         }
         defer conn.Close()
         client := pb.NewMyGrpcClient(conn)
@@ -123,21 +208,20 @@ func (c *myGrpcClientSet) Run() error {
 
 func (c *myGrpcClientSet) CallSimpleRPC(client pb.MyGrpcClient, ech chan struct{}, rid, cid int) error {    
     if c.useTestFile {
-        myGrpcLogger.Printf("Calling simple rpc by goroutine %d in client %d", rid, cid)
+        myGrpcLogger.Info("Calling simple rpc", "goroutine", rid, "client", cid)
         cfs := make([]context.CancelFunc, c.callNum)  // cancel function list for the context in each calling
         ctxs := make([]context.Context, c.callNum)  // context list for each calling
         for k := 0; k < c.callNum; k++ {
-            ctxs[k], cfs[k] = context.WithTimeout(context.Background(), c.callTimeout + time.Duration(k * int(c.callInterval)))
+            ctxs[k], cfs[k] = context.WithTimeout(c.baseContext(), c.callTimeout + time.Duration(k * int(c.callInterval)))
         }            
         defer release(ech, cfs)
         
         for k := 0; k < c.callNum; k++ {
             scd, err := client.GetChainReqResp(ctxs[k], c.testChainInfo[k%len(c.testChainInfo)])
             if err != nil {
-                myGrpcLogger.Fatalf("Failed to call simple rpc by goroutine %d in client %d for call number %d: %v", rid, cid, k, err)
+                logging.Fatal(myGrpcLogger, "Failed to call simple rpc", "goroutine", rid, "client", cid, "call", k, "err", describeError(err))
             }
-            jsonStr, _ := json.Marshal(scd)
-            myGrpcLogger.Printf("Get a response from simple rpc by goroutine %d in client %d for call number %d: %s", rid, cid, k, string(jsonStr))
+            logResult(scd, rid, cid, k)
             
             time.Sleep(c.callInterval)
         }
@@ -145,26 +229,27 @@ func (c *myGrpcClientSet) CallSimpleRPC(client pb.MyGrpcClient, ech chan struct{
         return nil        
     }
     
-    myGrpcLogger.Printf("Current implementations only support using test data, requests are pass")
+    myGrpcLogger.Warn("Current implementations only support using test data, requests are pass")
     close(ech)
     return nil
 }
 
 func (c *myGrpcClientSet) CallServerStreamRPC(client pb.MyGrpcClient, ech chan struct{}, rid, cid int) error {    
     if c.useTestFile {
-        myGrpcLogger.Printf("Calling server-streaming rpc by goroutine %d in client %d", rid, cid)
+        myGrpcLogger.Info("Calling server-streaming rpc", "goroutine", rid, "client", cid)
         scl := make([]*pb.ServiceChain, len(c.testChainInfo))
         for l, sc := range c.testChainInfo {
             scl[l] = sc
         }
         scs := &pb.ServiceChains {
                    Chains: scl,
+                   PartialResults: c.partialResults,
                }
         
         cfs := make([]context.CancelFunc, c.callNum)  // cancel function list for the context in each calling
         ctxs := make([]context.Context, c.callNum)  // context list for each calling
         for k := 0; k < c.callNum; k++ {
-            ctxs[k], cfs[k] = context.WithTimeout(context.Background(), c.callTimeout + time.Duration(k * int(c.callInterval)))
+            ctxs[k], cfs[k] = context.WithTimeout(c.baseContext(), c.callTimeout + time.Duration(k * int(c.callInterval)))
         }            
         defer release(ech, cfs)
         
@@ -174,7 +259,7 @@ func (c *myGrpcClientSet) CallServerStreamRPC(client pb.MyGrpcClient, ech chan s
             
             stream, err = client.GetChainsReqResps(ctxs[k], scs)
             if err != nil {
-                myGrpcLogger.Fatalf("Failed to call server-streaming rpc by goroutine %d in client %d for call number %d: %v", rid, cid, k, err)
+                logging.Fatal(myGrpcLogger, "Failed to call server-streaming rpc", "goroutine", rid, "client", cid, "call", k, "err", describeError(err))
             }
             for {
                 scd, er := stream.Recv()
@@ -182,10 +267,9 @@ func (c *myGrpcClientSet) CallServerStreamRPC(client pb.MyGrpcClient, ech chan s
                     break
                 }
                 if er != nil {
-                    myGrpcLogger.Fatalf("Failed to receive from server-streaming rpc by goroutine %d in client %d for call number %d: %v", rid, cid, k, er)
+                    logging.Fatal(myGrpcLogger, "Failed to receive from server-streaming rpc", "goroutine", rid, "client", cid, "call", k, "err", describeError(er))
                 }
-                jsonStr, _ := json.Marshal(scd)
-                myGrpcLogger.Printf("Get a response from server-streaming rpc by goroutine %d in client %d for call number %d: %s", rid, cid, k, string(jsonStr))
+                logResult(scd, rid, cid, k)
             }
 
             time.Sleep(c.callInterval)
@@ -194,18 +278,18 @@ func (c *myGrpcClientSet) CallServerStreamRPC(client pb.MyGrpcClient, ech chan s
         return nil        
     }
     
-    myGrpcLogger.Printf("Current implementations only support using test data, requests are pass")
+    myGrpcLogger.Warn("Current implementations only support using test data, requests are pass")
     close(ech)
     return nil
 }
 
 func (c *myGrpcClientSet) CallClientStreamRPC(client pb.MyGrpcClient, ech chan struct{}, rid, cid int) error {    
     if c.useTestFile {
-        myGrpcLogger.Printf("Calling client-streaming rpc by goroutine %d in client %d", rid, cid)
+        myGrpcLogger.Info("Calling client-streaming rpc", "goroutine", rid, "client", cid)
         cfs := make([]context.CancelFunc, c.callNum)  // cancel function list for the context in each calling
         ctxs := make([]context.Context, c.callNum)  // context list for each calling
         for k := 0; k < c.callNum; k++ {
-            ctxs[k], cfs[k] = context.WithTimeout(context.Background(), c.callTimeout + time.Duration(k * int(c.callInterval)))
+            ctxs[k], cfs[k] = context.WithTimeout(c.baseContext(), c.callTimeout + time.Duration(k * int(c.callInterval)))
         }            
         defer release(ech, cfs)
         
@@ -215,23 +299,22 @@ func (c *myGrpcClientSet) CallClientStreamRPC(client pb.MyGrpcClient, ech chan s
             
             stream, err = client.GetChainsReqsResp(ctxs[k])
             if err != nil {
-                myGrpcLogger.Fatalf("Failed to call client-streaming rpc by goroutine %d in client %d for call number %d: %v", rid, cid, k, err)
+                logging.Fatal(myGrpcLogger, "Failed to call client-streaming rpc", "goroutine", rid, "client", cid, "call", k, "err", describeError(err))
             }
             for _, sc := range c.testChainInfo {
                 er := stream.Send(sc)
                 if er != nil {
-                    myGrpcLogger.Fatalf("Failed to send to client-streaming rpc by goroutine %d in client %d for call number %d: %v", rid, cid, k, er)
+                    logging.Fatal(myGrpcLogger, "Failed to send to client-streaming rpc", "goroutine", rid, "client", cid, "call", k, "err", describeError(er))
                 }
-                jsonStr, _ := json.Marshal(sc)
-                myGrpcLogger.Printf("Send to client-streaming rpc by goroutine %d in client %d for call number %d: %s", rid, cid, k, string(jsonStr))
             }
             
             scds, er := stream.CloseAndRecv()
             if er != nil {
-                myGrpcLogger.Fatalf("Failed to receive from client-streaming rpc by goroutine %d in client %d for call number %d: %v", rid, cid, k, er)
+                logging.Fatal(myGrpcLogger, "Failed to receive from client-streaming rpc", "goroutine", rid, "client", cid, "call", k, "err", describeError(er))
+            }
+            for _, scd := range scds.GetChainDescs() {
+                logResult(scd, rid, cid, k)
             }
-            jsonStr, _ := json.Marshal(scds)
-            myGrpcLogger.Printf("Get a response from client-streaming rpc by goroutine %d in client %d for call number %d: %s", rid, cid, k, string(jsonStr))
             
             time.Sleep(c.callInterval)
         }
@@ -239,18 +322,18 @@ func (c *myGrpcClientSet) CallClientStreamRPC(client pb.MyGrpcClient, ech chan s
         return nil        
     }
     
-    myGrpcLogger.Printf("Current implementations only support using test data, requests are pass")
+    myGrpcLogger.Warn("Current implementations only support using test data, requests are pass")
     close(ech)
     return nil
 }
 
 func (c *myGrpcClientSet) CallBiStreamRPC(client pb.MyGrpcClient, ech chan struct{}, rid, cid int) error {    
     if c.useTestFile {
-        myGrpcLogger.Printf("Calling bi-streaming rpc by goroutine %d in client %d", rid, cid)
+        myGrpcLogger.Info("Calling bi-streaming rpc", "goroutine", rid, "client", cid)
         cfs := make([]context.CancelFunc, c.callNum)  // cancel function list for the context in each calling
         ctxs := make([]context.Context, c.callNum)  // context list for each calling
         for k := 0; k < c.callNum; k++ {
-            ctxs[k], cfs[k] = context.WithTimeout(context.Background(), c.callTimeout + time.Duration(k * int(c.callInterval)))
+            ctxs[k], cfs[k] = context.WithTimeout(c.baseContext(), c.callTimeout + time.Duration(k * int(c.callInterval)))
         }            
         defer release(ech, cfs)
         
@@ -260,7 +343,7 @@ func (c *myGrpcClientSet) CallBiStreamRPC(client pb.MyGrpcClient, ech chan struc
             
             stream, err = client.GetChainsReqsResps(ctxs[k])
             if err != nil {
-                myGrpcLogger.Fatalf("Failed to call bi-streaming rpc by goroutine %d in client %d for call number %d: %v", rid, cid, k, err)
+                logging.Fatal(myGrpcLogger, "Failed to call bi-streaming rpc", "goroutine", rid, "client", cid, "call", k, "err", describeError(err))
             }
             waitch := make(chan struct {})
             go func() {
@@ -271,20 +354,17 @@ func (c *myGrpcClientSet) CallBiStreamRPC(client pb.MyGrpcClient, ech chan struc
                         return
                     }
                     if er != nil {
-                        myGrpcLogger.Fatalf("Failed to receive from server-streaming rpc by co-goroutine of goroutine %d in client %d for call number %d: %v", rid, cid, k, er)
+                        logging.Fatal(myGrpcLogger, "Failed to receive from server-streaming rpc", "goroutine", rid, "client", cid, "co_goroutine", true, "call", k, "err", describeError(er))
                     }
-                    jsonStr, _ := json.Marshal(scd)
-                    myGrpcLogger.Printf("Get a response from server-streaming rpc by co-goroutine of goroutine %d in client %d for call number %d: %s", rid, cid, k, string(jsonStr))
+                    logResult(scd, rid, cid, k)
                 }
             }()
             
             for _, sc := range c.testChainInfo {
                 er := stream.Send(sc)
                 if er != nil {
-                    myGrpcLogger.Fatalf("Failed to send to bi-streaming rpc by goroutine %d in client %d for call number %d: %v", rid, cid, k, er)
+                    logging.Fatal(myGrpcLogger, "Failed to send to bi-streaming rpc", "goroutine", rid, "client", cid, "call", k, "err", describeError(er))
                 }
-                jsonStr, _ := json.Marshal(sc)
-                myGrpcLogger.Printf("Send to client-streaming rpc by goroutine %d in client %d for call number %d: %s", rid, cid, k, string(jsonStr))
                 time.Sleep(1e6)
             }
             stream.CloseSend()  // function of the grpc.ClientStream interface, which is the part of the interface combination composing pb.MyGrpc_GetChainsReqsRespsClient interface
@@ -296,7 +376,7 @@ func (c *myGrpcClientSet) CallBiStreamRPC(client pb.MyGrpcClient, ech chan struc
         return nil        
     }
     
-    myGrpcLogger.Printf("Current implementations only support using test data, requests are pass")
+    myGrpcLogger.Warn("Current implementations only support using test data, requests are pass")
     close(ech)
     return nil
 }
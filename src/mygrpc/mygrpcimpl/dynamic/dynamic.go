@@ -0,0 +1,272 @@
+// Dynamic client of any grpc service, discovering the services through the server
+// reflection and converting the messages from and to json
+
+package dynamic
+
+import (
+    "encoding/json"
+    "fmt"
+    "io"
+    "sort"
+    "strings"
+
+    "golang.org/x/net/context"
+    "google.golang.org/grpc"
+    rpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+    "google.golang.org/protobuf/encoding/protojson"
+    "google.golang.org/protobuf/proto"
+    "google.golang.org/protobuf/reflect/protodesc"
+    "google.golang.org/protobuf/reflect/protoreflect"
+    "google.golang.org/protobuf/reflect/protoregistry"
+    "google.golang.org/protobuf/types/descriptorpb"
+    "google.golang.org/protobuf/types/dynamicpb"
+)
+
+// error type used to raise exceptions when discovering or calling a method
+type DynamicError struct {
+    Name   string   // name of the service or method
+    Msg    string
+    Err    error
+}
+
+func (e *DynamicError) Error() string {
+    if e.Err == nil {
+        return fmt.Sprintf("%s: %s", e.Name, e.Msg)
+    }
+    return fmt.Sprintf("%s: %s: %v", e.Name, e.Msg, e.Err)
+}
+
+// client calling the methods of a server described by its reflection service
+type Client struct {
+    conn    *grpc.ClientConn
+    files   map[string]*descriptorpb.FileDescriptorProto   // files fetched from the server, by name
+}
+
+func NewClient(conn *grpc.ClientConn) *Client {
+    return &Client{conn: conn, files: make(map[string]*descriptorpb.FileDescriptorProto)}
+}
+
+// send one request to the reflection service of the server
+func (c *Client) reflect(ctx context.Context, req *rpb.ServerReflectionRequest) (*rpb.ServerReflectionResponse, error) {
+    stream, err := rpb.NewServerReflectionClient(c.conn).ServerReflectionInfo(ctx)
+    if err != nil {
+        return nil, err
+    }
+    defer stream.CloseSend()
+    if err := stream.Send(req); err != nil {
+        return nil, err
+    }
+    resp, err := stream.Recv()
+    if err != nil {
+        return nil, err
+    }
+    if e := resp.GetErrorResponse(); e != nil {
+        return nil, fmt.Errorf("reflection error %d: %s", e.GetErrorCode(), e.GetErrorMessage())
+    }
+    return resp, nil
+}
+
+// return the names of the services of the server, sorted
+func (c *Client) ListServices(ctx context.Context) ([]string, error) {
+    resp, err := c.reflect(ctx, &rpb.ServerReflectionRequest{MessageRequest: &rpb.ServerReflectionRequest_ListServices{}})
+    if err != nil {
+        return nil, &DynamicError{Name: c.conn.Target(), Msg: "Failed to list the services", Err: err}
+    }
+    var names []string
+    for _, s := range resp.GetListServicesResponse().GetService() {
+        names = append(names, s.GetName())
+    }
+    sort.Strings(names)
+    return names, nil
+}
+
+// fetch the files defining a symbol, or named by name if symbol is empty, with
+// all their dependencies
+func (c *Client) fetch(ctx context.Context, symbol, name string) error {
+    req := &rpb.ServerReflectionRequest{MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: symbol}}
+    if symbol == "" {
+        if _, prs := c.files[name]; prs {
+            return nil
+        }
+        req = &rpb.ServerReflectionRequest{MessageRequest: &rpb.ServerReflectionRequest_FileByFilename{FileByFilename: name}}
+    }
+    resp, err := c.reflect(ctx, req)
+    if err != nil {
+        return err
+    }
+    var deps []string
+    for _, b := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+        fd := &descriptorpb.FileDescriptorProto{}
+        if err := proto.Unmarshal(b, fd); err != nil {
+            return err
+        }
+        c.files[fd.GetName()] = fd
+        deps = append(deps, fd.GetDependency()...)
+    }
+    for _, dep := range deps {
+        if _, prs := c.files[dep]; prs {
+            continue
+        }
+        // well known types may not be served, the local copy is used then
+        if _, err := protoregistry.GlobalFiles.FindFileByPath(dep); err == nil {
+            continue
+        }
+        if err := c.fetch(ctx, "", dep); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// return the descriptor of a service
+func (c *Client) Service(ctx context.Context, name string) (protoreflect.ServiceDescriptor, error) {
+    if err := c.fetch(ctx, name, ""); err != nil {
+        return nil, &DynamicError{Name: name, Msg: "Failed to fetch the service descriptor", Err: err}
+    }
+    fds := &descriptorpb.FileDescriptorSet{}
+    for _, fd := range c.files {
+        fds.File = append(fds.File, fd)
+    }
+    files, err := protodesc.NewFiles(fds)
+    if err != nil {
+        return nil, &DynamicError{Name: name, Msg: "Invalid service descriptor", Err: err}
+    }
+    d, err := files.FindDescriptorByName(protoreflect.FullName(name))
+    if err != nil {
+        return nil, &DynamicError{Name: name, Msg: "No service found", Err: err}
+    }
+    sd, ok := d.(protoreflect.ServiceDescriptor)
+    if !ok {
+        return nil, &DynamicError{Name: name, Msg: "Not a service"}
+    }
+    return sd, nil
+}
+
+// return the descriptor of a method named like 'pkg.Service/Method' or 'pkg.Service.Method'
+func (c *Client) Method(ctx context.Context, name string) (protoreflect.MethodDescriptor, error) {
+    i := strings.LastIndexAny(name, "/.")
+    if i <= 0 {
+        return nil, &DynamicError{Name: name, Msg: "Malformed method name, expecting 'package.Service/Method'"}
+    }
+    sd, err := c.Service(ctx, strings.TrimPrefix(name[:i], "/"))
+    if err != nil {
+        return nil, err
+    }
+    md := sd.Methods().ByName(protoreflect.Name(name[i + 1:]))
+    if md == nil {
+        return nil, &DynamicError{Name: name, Msg: "No method found"}
+    }
+    return md, nil
+}
+
+// call a method with the json requests read from in, and pass every response to out.
+// unary and server streaming methods take a single request, an empty input being an
+// empty request, which is read before the rpc starts. client streaming methods take
+// any number of them, sent while responses are received so that bidi streams
+// interleave. a request failing to decode ends the rpc with its error
+func (c *Client) Call(ctx context.Context, md protoreflect.MethodDescriptor, in io.Reader, out func(proto.Message) error) error {
+    desc := &grpc.StreamDesc{
+                StreamName:     string(md.Name()),
+                ServerStreams:  md.IsStreamingServer(),
+                ClientStreams:  md.IsStreamingClient(),
+            }
+    method := fmt.Sprintf("/%s/%s", md.Parent().FullName(), md.Name())
+    var req proto.Message
+    if !md.IsStreamingClient() {
+        var err error
+        if req, err = readRequest(md, method, in); err != nil {
+            return err
+        }
+    }
+    ctx, cancel := context.WithCancel(ctx)
+    defer cancel()
+    stream, err := c.conn.NewStream(ctx, desc, method)
+    if err != nil {
+        return err
+    }
+
+    sendErr := make(chan error, 1)
+    go func() {
+        err := c.send(stream, md, method, req, in)
+        sendErr <- err
+        if err != nil {
+            cancel()
+        }
+    }()
+
+    for {
+        resp := dynamicpb.NewMessage(md.Output())
+        err := stream.RecvMsg(resp)
+        if err == io.EOF {
+            return <-sendErr
+        }
+        if err != nil {
+            // the rpc is canceled by a failure to send, which is the one to report
+            select {
+                case e := <-sendErr:
+                    if e != nil {
+                        return e
+                    }
+                default:
+            }
+            return err
+        }
+        if err := out(resp); err != nil {
+            return err
+        }
+    }
+}
+
+// read the single request of a unary or server streaming method, an empty input
+// being an empty request
+func readRequest(md protoreflect.MethodDescriptor, method string, in io.Reader) (proto.Message, error) {
+    dec := json.NewDecoder(in)
+    raw := json.RawMessage("{}")
+    if err := dec.Decode(&raw); err != nil && err != io.EOF {
+        return nil, &DynamicError{Name: method, Msg: "Malformed json of request 0", Err: err}
+    }
+    var extra json.RawMessage
+    if err := dec.Decode(&extra); err == nil {
+        return nil, &DynamicError{Name: method, Msg: "More than one request is given to a method taking a single one"}
+    } else if err != io.EOF {
+        return nil, &DynamicError{Name: method, Msg: "Malformed json after request 0", Err: err}
+    }
+    req := dynamicpb.NewMessage(md.Input())
+    if err := protojson.Unmarshal(raw, req); err != nil {
+        return nil, &DynamicError{Name: method, Msg: "Invalid request 0", Err: err}
+    }
+    return req, nil
+}
+
+// send the requests of a call and half-close the stream: the single request req of
+// a unary or server streaming method, else the requests read from in
+func (c *Client) send(stream grpc.ClientStream, md protoreflect.MethodDescriptor, method string, req proto.Message, in io.Reader) error {
+    dec := json.NewDecoder(in)
+    for n := 0; ; n++ {
+        if !md.IsStreamingClient() {
+            if n > 0 {
+                break
+            }
+        } else {
+            var raw json.RawMessage
+            err := dec.Decode(&raw)
+            if err == io.EOF {
+                break
+            }
+            if err != nil {
+                return &DynamicError{Name: method, Msg: fmt.Sprintf("Malformed json of request %d", n), Err: err}
+            }
+            req = dynamicpb.NewMessage(md.Input())
+            if err := protojson.Unmarshal(raw, req); err != nil {
+                return &DynamicError{Name: method, Msg: fmt.Sprintf("Invalid request %d", n), Err: err}
+            }
+        }
+        if err := stream.SendMsg(req); err != nil {
+            if err == io.EOF {
+                return nil   // the server ended the rpc, its status is returned by RecvMsg
+            }
+            return err
+        }
+    }
+    return stream.CloseSend()
+}
\ No newline at end of file
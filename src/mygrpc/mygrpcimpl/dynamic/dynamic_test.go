@@ -0,0 +1,158 @@
+package dynamic
+
+import (
+    "net"
+    "strings"
+    "testing"
+
+    pb "mygrpc/mygrpc"
+    reg "mygrpc/mygrpcimpl/registry"
+    "mygrpc/mygrpcimpl/server"
+
+    "golang.org/x/net/context"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/reflection"
+    "google.golang.org/grpc/status"
+    "google.golang.org/grpc/test/bufconn"
+    "google.golang.org/protobuf/encoding/protojson"
+    "google.golang.org/protobuf/proto"
+    "google.golang.org/protobuf/protoadapt"
+)
+
+// serve MyGrpc and the reflection service over an in-memory connection for the
+// duration of the test, and return a client of the server
+func dial(t *testing.T) *Client {
+    registry, err := reg.NewMemRegistry([]*pb.ServiceDescriptor{{SvcName: "svcA", SvcDesc: "a"}, {SvcName: "svcB", SvcDesc: "b"}})
+    if err != nil {
+        t.Fatal(err)
+    }
+    lis := bufconn.Listen(1 << 20)
+    srv := grpc.NewServer()
+    pb.RegisterMyGrpcServer(srv, server.NewMyGrpcServer(registry, "svcA", nil, nil))
+    reflection.Register(srv)
+    go srv.Serve(lis)
+    t.Cleanup(srv.Stop)
+    conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+        return lis.DialContext(ctx)
+    }))
+    if err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() { conn.Close() })
+    return NewClient(conn)
+}
+
+// json of a service chain of the given services
+const (
+    chain1 = `{"chain_id": 1, "chain_len": 2, "chain": [{"svc_name": "svcA", "svc_pos": 1}, {"svc_name": "svcB", "svc_pos": 2}]}`
+    chain2 = `{"chain_id": 2, "chain_len": 1, "chain": [{"svc_name": "svcB", "svc_pos": 1}]}`
+)
+
+// call a method with the given input, returning the responses as json
+func call(t *testing.T, c *Client, method, in string) ([]string, error) {
+    t.Helper()
+    md, err := c.Method(context.Background(), method)
+    if err != nil {
+        t.Fatal(err)
+    }
+    var out []string
+    err = c.Call(context.Background(), md, strings.NewReader(in), func(m proto.Message) error {
+        data, err := protojson.Marshal(m)
+        out = append(out, string(data))
+        return err
+    })
+    return out, err
+}
+
+func TestListServices(t *testing.T) {
+    c := dial(t)
+    names, err := c.ListServices(context.Background())
+    if err != nil {
+        t.Fatal(err)
+    }
+    if strings.Join(names, " ") != "grpc.reflection.v1.ServerReflection grpc.reflection.v1alpha.ServerReflection mygrpc.MyGrpc" {
+        t.Errorf("services = %v", names)
+    }
+    for _, name := range []string{"mygrpc.MyGrpc", "/mygrpc.MyGrpc/Missing", "mygrpc.Missing/GetChainReqResp"} {
+        if _, err := c.Method(context.Background(), name); err == nil {
+            t.Errorf("found method %s", name)
+        }
+    }
+    if md, err := c.Method(context.Background(), "mygrpc.MyGrpc.GetChainsReqsResps"); err != nil || !md.IsStreamingClient() || !md.IsStreamingServer() {
+        t.Errorf("method = %v, %v", md, err)
+    }
+}
+
+func TestCall(t *testing.T) {
+    c := dial(t)
+    for _, tc := range []struct {
+        method   string
+        in       string
+        want     []string   // services of the chains in every response
+    }{
+        {"mygrpc.MyGrpc/GetChainReqResp", chain1, []string{"svcA svcB"}},
+        {"mygrpc.MyGrpc/GetChainsReqResps", `{"chains": [` + chain1 + `, ` + chain2 + `]}`, []string{"svcA svcB", "svcB"}},
+        {"mygrpc.MyGrpc/GetChainsReqsResp", chain1 + "\n" + chain2, []string{"svcA svcB svcB"}},
+        {"mygrpc.MyGrpc/GetChainsReqsResps", chain1 + chain2 + chain1, []string{"svcA svcB", "svcB", "svcA svcB"}},
+    } {
+        out, err := call(t, c, tc.method, tc.in)
+        if err != nil {
+            t.Errorf("%s: %v", tc.method, err)
+            continue
+        }
+        var got []string
+        for _, data := range out {
+            var names []string
+            // a single descriptor or a set of them
+            scds := &pb.ServiceChainDescriptors{}
+            if err := protojson.Unmarshal([]byte(data), protoadapt.MessageV2Of(scds)); err != nil || len(scds.GetChainDescs()) == 0 {
+                scd := &pb.ServiceChainDescriptor{}
+                if err := protojson.Unmarshal([]byte(data), protoadapt.MessageV2Of(scd)); err != nil {
+                    t.Fatalf("%s: response %s: %v", tc.method, data, err)
+                }
+                scds.ChainDescs = []*pb.ServiceChainDescriptor{scd}
+            }
+            for _, scd := range scds.GetChainDescs() {
+                for _, sd := range scd.GetChainDesc() {
+                    names = append(names, sd.GetSvcName())
+                }
+            }
+            got = append(got, strings.Join(names, " "))
+        }
+        if strings.Join(got, ", ") != strings.Join(tc.want, ", ") {
+            t.Errorf("%s: responses %v, want %v", tc.method, got, tc.want)
+        }
+    }
+
+    // an empty input is an empty request, which the server rejects
+    if _, err := call(t, c, "mygrpc.MyGrpc/GetChainReqResp", ""); status.Code(err) != codes.InvalidArgument {
+        t.Errorf("call with an empty request returned %v, want InvalidArgument", err)
+    }
+}
+
+func TestCallMalformed(t *testing.T) {
+    c := dial(t)
+    for _, tc := range []struct {
+        method   string
+        in       string
+        msg      string
+    }{
+        {"mygrpc.MyGrpc/GetChainReqResp", `{"chain_id": `, "Malformed json of request 0"},
+        {"mygrpc.MyGrpc/GetChainReqResp", chain1 + chain2, "More than one request"},
+        {"mygrpc.MyGrpc/GetChainReqResp", chain1 + " }", "Malformed json after request 0"},
+        {"mygrpc.MyGrpc/GetChainsReqResps", `{"chain": []}`, "Invalid request 0"},
+        {"mygrpc.MyGrpc/GetChainsReqsResp", chain1 + ` {"chain_id": `, "Malformed json of request 1"},
+        {"mygrpc.MyGrpc/GetChainsReqsResps", chain1 + ` {"svc": 1}`, "Invalid request 1"},
+    } {
+        out, err := call(t, c, tc.method, tc.in)
+        de, ok := err.(*DynamicError)
+        if !ok || !strings.Contains(de.Msg, tc.msg) {
+            t.Errorf("%s with %q: %v, want %q", tc.method, tc.in, err, tc.msg)
+        }
+        // the single request of a method is read before the rpc starts
+        if !strings.Contains(tc.method, "ReqsResp") && len(out) > 0 {
+            t.Errorf("%s with %q: responses %v before the error", tc.method, tc.in, out)
+        }
+    }
+}
\ No newline at end of file
@@ -0,0 +1,253 @@
+// Service chains stored in a registry along with the services
+
+package registry
+
+import (
+    "fmt"
+    "io/ioutil"
+    "sort"
+
+    pb "mygrpc/mygrpc"
+    val "mygrpc/util/validate"
+
+    "github.com/golang/protobuf/proto"
+)
+
+// read-only access to stored service chains by id
+type ChainLookup interface {
+    // return the stored chain with the given id, and whether it is found
+    GetChain(id int32) (*pb.StoredChain, bool)
+}
+
+// optional interface of the registries also storing service chains, which are
+// fetched by the requests carrying only their chain_id. chains returned are copies
+// owned by the caller
+type ChainStore interface {
+    ChainLookup
+
+    // return all the stored chains ordered by id
+    ListChains() ([]*pb.StoredChain, error)
+
+    // add a chain into the registry, or replace the one with the same id
+    PutChain(c *pb.StoredChain) error
+
+    // remove the chain with the given id from the registry, which is refused while
+    // other stored chains reference it
+    DeleteChain(id int32) error
+
+    // atomically replace all the stored chains
+    ReplaceChains(cs []*pb.StoredChain) error
+}
+
+// report whether an error raised by a registry is caused by a missing chain
+func IsChainNotFound(err error) bool {
+    re, ok := err.(*RegistryError)
+    return ok && re.Msg == msgChainNotFound
+}
+
+// report whether an error raised by a registry is caused by a chain still referenced
+// by other stored chains
+func IsChainReferenced(err error) bool {
+    re, ok := err.(*RegistryError)
+    return ok && re.Msg == msgChainReferenced
+}
+
+const (
+    msgChainNotFound     = "No service chain found"
+    msgChainReferenced   = "Service chain is referenced by other stored chains"
+)
+
+// validate a chain before storing it into a registry
+func checkChain(c *pb.StoredChain) error {
+    if c == nil {
+        return &RegistryError{Msg: "Nil service chain"}
+    }
+    if err := val.ValStoredChain(c); err != nil {
+        return &RegistryError{ChainId: c.GetChainId(), Msg: "Invalid service chain", Err: err}
+    }
+    return nil
+}
+
+// validate a set of chains meant to replace the chains of a registry
+func CheckChains(cs []*pb.StoredChain) error {
+    ids := make(map[int32]bool, len(cs))
+    for _, c := range cs {
+        if err := checkChain(c); err != nil {
+            return err
+        }
+        if ids[c.GetChainId()] {
+            return &RegistryError{ChainId: c.GetChainId(), Msg: "Duplicated service chain"}
+        }
+        ids[c.GetChainId()] = true
+    }
+    return nil
+}
+
+// read a list of stored chains from a json file
+func LoadChainFile(path string) ([]*pb.StoredChain, error) {
+    data, err := ioutil.ReadFile(path)
+    if err != nil {
+        return nil, &RegistryError{Msg: "Failed to load service chains from " + path, Err: err}
+    }
+    cs, err := UnmarshalChains(data)
+    if err != nil {
+        return nil, &RegistryError{Msg: "Failed to unmarshal the service chains from " + path, Err: err}
+    }
+    return cs, nil
+}
+
+// decode a json list of stored chains in the format of UnmarshalServices
+func UnmarshalChains(data []byte) ([]*pb.StoredChain, error) {
+    var cs []*pb.StoredChain
+    err := unmarshalList(data, func() proto.Message {
+        cs = append(cs, &pb.StoredChain{})
+        return cs[len(cs) - 1]
+    })
+    return cs, err
+}
+
+// encode stored chains as an indented json list read by UnmarshalChains
+func MarshalChains(cs []*pb.StoredChain) ([]byte, error) {
+    msgs := make([]proto.Message, len(cs))
+    for i, c := range cs {
+        msgs[i] = c
+    }
+    return marshalList(msgs)
+}
+
+// read-only view of the chains in a memRegistry at some point in time
+type chainSnapshot map[int32]*pb.StoredChain
+
+func (s chainSnapshot) GetChain(id int32) (*pb.StoredChain, bool) {
+    c, prs := s[id]
+    if !prs {
+        return nil, false
+    }
+    return proto.Clone(c).(*pb.StoredChain), true
+}
+
+// return the ids of the chains referencing the chain with the given id, in order
+func (s chainSnapshot) referrers(id int32) []int32 {
+    var ids []int32
+    for oid, c := range s {
+        for _, svc := range c.GetChain() {
+            if svc.GetChainRef() == id {
+                ids = append(ids, oid)
+                break
+            }
+        }
+    }
+    sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+    return ids
+}
+
+// return an error if the chain with the given id may not be deleted from chains
+func checkChainDeletion(chains chainSnapshot, id int32) error {
+    if _, prs := chains[id]; !prs {
+        return &RegistryError{ChainId: id, Msg: msgChainNotFound}
+    }
+    if ids := chains.referrers(id); len(ids) > 0 {
+        return &RegistryError{ChainId: id, Msg: msgChainReferenced, Err: fmt.Errorf("Referenced by the chains %v", ids)}
+    }
+    return nil
+}
+
+// compute the events turning the chains in old into the ones in cur, ordered by id
+func diffChains(old, cur chainSnapshot) []Event {
+    var evs []Event
+    for id, c := range cur {
+        oc, prs := old[id]
+        switch {
+            case !prs:
+                evs = append(evs, Event{Type: pb.EventType_ADDED, Chain: c})
+            case !proto.Equal(oc, c):
+                evs = append(evs, Event{Type: pb.EventType_UPDATED, Chain: c})
+        }
+    }
+    for id, oc := range old {
+        if _, prs := cur[id]; !prs {
+            evs = append(evs, Event{Type: pb.EventType_DELETED, Chain: oc})
+        }
+    }
+    sort.Slice(evs, func(i, j int) bool { return evs[i].Chain.GetChainId() < evs[j].Chain.GetChainId() })
+    return evs
+}
+
+func (r *memRegistry) GetChain(id int32) (*pb.StoredChain, bool) {
+    r.mu.RLock()
+    chains := r.chains
+    r.mu.RUnlock()
+    return chains.GetChain(id)
+}
+
+func (r *memRegistry) ListChains() ([]*pb.StoredChain, error) {
+    r.mu.RLock()
+    chains := r.chains
+    r.mu.RUnlock()
+    cs := make([]*pb.StoredChain, 0, len(chains))
+    for _, c := range chains {
+        cs = append(cs, proto.Clone(c).(*pb.StoredChain))
+    }
+    sort.Slice(cs, func(i, j int) bool { return cs[i].GetChainId() < cs[j].GetChainId() })
+    return cs, nil
+}
+
+func (r *memRegistry) PutChain(c *pb.StoredChain) error {
+    if err := checkChain(c); err != nil {
+        return err
+    }
+    c = proto.Clone(c).(*pb.StoredChain)
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    ev := Event{Type: pb.EventType_ADDED, Chain: c}
+    if oc, prs := r.chains[c.GetChainId()]; prs {
+        if proto.Equal(oc, c) {
+            return nil
+        }
+        ev.Type = pb.EventType_UPDATED
+    }
+    chains := make(chainSnapshot, len(r.chains) + 1)
+    for id, oc := range r.chains {
+        chains[id] = oc
+    }
+    chains[c.GetChainId()] = c
+    r.chains = chains
+    r.hub.publish([]Event{ev})
+    return nil
+}
+
+func (r *memRegistry) DeleteChain(id int32) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    if err := checkChainDeletion(r.chains, id); err != nil {
+        return err
+    }
+    oc := r.chains[id]
+    chains := make(chainSnapshot, len(r.chains))
+    for oid, c := range r.chains {
+        if oid != id {
+            chains[oid] = c
+        }
+    }
+    r.chains = chains
+    r.hub.publish([]Event{{Type: pb.EventType_DELETED, Chain: oc}})
+    return nil
+}
+
+// atomically replace all the chains in the registry. the registry is left untouched
+// if any of the given chains is invalid
+func (r *memRegistry) ReplaceChains(cs []*pb.StoredChain) error {
+    if err := CheckChains(cs); err != nil {
+        return err
+    }
+    chains := make(chainSnapshot, len(cs))
+    for _, c := range cs {
+        chains[c.GetChainId()] = proto.Clone(c).(*pb.StoredChain)
+    }
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    evs := diffChains(r.chains, chains)
+    r.chains = chains
+    r.hub.publish(evs)
+    return nil
+}
\ No newline at end of file
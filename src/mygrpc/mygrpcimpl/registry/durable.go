@@ -0,0 +1,609 @@
+// Durable service registry persisted in a directory as a snapshot and a write-ahead log
+
+package registry
+
+import (
+    "bufio"
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "hash/crc32"
+    "io"
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "sync"
+
+    pb "mygrpc/mygrpc"
+    "mygrpc/util/logging"
+
+    "github.com/golang/protobuf/proto"
+    "google.golang.org/protobuf/encoding/protojson"
+    "google.golang.org/protobuf/protoadapt"
+)
+
+const (
+    SnapshotFile   = "snapshot.json"   // services at the last compaction, in the format of the json file registry
+    ChainsFile     = "chains.json"   // stored chains at the last compaction
+    WalFile        = "wal.log"   // modifications made since the last compaction
+    lockFile       = "LOCK"
+
+    DefaultCompactEvery = 1000   // number of logged modifications triggering a compaction
+
+    opPut             = "put"
+    opDelete          = "delete"
+    opReplace         = "replace"
+    opPutChain        = "put_chain"
+    opDeleteChain     = "delete_chain"
+    opReplaceChains   = "replace_chains"
+)
+
+var logger = logging.Logger("registry")
+
+// a modification in the write-ahead log. the log holds one record per line, made of
+// the crc32 of the json of the record in hex, a space and the json
+type walRecord struct {
+    Op         string
+    Service    *pb.ServiceDescriptor   // descriptor put
+    SvcName    string   // name of the service deleted
+    Services   []*pb.ServiceDescriptor   // descriptors replacing all the services
+    Chain      *pb.StoredChain   // chain put
+    ChainId    int32   // id of the chain deleted
+    Chains     []*pb.StoredChain   // chains replacing all the chains
+}
+
+// json of a walRecord, whose messages are encoded like in the snapshots
+type walJson struct {
+    Op         string            `json:"op"`
+    Service    json.RawMessage   `json:"service,omitempty"`
+    SvcName    string            `json:"svc_name,omitempty"`
+    Services   json.RawMessage   `json:"services,omitempty"`
+    Chain      json.RawMessage   `json:"chain,omitempty"`
+    ChainId    int32             `json:"chain_id,omitempty"`
+    Chains     json.RawMessage   `json:"chains,omitempty"`
+}
+
+// registry keeping the services and the chains in memory and persisting every modification in a
+// write-ahead log before applying it. the log is compacted into a snapshot every
+// compactEvery modifications. replaying the log onto a snapshot already including
+// some of its records gives the same services, so a crash at any point recovers
+// the services of the last modification written to the log
+type fileRegistry struct {
+    *memRegistry
+    dir            string
+    compactEvery   int
+    mu             sync.Mutex   // serializes the modifications, logging and applying them in the same order
+    wal            *os.File
+    records        int   // number of records in the log
+    lock           *os.File   // lock held on the directory while the registry is open
+}
+
+// open the registry persisted in dir, which is created if missing. a record torn by
+// a crash at the end of the log is dropped. compactEvery is DefaultCompactEvery if
+// not positive
+func OpenFileRegistry(dir string, compactEvery int) (*fileRegistry, error) {
+    if compactEvery <= 0 {
+        compactEvery = DefaultCompactEvery
+    }
+    if err := os.MkdirAll(dir, 0755); err != nil {
+        return nil, &RegistryError{Msg: "Failed to create " + dir, Err: err}
+    }
+    lock, err := lockDir(filepath.Join(dir, lockFile))
+    if err != nil {
+        return nil, &RegistryError{Msg: "Failed to lock " + dir + ", which may be used by another process", Err: err}
+    }
+    r := &fileRegistry{dir: dir, compactEvery: compactEvery, lock: lock}
+    sds, cs, err := r.recover()
+    if err != nil {
+        unlockDir(lock)
+        return nil, err
+    }
+    if r.memRegistry, err = NewMemRegistry(sds); err == nil {
+        err = r.memRegistry.ReplaceChains(cs)
+    }
+    if err != nil {
+        r.Close()
+        return nil, err
+    }
+    return r, nil
+}
+
+// read the snapshots and replay the log, returning the services and the chains recovered
+func (r *fileRegistry) recover() ([]*pb.ServiceDescriptor, []*pb.StoredChain, error) {
+    svcs := make(map[string]*pb.ServiceDescriptor)
+    chains := make(map[int32]*pb.StoredChain)
+    snapshot := filepath.Join(r.dir, SnapshotFile)
+    if _, err := os.Stat(snapshot); err == nil {
+        sds, err := LoadServiceFile(snapshot)
+        if err != nil {
+            return nil, nil, err
+        }
+        for _, sd := range sds {
+            svcs[sd.GetSvcName()] = sd
+        }
+    } else if !os.IsNotExist(err) {
+        return nil, nil, &RegistryError{Msg: "Failed to stat " + snapshot, Err: err}
+    }
+    chainsSnapshot := filepath.Join(r.dir, ChainsFile)
+    if _, err := os.Stat(chainsSnapshot); err == nil {
+        cs, err := LoadChainFile(chainsSnapshot)
+        if err != nil {
+            return nil, nil, err
+        }
+        for _, c := range cs {
+            chains[c.GetChainId()] = c
+        }
+    } else if !os.IsNotExist(err) {
+        return nil, nil, &RegistryError{Msg: "Failed to stat " + chainsSnapshot, Err: err}
+    }
+
+    path := filepath.Join(r.dir, WalFile)
+    wal, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+    if err != nil {
+        return nil, nil, &RegistryError{Msg: "Failed to open " + path, Err: err}
+    }
+    valid, err := r.replay(wal, svcs, chains)
+    if err == nil {
+        err = truncateTail(wal, valid)
+    }
+    if err != nil {
+        wal.Close()
+        return nil, nil, &RegistryError{Msg: "Failed to recover the log " + path, Err: err}
+    }
+    r.wal = wal
+
+    sds := make([]*pb.ServiceDescriptor, 0, len(svcs))
+    for _, sd := range svcs {
+        sds = append(sds, sd)
+    }
+    cs := make([]*pb.StoredChain, 0, len(chains))
+    for _, c := range chains {
+        cs = append(cs, c)
+    }
+    return sds, cs, nil
+}
+
+// apply the records of the log to svcs and chains, returning the size of the log up to the
+// last valid record. replaying stops at the first record torn or corrupted
+func (r *fileRegistry) replay(wal io.Reader, svcs map[string]*pb.ServiceDescriptor, chains map[int32]*pb.StoredChain) (int64, error) {
+    br := bufio.NewReader(wal)
+    var valid int64
+    for {
+        line, err := br.ReadBytes('\n')
+        if err == io.EOF {
+            if len(line) > 0 {
+                logger.Warn("Dropped a torn record at the end of the log", "dir", r.dir, "offset", valid)
+            }
+            return valid, nil
+        }
+        if err != nil {
+            return 0, err
+        }
+        rec, err := decodeRecord(line)
+        if err != nil {
+            logger.Warn("Dropped the log from a corrupted record", "dir", r.dir, "offset", valid, "err", err)
+            return valid, nil
+        }
+        switch rec.Op {
+            case opPut:
+                svcs[rec.Service.GetSvcName()] = rec.Service
+            case opDelete:
+                delete(svcs, rec.SvcName)
+            case opReplace:
+                for name := range svcs {
+                    delete(svcs, name)
+                }
+                for _, sd := range rec.Services {
+                    svcs[sd.GetSvcName()] = sd
+                }
+            case opPutChain:
+                chains[rec.Chain.GetChainId()] = rec.Chain
+            case opDeleteChain:
+                delete(chains, rec.ChainId)
+            case opReplaceChains:
+                for id := range chains {
+                    delete(chains, id)
+                }
+                for _, c := range rec.Chains {
+                    chains[c.GetChainId()] = c
+                }
+        }
+        valid += int64(len(line))
+        r.records++
+    }
+}
+
+// drop the content of the log after size and position it at the end
+func truncateTail(wal *os.File, size int64) error {
+    if err := wal.Truncate(size); err != nil {
+        return err
+    }
+    _, err := wal.Seek(size, io.SeekStart)
+    return err
+}
+
+func encodeRecord(rec *walRecord) ([]byte, error) {
+    opts := protojson.MarshalOptions{UseProtoNames: true}
+    wj := walJson{Op: rec.Op, SvcName: rec.SvcName, ChainId: rec.ChainId}
+    var err error
+    switch rec.Op {
+        case opPut:
+            wj.Service, err = opts.Marshal(protoadapt.MessageV2Of(rec.Service))
+        case opReplace:
+            wj.Services, err = MarshalServices(rec.Services)
+        case opPutChain:
+            wj.Chain, err = opts.Marshal(protoadapt.MessageV2Of(rec.Chain))
+        case opReplaceChains:
+            wj.Chains, err = MarshalChains(rec.Chains)
+    }
+    if err != nil {
+        return nil, err
+    }
+    // the json of the messages is compacted into a single line
+    data, err := json.Marshal(&wj)
+    if err != nil {
+        return nil, err
+    }
+    return []byte(fmt.Sprintf("%08x %s\n", crc32.ChecksumIEEE(data), data)), nil
+}
+
+// decode the json of a message of a record, which is required by its operation
+func unmarshalRecordMessage(data json.RawMessage, m proto.Message) error {
+    if len(data) == 0 {
+        return fmt.Errorf("Missing message")
+    }
+    return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, protoadapt.MessageV2Of(m))
+}
+
+func decodeRecord(line []byte) (*walRecord, error) {
+    line = bytes.TrimSuffix(line, []byte("\n"))
+    if len(line) < 10 || line[8] != ' ' {
+        return nil, fmt.Errorf("Malformed record")
+    }
+    var sum uint32
+    if _, err := fmt.Sscanf(string(line[:8]), "%08x", &sum); err != nil {
+        return nil, fmt.Errorf("Malformed checksum: %v", err)
+    }
+    data := line[9:]
+    if crc32.ChecksumIEEE(data) != sum {
+        return nil, fmt.Errorf("Checksum mismatch")
+    }
+    var wj walJson
+    if err := json.Unmarshal(data, &wj); err != nil {
+        return nil, err
+    }
+    rec := walRecord{Op: wj.Op, SvcName: wj.SvcName, ChainId: wj.ChainId}
+    switch rec.Op {
+        case opPut:
+            rec.Service = &pb.ServiceDescriptor{}
+            if err := unmarshalRecordMessage(wj.Service, rec.Service); err != nil {
+                return nil, err
+            }
+            if err := checkDescriptor(rec.Service); err != nil {
+                return nil, err
+            }
+        case opDelete:
+        case opReplace:
+            var err error
+            if rec.Services, err = UnmarshalServices(wj.Services); err != nil {
+                return nil, err
+            }
+            if err := CheckServices(rec.Services); err != nil {
+                return nil, err
+            }
+        case opPutChain:
+            rec.Chain = &pb.StoredChain{}
+            if err := unmarshalRecordMessage(wj.Chain, rec.Chain); err != nil {
+                return nil, err
+            }
+            if err := checkChain(rec.Chain); err != nil {
+                return nil, err
+            }
+        case opDeleteChain:
+        case opReplaceChains:
+            var err error
+            if rec.Chains, err = UnmarshalChains(wj.Chains); err != nil {
+                return nil, err
+            }
+            if err := CheckChains(rec.Chains); err != nil {
+                return nil, err
+            }
+        default:
+            return nil, fmt.Errorf("Unknown operation %q", rec.Op)
+    }
+    return &rec, nil
+}
+
+// append a record to the log and flush it to the disk
+func (r *fileRegistry) log(rec *walRecord) error {
+    if r.wal == nil {
+        return &RegistryError{Msg: "The registry is closed"}
+    }
+    line, err := encodeRecord(rec)
+    if err != nil {
+        return &RegistryError{Msg: "Failed to encode the log record", Err: err}
+    }
+    if _, err := r.wal.Write(line); err != nil {
+        return &RegistryError{Msg: "Failed to write the log", Err: err}
+    }
+    if err := r.wal.Sync(); err != nil {
+        return &RegistryError{Msg: "Failed to sync the log", Err: err}
+    }
+    r.records++
+    return nil
+}
+
+// compact the log once it holds enough records. a failed compaction is only
+// reported as the modifications are already durable in the log
+func (r *fileRegistry) maybeCompact() {
+    if r.records < r.compactEvery {
+        return
+    }
+    if err := r.compactLocked(); err != nil {
+        logger.Error("Failed to compact the registry, keep appending to the log", "dir", r.dir, "err", err)
+    }
+}
+
+func (r *fileRegistry) PutService(sd *pb.ServiceDescriptor) error {
+    if err := checkDescriptor(sd); err != nil {
+        return err
+    }
+    sd = storedCopy(sd)
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    if osd, prs := r.memRegistry.GetService(sd.GetSvcName()); prs && proto.Equal(osd, sd) {
+        return nil
+    }
+    if err := r.log(&walRecord{Op: opPut, Service: sd}); err != nil {
+        return err
+    }
+    if err := r.memRegistry.PutService(sd); err != nil {
+        return err
+    }
+    r.maybeCompact()
+    return nil
+}
+
+func (r *fileRegistry) DeleteService(name string) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    if _, prs := r.memRegistry.GetService(name); !prs {
+        return &RegistryError{SvcName: name, Msg: msgNotFound}
+    }
+    if err := r.log(&walRecord{Op: opDelete, SvcName: name}); err != nil {
+        return err
+    }
+    if err := r.memRegistry.DeleteService(name); err != nil {
+        return err
+    }
+    r.maybeCompact()
+    return nil
+}
+
+// atomically replace all the services in the registry. the registry is left
+// untouched if any of the given descriptors is invalid
+func (r *fileRegistry) ReplaceServices(sds []*pb.ServiceDescriptor) error {
+    if err := CheckServices(sds); err != nil {
+        return err
+    }
+    stored := make([]*pb.ServiceDescriptor, len(sds))
+    for i, sd := range sds {
+        stored[i] = storedCopy(sd)
+    }
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    if err := r.log(&walRecord{Op: opReplace, Services: stored}); err != nil {
+        return err
+    }
+    if err := r.memRegistry.ReplaceServices(stored); err != nil {
+        return err
+    }
+    r.maybeCompact()
+    return nil
+}
+
+func (r *fileRegistry) PutChain(c *pb.StoredChain) error {
+    if err := checkChain(c); err != nil {
+        return err
+    }
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    if oc, prs := r.memRegistry.GetChain(c.GetChainId()); prs && proto.Equal(oc, c) {
+        return nil
+    }
+    if err := r.log(&walRecord{Op: opPutChain, Chain: c}); err != nil {
+        return err
+    }
+    if err := r.memRegistry.PutChain(c); err != nil {
+        return err
+    }
+    r.maybeCompact()
+    return nil
+}
+
+func (r *fileRegistry) DeleteChain(id int32) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    r.memRegistry.mu.RLock()
+    err := checkChainDeletion(r.memRegistry.chains, id)
+    r.memRegistry.mu.RUnlock()
+    if err != nil {
+        return err
+    }
+    if err := r.log(&walRecord{Op: opDeleteChain, ChainId: id}); err != nil {
+        return err
+    }
+    if err := r.memRegistry.DeleteChain(id); err != nil {
+        return err
+    }
+    r.maybeCompact()
+    return nil
+}
+
+// atomically replace all the chains in the registry. the registry is left untouched
+// if any of the given chains is invalid
+func (r *fileRegistry) ReplaceChains(cs []*pb.StoredChain) error {
+    if err := CheckChains(cs); err != nil {
+        return err
+    }
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    if err := r.log(&walRecord{Op: opReplaceChains, Chains: cs}); err != nil {
+        return err
+    }
+    if err := r.memRegistry.ReplaceChains(cs); err != nil {
+        return err
+    }
+    r.maybeCompact()
+    return nil
+}
+
+// write the services and the chains into new snapshots and empty the log
+func (r *fileRegistry) Compact() error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    return r.compactLocked()
+}
+
+func (r *fileRegistry) compactLocked() error {
+    if r.wal == nil {
+        return &RegistryError{Msg: "The registry is closed"}
+    }
+    sds, _ := r.memRegistry.ListServices()
+    if err := writeFileAtomic(filepath.Join(r.dir, SnapshotFile), func(w io.Writer) error { return writeServices(w, sds) }); err != nil {
+        return err
+    }
+    cs, _ := r.memRegistry.ListChains()
+    if err := writeFileAtomic(filepath.Join(r.dir, ChainsFile), func(w io.Writer) error { return writeChains(w, cs) }); err != nil {
+        return err
+    }
+    // a crash before the log is emptied replays it onto the new snapshot
+    if err := truncateTail(r.wal, 0); err != nil {
+        return &RegistryError{Msg: "Failed to empty the log", Err: err}
+    }
+    if err := r.wal.Sync(); err != nil {
+        return &RegistryError{Msg: "Failed to sync the log", Err: err}
+    }
+    logger.Info("Compacted the registry", "dir", r.dir, "services", len(sds), "chains", len(cs), "records", r.records)
+    r.records = 0
+    return nil
+}
+
+// write path through a temporary file renamed over it, so that path holds either
+// the previous or the new content after a crash
+func writeFileAtomic(path string, write func(w io.Writer) error) error {
+    tmp := path + ".tmp"
+    f, err := os.Create(tmp)
+    if err != nil {
+        return &RegistryError{Msg: "Failed to create " + tmp, Err: err}
+    }
+    err = write(f)
+    if err == nil {
+        err = f.Sync()
+    }
+    if cerr := f.Close(); err == nil {
+        err = cerr
+    }
+    if err == nil {
+        err = os.Rename(tmp, path)
+    }
+    if err != nil {
+        os.Remove(tmp)
+        return &RegistryError{Msg: "Failed to write " + path, Err: err}
+    }
+    if d, err := os.Open(filepath.Dir(path)); err == nil {
+        d.Sync()
+        d.Close()
+    }
+    return nil
+}
+
+// write the services as an indented json list, the format read by LoadServiceFile
+func writeServices(w io.Writer, sds []*pb.ServiceDescriptor) error {
+    data, err := MarshalServices(sds)
+    if err != nil {
+        return err
+    }
+    _, err = w.Write(append(data, '\n'))
+    return err
+}
+
+// write the chains as an indented json list, the format read by LoadChainFile
+func writeChains(w io.Writer, cs []*pb.StoredChain) error {
+    data, err := MarshalChains(cs)
+    if err != nil {
+        return err
+    }
+    _, err = w.Write(append(data, '\n'))
+    return err
+}
+
+// write a point-in-time snapshot of the services to w, in the format of the json
+// file registry, and of the stored chains to cw, in the format of the chain file.
+// cw may only be nil when the registry holds no chain, so that none is lost
+func (r *fileRegistry) Export(w, cw io.Writer) error {
+    r.mu.Lock()
+    sds, _ := r.ListServices()
+    cs, _ := r.ListChains()
+    r.mu.Unlock()
+    if cw == nil && len(cs) > 0 {
+        return &RegistryError{Msg: fmt.Sprintf("The registry holds %d stored chains, which need to be exported as well", len(cs))}
+    }
+    if err := writeServices(w, sds); err != nil {
+        return err
+    }
+    if cw == nil {
+        return nil
+    }
+    return writeChains(cw, cs)
+}
+
+// replace all the services by the ones of a json list read from rd, in the format
+// of the json file registry, and all the stored chains by the ones read from crd, in
+// the format of the chain file. the chains are kept if crd is nil. nothing is replaced
+// if any of the services or the chains is invalid
+func (r *fileRegistry) Import(rd, crd io.Reader) error {
+    data, err := ioutil.ReadAll(rd)
+    if err != nil {
+        return &RegistryError{Msg: "Failed to read the services to import", Err: err}
+    }
+    sds, err := UnmarshalServices(data)
+    if err != nil {
+        return &RegistryError{Msg: "Failed to unmarshal the services to import", Err: err}
+    }
+    if err := CheckServices(sds); err != nil {
+        return err
+    }
+    if crd == nil {
+        return r.ReplaceServices(sds)
+    }
+    data, err = ioutil.ReadAll(crd)
+    if err != nil {
+        return &RegistryError{Msg: "Failed to read the chains to import", Err: err}
+    }
+    cs, err := UnmarshalChains(data)
+    if err != nil {
+        return &RegistryError{Msg: "Failed to unmarshal the chains to import", Err: err}
+    }
+    if err := CheckChains(cs); err != nil {
+        return err
+    }
+    if err := r.ReplaceServices(sds); err != nil {
+        return err
+    }
+    return r.ReplaceChains(cs)
+}
+
+// close the log and release the directory. the registry keeps serving reads but
+// refuses modifications
+func (r *fileRegistry) Close() error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    if r.wal == nil {
+        return nil
+    }
+    err := r.wal.Close()
+    r.wal = nil
+    unlockDir(r.lock)
+    return err
+}
\ No newline at end of file
@@ -0,0 +1,300 @@
+package registry
+
+import (
+    "bytes"
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "reflect"
+    "testing"
+
+    pb "mygrpc/mygrpc"
+
+    "github.com/golang/protobuf/proto"
+    "github.com/golang/protobuf/ptypes"
+)
+
+func svc(name, desc string) *pb.ServiceDescriptor {
+    return &pb.ServiceDescriptor{SvcName: name, SvcDesc: desc}
+}
+
+// return the services of a registry as name to description
+func contents(t *testing.T, r ServiceRegistry) map[string]string {
+    sds, err := r.ListServices()
+    if err != nil {
+        t.Fatal(err)
+    }
+    m := make(map[string]string, len(sds))
+    for _, sd := range sds {
+        m[sd.GetSvcName()] = sd.GetSvcDesc()
+    }
+    return m
+}
+
+func open(t *testing.T, dir string, compactEvery int) *fileRegistry {
+    r, err := OpenFileRegistry(dir, compactEvery)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return r
+}
+
+func mustDo(t *testing.T, errs ...error) {
+    for _, err := range errs {
+        if err != nil {
+            t.Fatal(err)
+        }
+    }
+}
+
+func TestReopen(t *testing.T) {
+    dir := t.TempDir()
+    r := open(t, dir, 0)
+    mustDo(t, r.PutService(svc("svcA", "a")), r.PutService(svc("svcB", "b")), r.PutService(svc("svcA", "a2")), r.DeleteService("svcB"), r.PutService(svc("svcC", "c")))
+    want := map[string]string{"svcA": "a2", "svcC": "c"}
+    if got := contents(t, r); !reflect.DeepEqual(got, want) {
+        t.Fatalf("contents = %v, want %v", got, want)
+    }
+    mustDo(t, r.Close())
+
+    r = open(t, dir, 0)
+    defer r.Close()
+    if got := contents(t, r); !reflect.DeepEqual(got, want) {
+        t.Errorf("contents after reopening = %v, want %v", got, want)
+    }
+    if _, err := OpenFileRegistry(dir, 0); err == nil {
+        t.Errorf("opened the registry twice")
+    }
+}
+
+func TestTornRecord(t *testing.T) {
+    dir := t.TempDir()
+    r := open(t, dir, 0)
+    mustDo(t, r.PutService(svc("svcA", "a")), r.PutService(svc("svcB", "b")), r.Close())
+    wal := filepath.Join(dir, WalFile)
+    data, err := ioutil.ReadFile(wal)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    // the process dies in the middle of writing a third record
+    line, _ := encodeRecord(&walRecord{Op: opPut, Service: svc("svcC", "c")})
+    mustDo(t, ioutil.WriteFile(wal, append(data, line[:len(line) / 2]...), 0644))
+    r = open(t, dir, 0)
+    if got, want := contents(t, r), map[string]string{"svcA": "a", "svcB": "b"}; !reflect.DeepEqual(got, want) {
+        t.Errorf("contents = %v, want %v", got, want)
+    }
+    if fi, _ := os.Stat(wal); fi.Size() != int64(len(data)) {
+        t.Errorf("log of %d bytes not truncated to %d", fi.Size(), len(data))
+    }
+    // the log is appended after the last valid record
+    mustDo(t, r.PutService(svc("svcD", "d")), r.Close())
+    r = open(t, dir, 0)
+    defer r.Close()
+    if got, want := contents(t, r), map[string]string{"svcA": "a", "svcB": "b", "svcD": "d"}; !reflect.DeepEqual(got, want) {
+        t.Errorf("contents = %v, want %v", got, want)
+    }
+}
+
+func TestCorruptedRecord(t *testing.T) {
+    dir := t.TempDir()
+    r := open(t, dir, 0)
+    mustDo(t, r.PutService(svc("svcA", "a")), r.PutService(svc("svcB", "b")), r.PutService(svc("svcC", "c")), r.Close())
+    wal := filepath.Join(dir, WalFile)
+    data, _ := ioutil.ReadFile(wal)
+    // a bit flips in the description of svcB, the records from it are not trusted
+    i := bytes.Index(data, []byte(`"b"`))
+    data[i + 1] = 'x'
+    mustDo(t, ioutil.WriteFile(wal, data, 0644))
+    r = open(t, dir, 0)
+    defer r.Close()
+    if got, want := contents(t, r), map[string]string{"svcA": "a"}; !reflect.DeepEqual(got, want) {
+        t.Errorf("contents = %v, want %v", got, want)
+    }
+}
+
+func TestRecordFormat(t *testing.T) {
+    dir := t.TempDir()
+    r := open(t, dir, 0)
+    sd := svc("svcA", "a")
+    sd.Labels = map[string]string{"tier": "backend"}
+    sd.CreatedAt = ptypes.TimestampNow()
+    mustDo(t, r.PutService(sd), r.ReplaceServices([]*pb.ServiceDescriptor{sd, svc("svcB", "b")}), r.Close())
+
+    // the messages are logged like in the snapshots, e.g. the timestamps as rfc 3339
+    data, _ := ioutil.ReadFile(filepath.Join(dir, WalFile))
+    if !bytes.Contains(data, []byte(`"created_at":"`)) || bytes.Count(data, []byte("\n")) != 2 {
+        t.Errorf("log = %s", data)
+    }
+    r = open(t, dir, 0)
+    defer r.Close()
+    if got, _ := r.GetService("svcA"); !proto.Equal(got, sd) {
+        t.Errorf("service replayed from the log = %v, want %v", got, sd)
+    }
+    if got, want := contents(t, r), map[string]string{"svcA": "a", "svcB": "b"}; !reflect.DeepEqual(got, want) {
+        t.Errorf("contents = %v, want %v", got, want)
+    }
+}
+
+func TestCompaction(t *testing.T) {
+    dir := t.TempDir()
+    r := open(t, dir, 3)
+    mustDo(t, r.PutService(svc("svcA", "a")), r.PutService(svc("svcB", "b")), r.DeleteService("svcA"))
+    if fi, _ := os.Stat(filepath.Join(dir, WalFile)); fi.Size() != 0 {
+        t.Errorf("log of %d bytes not emptied by the compaction", fi.Size())
+    }
+    sds, err := LoadServiceFile(filepath.Join(dir, SnapshotFile))
+    if err != nil || len(sds) != 1 || sds[0].GetSvcName() != "svcB" {
+        t.Errorf("snapshot = %v, %v, want svcB", sds, err)
+    }
+    mustDo(t, r.PutService(svc("svcC", "c")), r.Close())
+    r = open(t, dir, 3)
+    defer r.Close()
+    if got, want := contents(t, r), map[string]string{"svcB": "b", "svcC": "c"}; !reflect.DeepEqual(got, want) {
+        t.Errorf("contents = %v, want %v", got, want)
+    }
+}
+
+func TestCrashDuringCompaction(t *testing.T) {
+    dir := t.TempDir()
+    r := open(t, dir, 0)
+    mustDo(t, r.PutService(svc("svcA", "a")), r.PutService(svc("svcB", "b")), r.Compact())
+    mustDo(t, r.PutService(svc("svcC", "c")), r.DeleteService("svcA"), r.PutService(svc("svcB", "b2")))
+    wal, _ := ioutil.ReadFile(filepath.Join(dir, WalFile))
+    sds, _ := r.ListServices()
+    mustDo(t, r.Close())
+
+    // the process dies after renaming the new snapshot and before emptying the log,
+    // leaving a temporary file of a later compaction behind
+    f, _ := os.Create(filepath.Join(dir, SnapshotFile))
+    mustDo(t, writeServices(f, sds), f.Close())
+    mustDo(t, ioutil.WriteFile(filepath.Join(dir, SnapshotFile + ".tmp"), []byte("[{"), 0644))
+    mustDo(t, ioutil.WriteFile(filepath.Join(dir, WalFile), wal, 0644))
+    r = open(t, dir, 0)
+    defer r.Close()
+    if got, want := contents(t, r), map[string]string{"svcB": "b2", "svcC": "c"}; !reflect.DeepEqual(got, want) {
+        t.Errorf("contents = %v, want %v", got, want)
+    }
+}
+
+func TestExportImport(t *testing.T) {
+    r := open(t, t.TempDir(), 0)
+    defer r.Close()
+    src, err := os.Open("../../testdata/test_data_server.json")
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer src.Close()
+    mustDo(t, r.Import(src, nil))
+    want, err := LoadServiceFile("../../testdata/test_data_server.json")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got := contents(t, r); len(got) != len(want) || got[want[0].GetSvcName()] != want[0].GetSvcDesc() {
+        t.Errorf("imported %v from %v", got, want)
+    }
+
+    var buf bytes.Buffer
+    mustDo(t, r.Export(&buf, nil))
+    path := filepath.Join(t.TempDir(), "export.json")
+    mustDo(t, ioutil.WriteFile(path, buf.Bytes(), 0644))
+    fr, err := NewJsonFileRegistry(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got := contents(t, fr); !reflect.DeepEqual(got, contents(t, r)) {
+        t.Errorf("exported %v, want %v", got, contents(t, r))
+    }
+    // all the fields of the descriptors are kept, e.g. the labels and the timestamps
+    for _, sd := range want {
+        if got, _ := fr.GetService(sd.GetSvcName()); !proto.Equal(got, sd) {
+            t.Errorf("exported %v, want %v", got, sd)
+        }
+    }
+    if err := r.Import(bytes.NewBufferString(`[{"svc_name": "bad name"}]`), nil); err == nil {
+        t.Errorf("imported an invalid service")
+    }
+
+    // the stored chains are exported along the services, and may not be left behind
+    mustDo(t, r.PutChain(storedChain(1, want[0].GetSvcName())))
+    if err := r.Export(&bytes.Buffer{}, nil); err == nil {
+        t.Errorf("exported the services without the stored chains")
+    }
+    var cbuf bytes.Buffer
+    buf.Reset()
+    mustDo(t, r.Export(&buf, &cbuf))
+    cs, err := UnmarshalChains(cbuf.Bytes())
+    if err != nil || len(cs) != 1 || !proto.Equal(cs[0], storedChain(1, want[0].GetSvcName())) {
+        t.Errorf("exported chains %v, %v", cs, err)
+    }
+
+    // the chains are kept by an import without chains, replaced otherwise
+    mustDo(t, r.Import(bytes.NewBufferString(`[{"svc_name": "svcA"}]`), nil))
+    if got := chainIds(t, r); !reflect.DeepEqual(got, []int32{1}) {
+        t.Errorf("chains after importing services only = %v", got)
+    }
+    mustDo(t, r.Import(bytes.NewReader(buf.Bytes()), bytes.NewBufferString(`[{"chain_id": 2, "chain": [{"svc_name": "svcA", "svc_pos": 1}]}]`)))
+    if got := chainIds(t, r); !reflect.DeepEqual(got, []int32{2}) || len(contents(t, r)) != len(want) {
+        t.Errorf("chains after the import = %v, services %v", got, contents(t, r))
+    }
+    // nothing is imported when a chain is invalid
+    if err := r.Import(bytes.NewBufferString(`[{"svc_name": "svcA"}]`), bytes.NewBufferString(`[{"chain_id": 3}]`)); err == nil {
+        t.Errorf("imported an invalid chain")
+    }
+    if got := contents(t, r); len(got) != len(want) {
+        t.Errorf("services after a failed import = %v", got)
+    }
+}
+
+func storedChain(id int32, names ...string) *pb.StoredChain {
+    c := &pb.StoredChain{ChainId: id}
+    for i, name := range names {
+        c.Chain = append(c.Chain, &pb.Service{SvcName: name, SvcPos: int32(i + 1)})
+    }
+    return c
+}
+
+// return the ids of the chains of a registry
+func chainIds(t *testing.T, r ChainStore) []int32 {
+    cs, err := r.ListChains()
+    if err != nil {
+        t.Fatal(err)
+    }
+    ids := []int32{}
+    for _, c := range cs {
+        ids = append(ids, c.GetChainId())
+    }
+    return ids
+}
+
+func TestStoredChains(t *testing.T) {
+    dir := t.TempDir()
+    r := open(t, dir, 0)
+    mustDo(t, r.PutChain(storedChain(2, "svcB")), r.PutChain(storedChain(1, "svcA", "svcB")), r.PutChain(storedChain(3, "svcC")), r.DeleteChain(2))
+    if err := r.PutChain(storedChain(4)); err == nil {
+        t.Errorf("stored a chain without service")
+    }
+    if err := r.DeleteChain(2); !IsChainNotFound(err) {
+        t.Errorf("deleting a missing chain = %v, want not found", err)
+    }
+    mustDo(t, r.PutChain(&pb.StoredChain{ChainId: 7, Chain: []*pb.Service{{ChainRef: 3, SvcPos: 1}}}))
+    if err := r.DeleteChain(3); !IsChainReferenced(err) {
+        t.Errorf("deleting a referenced chain = %v, want referenced", err)
+    }
+    mustDo(t, r.DeleteChain(7), r.Close())
+
+    // chains are recovered from the log, then from the snapshot after a compaction
+    r = open(t, dir, 0)
+    if got, want := chainIds(t, r), []int32{1, 3}; !reflect.DeepEqual(got, want) {
+        t.Fatalf("chains = %v, want %v", got, want)
+    }
+    mustDo(t, r.Compact(), r.ReplaceChains([]*pb.StoredChain{storedChain(5, "svcD")}), r.Compact(), r.PutChain(storedChain(6, "svcA")), r.Close())
+    r = open(t, dir, 0)
+    defer r.Close()
+    if got, want := chainIds(t, r), []int32{5, 6}; !reflect.DeepEqual(got, want) {
+        t.Errorf("chains = %v, want %v", got, want)
+    }
+    if c, prs := r.GetChain(5); !prs || c.GetChain()[0].GetSvcName() != "svcD" {
+        t.Errorf("chain 5 = %v, %v", c, prs)
+    }
+}
\ No newline at end of file
@@ -0,0 +1,169 @@
+// Service registry loaded from a json file
+
+package registry
+
+import (
+    "encoding/json"
+    "fmt"
+    "io/ioutil"
+    "os"
+    "sync"
+    "time"
+
+    pb "mygrpc/mygrpc"
+
+    "github.com/golang/protobuf/proto"
+    "google.golang.org/protobuf/encoding/protojson"
+    "google.golang.org/protobuf/protoadapt"
+)
+
+// registry keeping in memory the service descriptors read from a json file
+type jsonFileRegistry struct {
+    *memRegistry
+    path       string   // path of the json file
+    reloadMu   sync.Mutex   // serializes reloading of the file
+    modTime    time.Time   // modification time of the file when last loaded
+    size       int64   // size of the file when last loaded
+}
+
+func NewJsonFileRegistry(path string) (*jsonFileRegistry, error) {
+    r := &jsonFileRegistry{path: path}
+    sds, err := r.load()
+    if err != nil {
+        return nil, err
+    }
+    mr, err := NewMemRegistry(sds)
+    if err != nil {
+        return nil, err
+    }
+    r.memRegistry = mr
+    return r, nil
+}
+
+// return the path of the json file backing the registry
+func (r *jsonFileRegistry) Path() string {
+    return r.path
+}
+
+// record the state of the json file and read it. the state is recorded even if
+// the content is invalid so that a broken file is only reported once
+func (r *jsonFileRegistry) load() ([]*pb.ServiceDescriptor, error) {
+    fi, err := os.Stat(r.path)
+    if err != nil {
+        return nil, &RegistryError{Msg: "Failed to stat " + r.path, Err: err}
+    }
+    r.modTime, r.size = fi.ModTime(), fi.Size()
+    sds, err := LoadServiceFile(r.path)
+    if err != nil {
+        return nil, err
+    }
+    if len(sds) == 0 {
+        return nil, &RegistryError{Msg: "No service found in " + r.path}
+    }
+    if err := CheckServices(sds); err != nil {
+        return nil, err
+    }
+    return sds, nil
+}
+
+// read the json file again and atomically replace the services in the registry.
+// the current services are kept if the file cannot be loaded or is invalid, else
+// modifications made since the last loading, e.g. by the admin service, are discarded
+func (r *jsonFileRegistry) Reload() error {
+    r.reloadMu.Lock()
+    defer r.reloadMu.Unlock()
+    sds, err := r.load()
+    if err != nil {
+        return err
+    }
+    return r.ReplaceServices(sds)
+}
+
+// report whether the json file differs from the one last loaded
+func (r *jsonFileRegistry) changed() bool {
+    fi, err := os.Stat(r.path)
+    if err != nil {
+        return false
+    }
+    r.reloadMu.Lock()
+    defer r.reloadMu.Unlock()
+    return !fi.ModTime().Equal(r.modTime) || fi.Size() != r.size
+}
+
+// poll the json file every interval and reload the registry when it changes,
+// until stop is closed. the result of each reloading is passed to report
+func (r *jsonFileRegistry) WatchFile(interval time.Duration, stop <-chan struct{}, report func(error)) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    for {
+        select {
+            case <-stop:
+                return
+            case <-ticker.C:
+                if r.changed() {
+                    report(r.Reload())
+                }
+        }
+    }
+}
+
+// read a list of service descriptors from a json file
+func LoadServiceFile(path string) ([]*pb.ServiceDescriptor, error) {
+    fileData, err := ioutil.ReadFile(path)
+    if err != nil {
+        return nil, &RegistryError{Msg: "Failed to load service info from " + path, Err: err}
+    }
+    sds, err := UnmarshalServices(fileData)
+    if err != nil {
+        return nil, &RegistryError{Msg: "Failed to unmarshal the service info from " + path, Err: err}
+    }
+    return sds, nil
+}
+
+// decode a json list of service descriptors, whose fields are named as in the proto,
+// e.g. svc_name, and whose timestamps are RFC 3339 strings. unknown fields are ignored
+func UnmarshalServices(data []byte) ([]*pb.ServiceDescriptor, error) {
+    var sds []*pb.ServiceDescriptor
+    err := unmarshalList(data, func() proto.Message {
+        sds = append(sds, &pb.ServiceDescriptor{})
+        return sds[len(sds) - 1]
+    })
+    return sds, err
+}
+
+// encode service descriptors as an indented json list read by UnmarshalServices
+func MarshalServices(sds []*pb.ServiceDescriptor) ([]byte, error) {
+    msgs := make([]proto.Message, len(sds))
+    for i, sd := range sds {
+        msgs[i] = sd
+    }
+    return marshalList(msgs)
+}
+
+// decode a json list of messages, each into the message returned by next
+func unmarshalList(data []byte, next func() proto.Message) error {
+    var raws []json.RawMessage
+    if err := json.Unmarshal(data, &raws); err != nil {
+        return err
+    }
+    opts := protojson.UnmarshalOptions{DiscardUnknown: true}
+    for i, raw := range raws {
+        if err := opts.Unmarshal(raw, protoadapt.MessageV2Of(next())); err != nil {
+            return fmt.Errorf("item %d: %v", i, err)
+        }
+    }
+    return nil
+}
+
+func marshalList(msgs []proto.Message) ([]byte, error) {
+    opts := protojson.MarshalOptions{UseProtoNames: true}
+    raws := make([]json.RawMessage, len(msgs))
+    for i, m := range msgs {
+        raw, err := opts.Marshal(protoadapt.MessageV2Of(m))
+        if err != nil {
+            return nil, err
+        }
+        raws[i] = raw
+    }
+    return json.MarshalIndent(raws, "", "    ")
+}
\ No newline at end of file
@@ -0,0 +1,113 @@
+package registry
+
+import (
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "reflect"
+    "testing"
+    "time"
+)
+
+// write the content to the file with a later modification time than the previous one
+func writeFile(t *testing.T, path, content string, age int) {
+    if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+        t.Fatal(err)
+    }
+    mt := time.Now().Add(time.Duration(-age) * time.Minute)
+    if err := os.Chtimes(path, mt, mt); err != nil {
+        t.Fatal(err)
+    }
+}
+
+func TestReload(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "services.json")
+    writeFile(t, path, `[{"svc_name": "svcA", "svc_desc": "a"}, {"svc_name": "svcB", "svc_desc": "b"}]`, 10)
+    r, err := NewJsonFileRegistry(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    want := map[string]string{"svcA": "a", "svcB": "b"}
+
+    // a broken file keeps the services loaded before
+    for i, content := range []string{
+        `[{"svc_name": "svcA", "svc_desc": "a2"}`,
+        `[]`,
+        `[{"svc_desc": "no name"}]`,
+        `[{"svc_name": "svcA"}, {"svc_name": "svcA"}]`,
+        `{"svc_name": "svcA"}`,
+    } {
+        writeFile(t, path, content, 9 - i)
+        if err := r.Reload(); err == nil {
+            t.Errorf("broken file %s is reloaded", content)
+        }
+        if got := contents(t, r); !reflect.DeepEqual(got, want) {
+            t.Errorf("services after reloading %s = %v, want %v", content, got, want)
+        }
+        // the broken file is only reported once
+        if r.changed() {
+            t.Errorf("broken file %s is reported as changed again", content)
+        }
+    }
+    if err := os.Remove(path); err != nil {
+        t.Fatal(err)
+    }
+    if err := r.Reload(); err == nil || !reflect.DeepEqual(contents(t, r), want) {
+        t.Errorf("reloading a removed file = %v, services %v", err, contents(t, r))
+    }
+
+    // a fixed file is picked up by the watch of the file
+    writeFile(t, path, `[{"svc_name": "svcA", "svc_desc": "a2"}, {"svc_name": "svcC", "svc_desc": "c"}]`, 0)
+    stop := make(chan struct{})
+    reported := make(chan error, 1)
+    go r.WatchFile(time.Millisecond, stop, func(err error) { reported <- err })
+    select {
+        case err := <-reported:
+            if err != nil {
+                t.Errorf("reloading the fixed file: %v", err)
+            }
+        case <-time.After(5 * time.Second):
+            t.Fatal("fixed file is not reloaded")
+    }
+    close(stop)
+    if got := contents(t, r); !reflect.DeepEqual(got, map[string]string{"svcA": "a2", "svcC": "c"}) {
+        t.Errorf("services after reloading the fixed file = %v", got)
+    }
+}
+
+func TestSnapshotIsolation(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "services.json")
+    writeFile(t, path, `[{"svc_name": "svcA", "svc_desc": "a"}, {"svc_name": "svcB", "svc_desc": "b"}]`, 10)
+    r, err := NewJsonFileRegistry(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    before := SnapshotOf(r)
+    rev := before.(Revisioned).Revision()
+
+    writeFile(t, path, `[{"svc_name": "svcA", "svc_desc": "a2"}, {"svc_name": "svcC", "svc_desc": "c"}]`, 0)
+    mustDo(t, r.Reload(), r.PutService(svc("svcD", "d")))
+    for name, desc := range map[string]string{"svcA": "a", "svcB": "b", "svcC": "", "svcD": ""} {
+        sd, prs := before.GetService(name)
+        if prs != (desc != "") || sd.GetSvcDesc() != desc {
+            t.Errorf("snapshot taken before the reload has %s = %v, %v", name, sd, prs)
+        }
+    }
+    if got := before.(Revisioned).Revision(); got != rev {
+        t.Errorf("revision of the snapshot changed from %d to %d", rev, got)
+    }
+    after := SnapshotOf(r)
+    if after.(Revisioned).Revision() == rev {
+        t.Errorf("snapshot after the reload has the same revision %d", rev)
+    }
+
+    // the descriptors of a snapshot are copies
+    sd, _ := after.GetService("svcA")
+    sd.SvcDesc = "modified"
+    if sd, _ := after.GetService("svcA"); sd.GetSvcDesc() != "a2" {
+        t.Errorf("snapshot is modified through its descriptor: %v", sd)
+    }
+    if sd, _ := r.GetService("svcA"); sd.GetSvcDesc() != "a2" {
+        t.Errorf("registry is modified through the descriptor of a snapshot: %v", sd)
+    }
+}
\ No newline at end of file
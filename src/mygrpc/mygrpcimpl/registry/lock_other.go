@@ -0,0 +1,19 @@
+//go:build !unix
+
+// Locking of the directory of a durable registry
+
+package registry
+
+import (
+    "os"
+)
+
+// the directory is not locked on the systems without flock, the lock file is only
+// kept open
+func lockDir(path string) (*os.File, error) {
+    return os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+}
+
+func unlockDir(f *os.File) {
+    f.Close()
+}
\ No newline at end of file
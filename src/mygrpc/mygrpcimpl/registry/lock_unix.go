@@ -0,0 +1,29 @@
+//go:build unix
+
+// Locking of the directory of a durable registry
+
+package registry
+
+import (
+    "os"
+    "syscall"
+)
+
+// take an exclusive lock on the file, which is released by the system if the
+// process dies so that a crash never leaves the directory locked
+func lockDir(path string) (*os.File, error) {
+    f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+    if err != nil {
+        return nil, err
+    }
+    if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+        f.Close()
+        return nil, err
+    }
+    return f, nil
+}
+
+func unlockDir(f *os.File) {
+    syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+    f.Close()
+}
\ No newline at end of file
@@ -0,0 +1,176 @@
+// In-memory implementation of the service registry
+
+package registry
+
+import (
+    "sort"
+    "sync"
+
+    pb "mygrpc/mygrpc"
+
+    "github.com/golang/protobuf/proto"
+)
+
+// the map of service descriptors is never modified once published, writers
+// replace it as a whole so that readers can hold it as a consistent snapshot
+type memRegistry struct {
+    mu       sync.RWMutex   // guards svcs, idx and chains, and orders the events published to hub
+    svcs     svcSnapshot   // map of service descriptors keyed by service name
+    idx      *svcIndex   // index of svcs for searching
+    chains   chainSnapshot   // map of stored chains keyed by chain id, replaced as a whole as svcs
+    hub      *eventHub   // dispatcher of the modifications to the watchers
+}
+
+func NewMemRegistry(sds []*pb.ServiceDescriptor) (*memRegistry, error) {
+    r := &memRegistry{svcs: make(svcSnapshot), idx: newSvcIndex(), chains: make(chainSnapshot), hub: newEventHub()}
+    if err := r.ReplaceServices(sds); err != nil {
+        return nil, err
+    }
+    return r, nil
+}
+
+func (r *memRegistry) current() svcSnapshot {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    return r.svcs
+}
+
+func (r *memRegistry) GetService(name string) (*pb.ServiceDescriptor, bool) {
+    return r.current().GetService(name)
+}
+
+func (r *memRegistry) ListServices() ([]*pb.ServiceDescriptor, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    return r.listLocked()
+}
+
+func (r *memRegistry) listLocked() ([]*pb.ServiceDescriptor, error) {
+    svcs := r.svcs
+    sds := make([]*pb.ServiceDescriptor, 0, len(svcs))
+    for _, sd := range svcs {
+        sds = append(sds, proto.Clone(sd).(*pb.ServiceDescriptor))
+    }
+    sort.Slice(sds, func(i, j int) bool { return sds[i].GetSvcName() < sds[j].GetSvcName() })
+    return sds, nil
+}
+
+func (r *memRegistry) PutService(sd *pb.ServiceDescriptor) error {
+    if err := checkDescriptor(sd); err != nil {
+        return err
+    }
+    sd = storedCopy(sd)
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    ev := Event{Type: pb.EventType_ADDED, Service: sd}
+    osd, prs := r.svcs[sd.GetSvcName()]
+    if prs {
+        if proto.Equal(osd, sd) {
+            return nil
+        }
+        ev.Type = pb.EventType_UPDATED
+    }
+    svcs := r.svcs.copy()
+    svcs[sd.GetSvcName()] = sd
+    r.svcs = svcs
+    r.idx.update(osd, sd)
+    r.hub.publish([]Event{ev})
+    return nil
+}
+
+func (r *memRegistry) DeleteService(name string) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    osd, prs := r.svcs[name]
+    if !prs {
+        return &RegistryError{SvcName: name, Msg: msgNotFound}
+    }
+    svcs := r.svcs.copy()
+    delete(svcs, name)
+    r.svcs = svcs
+    r.idx.update(osd, nil)
+    r.hub.publish([]Event{{Type: pb.EventType_DELETED, Service: osd}})
+    return nil
+}
+
+// atomically replace all the services in the registry. the registry is left
+// untouched if any of the given descriptors is invalid
+func (r *memRegistry) ReplaceServices(sds []*pb.ServiceDescriptor) error {
+    if err := CheckServices(sds); err != nil {
+        return err
+    }
+    svcs := make(svcSnapshot, len(sds))
+    for _, sd := range sds {
+        svcs[sd.GetSvcName()] = storedCopy(sd)
+    }
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    evs := diffSnapshots(r.svcs, svcs)
+    for _, ev := range evs {
+        if ev.Type == pb.EventType_DELETED {
+            r.idx.update(ev.Service, nil)
+        } else {
+            r.idx.update(r.svcs[ev.Service.GetSvcName()], ev.Service)
+        }
+    }
+    r.svcs = svcs
+    r.hub.publish(evs)
+    return nil
+}
+
+func (r *memRegistry) Snapshot() ServiceLookup {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    return &revSnapshot{svcSnapshot: r.svcs, chainSnapshot: r.chains, rev: r.hub.revision()}
+}
+
+func (r *memRegistry) Watch(since int64, initial bool) (*Watch, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    var sds []*pb.ServiceDescriptor
+    if initial {
+        sds, _ = r.listLocked()
+    }
+    return r.hub.watch(since, sds, r.svcs, r.chains)
+}
+
+// read-only view of the services in a memRegistry at some point in time
+type svcSnapshot map[string]*pb.ServiceDescriptor
+
+func (s svcSnapshot) GetService(name string) (*pb.ServiceDescriptor, bool) {
+    sd, prs := s[name]
+    if !prs {
+        return nil, false
+    }
+    return proto.Clone(sd).(*pb.ServiceDescriptor), true
+}
+
+// snapshot of the services and the chains of a memRegistry along with its revision
+type revSnapshot struct {
+    svcSnapshot
+    chainSnapshot
+    rev   int64
+}
+
+func (s *revSnapshot) Revision() int64 {
+    return s.rev
+}
+
+func (s svcSnapshot) Len() int {
+    return len(s)
+}
+
+func (s svcSnapshot) copy() svcSnapshot {
+    c := make(svcSnapshot, len(s) + 1)
+    for k, v := range s {
+        c[k] = v
+    }
+    return c
+}
+
+// return a copy of a descriptor suitable to be kept in a registry
+func storedCopy(sd *pb.ServiceDescriptor) *pb.ServiceDescriptor {
+    sd = proto.Clone(sd).(*pb.ServiceDescriptor)
+    sd.SvcPos = 0  // position is only meaningful within a chain
+    return sd
+}
\ No newline at end of file
@@ -0,0 +1,121 @@
+// Registry of service descriptors used by the server of mygrpc
+
+package registry
+
+import (
+    "fmt"
+
+    pb "mygrpc/mygrpc"
+    val "mygrpc/util/validate"
+)
+
+// read-only access to service descriptors by name
+type ServiceLookup interface {
+    // return the descriptor of the service with the given name, and whether it is found
+    GetService(name string) (*pb.ServiceDescriptor, bool)
+}
+
+// interface of the backends storing the service descriptors served by mygrpc.
+// descriptors returned by a registry are copies owned by the caller
+type ServiceRegistry interface {
+    ServiceLookup
+
+    // return descriptors of all the services in the registry ordered by name
+    ListServices() ([]*pb.ServiceDescriptor, error)
+
+    // add a service descriptor into the registry, or replace the one with the same name
+    PutService(sd *pb.ServiceDescriptor) error
+
+    // remove the service with the given name from the registry
+    DeleteService(name string) error
+}
+
+// optional interface of the registries able to provide a consistent view of their
+// services which is not affected by later modifications
+type Snapshotter interface {
+    Snapshot() ServiceLookup
+}
+
+// return a consistent view of a registry if supported, else the registry itself
+func SnapshotOf(r ServiceRegistry) ServiceLookup {
+    if s, ok := r.(Snapshotter); ok {
+        return s.Snapshot()
+    }
+    return r
+}
+
+// optional interface of the views of a registry knowing its revision, which
+// changes whenever a service is modified. views of the same revision hold the
+// same services
+type Revisioned interface {
+    Revision() int64
+}
+
+// optional interface of the views of a registry knowing their number of services
+// without listing them
+type Sized interface {
+    Len() int
+}
+
+// optional interface of the registries loaded from an external source which can be
+// read again. the registry keeps its content when reloading fails
+type Reloader interface {
+    Reload() error
+}
+
+// error type used to raise exceptions when operating on a registry
+type RegistryError struct {
+    SvcName   string
+    ChainId   int32   // stored chain causing the error, 0 if the error is not about one
+    Msg       string
+    Err       error
+}
+
+func (e *RegistryError) Error() string {
+    msg := e.Msg
+    if e.Err != nil {
+        msg = fmt.Sprintf("%s: %s", e.Msg, e.Err.Error())
+    }
+    if e.ChainId != 0 {
+        return fmt.Sprintf("Registry error for service chain %d: %s", e.ChainId, msg)
+    }
+    if e.SvcName == "" {
+        return fmt.Sprintf("Registry error: %s", msg)
+    }
+
+    return fmt.Sprintf("Registry error for service %s: %s", e.SvcName, msg)
+}
+
+// report whether an error raised by a registry is caused by a missing service
+func IsNotFound(err error) bool {
+    re, ok := err.(*RegistryError)
+    return ok && re.Msg == msgNotFound
+}
+
+const msgNotFound = "No service found"
+
+// validate a service descriptor before storing it into a registry
+func checkDescriptor(sd *pb.ServiceDescriptor) error {
+    if sd == nil {
+        return &RegistryError{Msg: "Nil service descriptor"}
+    }
+    if err := val.ValServiceDescriptor(sd); err != nil {
+        return &RegistryError{SvcName: sd.GetSvcName(), Msg: "Invalid service descriptor", Err: err}
+    }
+    return nil
+}
+
+// validate a set of service descriptors meant to replace the content of a registry
+func CheckServices(sds []*pb.ServiceDescriptor) error {
+    names := make(map[string]bool, len(sds))
+    for _, sd := range sds {
+        if err := checkDescriptor(sd); err != nil {
+            return err
+        }
+        if names[sd.GetSvcName()] {
+            return &RegistryError{SvcName: sd.GetSvcName(), Msg: "Duplicated service"}
+        }
+        names[sd.GetSvcName()] = true
+    }
+    return nil
+}
\ No newline at end of file
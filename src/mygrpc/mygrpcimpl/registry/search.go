@@ -0,0 +1,253 @@
+// Searching of the services of a registry by labels, name and description
+
+package registry
+
+import (
+    "path"
+    "sort"
+    "strings"
+    "unicode"
+
+    pb "mygrpc/mygrpc"
+)
+
+// a search of services, whose results are ordered by service name
+type Query struct {
+    Selector   Selector   // label selector, all services if empty
+    Name       string   // prefix of the service names, or a glob pattern if it holds any of *?[
+    Text       string   // words all found in the descriptions, case-insensitively
+    After      string   // name of the last service of the previous page, empty for the first page
+    Limit      int   // maximal number of services returned, all if not positive
+}
+
+// optional interface of the registries indexing their services for searching
+type Searcher interface {
+    // return the services matching the query after q.After, and the number of all the
+    // services matching the query
+    Search(q *Query) ([]*pb.ServiceDescriptor, int, error)
+}
+
+// search the services of a registry, with its index if supported, else by listing
+// all the services
+func Search(r ServiceRegistry, q *Query) ([]*pb.ServiceDescriptor, int, error) {
+    if s, ok := r.(Searcher); ok {
+        return s.Search(q)
+    }
+    sds, err := r.ListServices()
+    if err != nil {
+        return nil, 0, err
+    }
+    words := tokenize(q.Text)
+    var matched []*pb.ServiceDescriptor
+    for _, sd := range sds {
+        if q.matchesName(sd.GetSvcName()) && q.Selector.Matches(sd.GetLabels()) && containsWords(sd.GetSvcDesc(), words) {
+            matched = append(matched, sd)
+        }
+    }
+    start := sort.Search(len(matched), func(i int) bool { return matched[i].GetSvcName() > q.After })
+    end := len(matched)
+    if q.Limit > 0 && start + q.Limit < end {
+        end = start + q.Limit
+    }
+    return matched[start:end], len(matched), nil
+}
+
+// report whether the name is a glob pattern instead of a prefix
+func isGlob(name string) bool {
+    return strings.ContainsAny(name, "*?[")
+}
+
+func (q *Query) matchesName(name string) bool {
+    if isGlob(q.Name) {
+        ok, _ := path.Match(q.Name, name)
+        return ok
+    }
+    return strings.HasPrefix(name, q.Name)
+}
+
+// split a text into lower case words of letters and digits
+func tokenize(text string) []string {
+    return strings.FieldsFunc(strings.ToLower(text), func(c rune) bool {
+        return !unicode.IsLetter(c) && !unicode.IsDigit(c)
+    })
+}
+
+func containsWords(text string, words []string) bool {
+    if len(words) == 0 {
+        return true
+    }
+    found := make(map[string]bool)
+    for _, w := range tokenize(text) {
+        found[w] = true
+    }
+    for _, w := range words {
+        if !found[w] {
+            return false
+        }
+    }
+    return true
+}
+
+type nameSet map[string]struct{}
+
+// index of the services of a memRegistry, maintained along with its services
+type svcIndex struct {
+    names    []string   // names of all the services, sorted
+    labels   map[string]map[string]nameSet   // services by label key and value
+    words    map[string]nameSet   // services by word of their description
+}
+
+func newSvcIndex() *svcIndex {
+    return &svcIndex{labels: make(map[string]map[string]nameSet), words: make(map[string]nameSet)}
+}
+
+// replace the descriptor old of a service by cur in the index, old is nil for a
+// service added and cur is nil for a service removed
+func (x *svcIndex) update(old, cur *pb.ServiceDescriptor) {
+    if old != nil {
+        name := old.GetSvcName()
+        for k, v := range old.GetLabels() {
+            delete(x.labels[k][v], name)
+            if len(x.labels[k][v]) == 0 {
+                delete(x.labels[k], v)
+            }
+            if len(x.labels[k]) == 0 {
+                delete(x.labels, k)
+            }
+        }
+        for _, w := range tokenize(old.GetSvcDesc()) {
+            delete(x.words[w], name)
+            if len(x.words[w]) == 0 {
+                delete(x.words, w)
+            }
+        }
+        if cur == nil {
+            i := sort.SearchStrings(x.names, name)
+            x.names = append(x.names[:i], x.names[i + 1:]...)
+        }
+    }
+    if cur != nil {
+        name := cur.GetSvcName()
+        for k, v := range cur.GetLabels() {
+            if x.labels[k] == nil {
+                x.labels[k] = make(map[string]nameSet)
+            }
+            if x.labels[k][v] == nil {
+                x.labels[k][v] = make(nameSet)
+            }
+            x.labels[k][v][name] = struct{}{}
+        }
+        for _, w := range tokenize(cur.GetSvcDesc()) {
+            if x.words[w] == nil {
+                x.words[w] = make(nameSet)
+            }
+            x.words[w][name] = struct{}{}
+        }
+        if old == nil {
+            i := sort.SearchStrings(x.names, name)
+            x.names = append(x.names, "")
+            copy(x.names[i + 1:], x.names[i:])
+            x.names[i] = name
+        }
+    }
+}
+
+// return the names of the services possibly matching the query, a narrow superset
+// taken from the index, sorted if sorted is true
+func (x *svcIndex) candidates(q *Query) (names []string, sorted bool) {
+    // the services in the range of the name prefix, or of the literal prefix of a glob
+    prefix := q.Name
+    if i := strings.IndexAny(prefix, "*?[\\"); i >= 0 {
+        prefix = prefix[:i]
+    }
+    start := sort.SearchStrings(x.names, prefix)
+    end := start + sort.Search(len(x.names) - start, func(i int) bool { return !strings.HasPrefix(x.names[start + i], prefix) })
+    best := x.names[start:end]
+
+    var sets []nameSet
+    for _, req := range q.Selector {
+        switch req.Op {
+            case OpEquals, OpIn:
+                var set nameSet
+                if len(req.Values) == 1 {
+                    set = x.labels[req.Key][req.Values[0]]
+                } else {
+                    set = make(nameSet)
+                    for _, v := range req.Values {
+                        for name := range x.labels[req.Key][v] {
+                            set[name] = struct{}{}
+                        }
+                    }
+                }
+                sets = append(sets, set)
+            case OpExists:
+                set := make(nameSet)
+                for _, names := range x.labels[req.Key] {
+                    for name := range names {
+                        set[name] = struct{}{}
+                    }
+                }
+                sets = append(sets, set)
+        }
+    }
+    for _, w := range tokenize(q.Text) {
+        sets = append(sets, x.words[w])
+    }
+    var smallest nameSet
+    for _, set := range sets {
+        if smallest == nil || len(set) < len(smallest) {
+            smallest = set
+        }
+    }
+    if sets == nil || len(smallest) >= len(best) {
+        return best, true
+    }
+    names = make([]string, 0, len(smallest))
+    for name := range smallest {
+        names = append(names, name)
+    }
+    return names, false
+}
+
+// report whether a service is in the index of the words of the descriptions for
+// every word of the query
+func (x *svcIndex) hasWords(name string, words []string) bool {
+    for _, w := range words {
+        if _, prs := x.words[w][name]; !prs {
+            return false
+        }
+    }
+    return true
+}
+
+// search the services with the index, the registry being locked for reading
+func (r *memRegistry) Search(q *Query) ([]*pb.ServiceDescriptor, int, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    names, sorted := r.idx.candidates(q)
+    words := tokenize(q.Text)
+    var matched []string
+    for _, name := range names {
+        sd := r.svcs[name]
+        if q.matchesName(name) && q.Selector.Matches(sd.GetLabels()) && r.idx.hasWords(name, words) {
+            matched = append(matched, name)
+        }
+    }
+    if !sorted {
+        sort.Strings(matched)
+    }
+    start := sort.SearchStrings(matched, q.After)
+    if start < len(matched) && matched[start] == q.After {
+        start++
+    }
+    end := len(matched)
+    if q.Limit > 0 && start + q.Limit < end {
+        end = start + q.Limit
+    }
+    sds := make([]*pb.ServiceDescriptor, 0, end - start)
+    for _, name := range matched[start:end] {
+        sd, _ := r.svcs.GetService(name)
+        sds = append(sds, sd)
+    }
+    return sds, len(matched), nil
+}
\ No newline at end of file
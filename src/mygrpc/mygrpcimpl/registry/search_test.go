@@ -0,0 +1,146 @@
+package registry
+
+import (
+    "fmt"
+    "math/rand"
+    "reflect"
+    "testing"
+
+    pb "mygrpc/mygrpc"
+)
+
+func TestParseSelector(t *testing.T) {
+    sel, err := ParseSelector("tier=backend, env==prod,team!=edge,zone in (b, a),region notin (eu),canary,!deprecated")
+    if err != nil {
+        t.Fatal(err)
+    }
+    want := Selector{
+                {Key: "tier", Op: OpEquals, Values: []string{"backend"}},
+                {Key: "env", Op: OpEquals, Values: []string{"prod"}},
+                {Key: "team", Op: OpNotEquals, Values: []string{"edge"}},
+                {Key: "zone", Op: OpIn, Values: []string{"a", "b"}},
+                {Key: "region", Op: OpNotIn, Values: []string{"eu"}},
+                {Key: "canary", Op: OpExists},
+                {Key: "deprecated", Op: OpNotExists},
+            }
+    if !reflect.DeepEqual(sel, want) {
+        t.Errorf("selector = %v, want %v", sel, want)
+    }
+    labels := map[string]string{"tier": "backend", "env": "prod", "zone": "a", "canary": ""}
+    if !sel.Matches(labels) {
+        t.Errorf("%v not selected", labels)
+    }
+    labels["deprecated"] = "true"
+    if sel.Matches(labels) {
+        t.Errorf("%v selected", labels)
+    }
+
+    if sel, err := ParseSelector(" "); err != nil || len(sel) != 0 {
+        t.Errorf("empty selector = %v, %v", sel, err)
+    }
+    if sel, err := ParseSelector("tier="); err != nil || !sel.Matches(map[string]string{"tier": ""}) {
+        t.Errorf("selector of an empty value = %v, %v", sel, err)
+    }
+    for _, s := range []string{"tier=a,", "tier in ()", "-tier", "tier=a=b", "tier in (a,b"} {
+        if _, err := ParseSelector(s); err == nil {
+            t.Errorf("selector %q is accepted", s)
+        }
+    }
+}
+
+// return a registry of n services with random labels and descriptions
+func randomRegistry(t testing.TB, n int) *memRegistry {
+    rnd := rand.New(rand.NewSource(1))
+    words := []string{"payments", "billing", "search", "auth", "cache", "Payments"}
+    sds := make([]*pb.ServiceDescriptor, n)
+    for i := range sds {
+        sds[i] = &pb.ServiceDescriptor{
+                     SvcName:  fmt.Sprintf("svc%05d", i),
+                     SvcDesc:  fmt.Sprintf("Handles %s and %s.", words[rnd.Intn(len(words))], words[rnd.Intn(len(words))]),
+                     Labels:   map[string]string{"tier": []string{"frontend", "backend", "db"}[rnd.Intn(3)]},
+                 }
+        if rnd.Intn(4) == 0 {
+            sds[i].Labels["canary"] = "true"
+        }
+    }
+    r, err := NewMemRegistry(sds)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return r
+}
+
+// registry hiding the index of a memRegistry, searched by listing its services
+type unindexed struct {
+    ServiceRegistry
+}
+
+func names(sds []*pb.ServiceDescriptor) []string {
+    ns := make([]string, len(sds))
+    for i, sd := range sds {
+        ns[i] = sd.GetSvcName()
+    }
+    return ns
+}
+
+func TestSearch(t *testing.T) {
+    r := randomRegistry(t, 500)
+    mustDo(t, r.PutService(&pb.ServiceDescriptor{SvcName: "svc00001", SvcDesc: "moved to payments", Labels: map[string]string{"tier": "db"}}),
+              r.DeleteService("svc00002"))
+    queries := []Query{
+                   {},
+                   {Name: "svc001"},
+                   {Name: "svc*7"},
+                   {Text: "payments"},
+                   {Text: "PAYMENTS billing"},
+                   {Text: "nothing"},
+                   {Text: "payments", Selector: Selector{{Key: "tier", Op: OpEquals, Values: []string{"db"}}}},
+                   {Selector: Selector{{Key: "tier", Op: OpIn, Values: []string{"backend", "db"}}, {Key: "canary", Op: OpNotExists}}},
+                   {Selector: Selector{{Key: "canary", Op: OpExists}}, Name: "svc00*"},
+                   {Selector: Selector{{Key: "tier", Op: OpNotEquals, Values: []string{"db"}}}, After: "svc00250", Limit: 10},
+               }
+    for _, q := range queries {
+        q := q
+        got, total, err := r.Search(&q)
+        if err != nil {
+            t.Fatal(err)
+        }
+        want, wantTotal, _ := Search(unindexed{r}, &q)
+        if !reflect.DeepEqual(names(got), names(want)) || total != wantTotal {
+            t.Errorf("search %+v = %v of %d, want %v of %d", q, names(got), total, names(want), wantTotal)
+        }
+    }
+
+    // pages of a search cover all the matching services once
+    q := &Query{Text: "payments", Limit: 7}
+    var all []string
+    for {
+        sds, total, _ := r.Search(q)
+        all = append(all, names(sds)...)
+        if len(sds) < q.Limit {
+            if len(all) != total {
+                t.Errorf("%d services in the pages, want %d", len(all), total)
+            }
+            break
+        }
+        q.After = sds[len(sds) - 1].GetSvcName()
+    }
+}
+
+func BenchmarkSearch(b *testing.B) {
+    r := randomRegistry(b, 20000)
+    sel, _ := ParseSelector("tier=backend,!canary")
+    for _, bc := range []struct {
+        name   string
+        r      ServiceRegistry
+    }{
+        {"index", r},
+        {"scan", unindexed{r}},
+    } {
+        b.Run(bc.name, func(b *testing.B) {
+            for i := 0; i < b.N; i++ {
+                Search(bc.r, &Query{Selector: sel, Text: "billing", Limit: 50})
+            }
+        })
+    }
+}
\ No newline at end of file
@@ -0,0 +1,149 @@
+// Kubernetes-style label selectors over the services of a registry
+
+package registry
+
+import (
+    "fmt"
+    "regexp"
+    "sort"
+    "strings"
+
+    val "mygrpc/util/validate"
+)
+
+// operators of the requirements of a label selector
+const (
+    OpEquals      = "="
+    OpNotEquals   = "!="
+    OpIn          = "in"
+    OpNotIn       = "notin"
+    OpExists      = "exists"
+    OpNotExists   = "!"
+)
+
+// a condition on one label of a service
+type Requirement struct {
+    Key      string
+    Op       string
+    Values   []string   // values of the =, !=, in and notin operators, sorted
+}
+
+// a label selector, matching the services meeting all its requirements
+type Selector []Requirement
+
+var setRe = regexp.MustCompile(`^(\S+)\s+(in|notin)\s*\((.*)\)$`)
+
+// parse a label selector of requirements separated by ',', each of which is one of
+// key=value (or key==value), key!=value, key in (v1,v2), key notin (v1,v2), key
+// (the label exists) and !key (the label does not exist). the empty selector
+// matches all the services
+func ParseSelector(s string) (Selector, error) {
+    var sel Selector
+    for _, part := range splitRequirements(s) {
+        part = strings.TrimSpace(part)
+        if part == "" {
+            if strings.TrimSpace(s) == "" {
+                break
+            }
+            return nil, selectorError(s, "Empty requirement")
+        }
+        req, err := parseRequirement(part)
+        if err != nil {
+            return nil, selectorError(s, err.Error())
+        }
+        sel = append(sel, req)
+    }
+    return sel, nil
+}
+
+func selectorError(s, msg string) error {
+    return &RegistryError{Msg: fmt.Sprintf("Invalid label selector %q", s), Err: fmt.Errorf("%s", msg)}
+}
+
+// split a selector on the commas outside of the value sets
+func splitRequirements(s string) []string {
+    var parts []string
+    depth, start := 0, 0
+    for i, c := range s {
+        switch c {
+            case '(':
+                depth++
+            case ')':
+                depth--
+            case ',':
+                if depth == 0 {
+                    parts = append(parts, s[start:i])
+                    start = i + 1
+                }
+        }
+    }
+    return append(parts, s[start:])
+}
+
+func parseRequirement(part string) (Requirement, error) {
+    var req Requirement
+    switch {
+        case setRe.MatchString(part):
+            m := setRe.FindStringSubmatch(part)
+            req = Requirement{Key: m[1], Op: m[2]}
+            for _, v := range strings.Split(m[3], ",") {
+                if v = strings.TrimSpace(v); v != "" {
+                    req.Values = append(req.Values, v)
+                }
+            }
+            if len(req.Values) == 0 {
+                return req, fmt.Errorf("Requirement %q has no value", part)
+            }
+        case strings.Contains(part, "!="):
+            kv := strings.SplitN(part, "!=", 2)
+            req = Requirement{Key: strings.TrimSpace(kv[0]), Op: OpNotEquals, Values: []string{strings.TrimSpace(kv[1])}}
+        case strings.Contains(part, "="):
+            kv := strings.SplitN(part, "=", 2)
+            req = Requirement{Key: strings.TrimSpace(kv[0]), Op: OpEquals, Values: []string{strings.TrimSpace(strings.TrimPrefix(kv[1], "="))}}
+        case strings.HasPrefix(part, "!"):
+            req = Requirement{Key: strings.TrimSpace(part[1:]), Op: OpNotExists}
+        default:
+            req = Requirement{Key: part, Op: OpExists}
+    }
+    if err := val.ValLabelKey(req.Key); err != nil {
+        return req, fmt.Errorf("Requirement %q has malformed key %q", part, req.Key)
+    }
+    for _, v := range req.Values {
+        if strings.ContainsAny(v, "()=! \t") {
+            return req, fmt.Errorf("Requirement %q has malformed value %q", part, v)
+        }
+    }
+    sort.Strings(req.Values)
+    return req, nil
+}
+
+// report whether the labels meet the requirement
+func (req Requirement) Matches(labels map[string]string) bool {
+    v, prs := labels[req.Key]
+    switch req.Op {
+        case OpExists:
+            return prs
+        case OpNotExists:
+            return !prs
+        case OpEquals, OpIn:
+            return prs && req.has(v)
+        default:
+            // a service without the label is selected by != and notin, as in kubernetes
+            return !prs || !req.has(v)
+    }
+}
+
+func (req Requirement) has(v string) bool {
+    i := sort.SearchStrings(req.Values, v)
+    return i < len(req.Values) && req.Values[i] == v
+}
+
+// report whether the labels meet all the requirements
+func (sel Selector) Matches(labels map[string]string) bool {
+    for _, req := range sel {
+        if !req.Matches(labels) {
+            return false
+        }
+    }
+    return true
+}
\ No newline at end of file
@@ -0,0 +1,215 @@
+// Watching of the modifications of a service registry
+
+package registry
+
+import (
+    "sort"
+    "sync"
+
+    pb "mygrpc/mygrpc"
+
+    "github.com/golang/protobuf/proto"
+)
+
+const (
+    historySize   = 1024   // number of past events kept for resuming watches
+    watchBuffer   = 256   // number of pending events a watcher may lag behind
+)
+
+// optional interface of the registries reporting their modifications as events
+type Watchable interface {
+    // start watching the modifications of the services and of the stored chains made
+    // after revision since, or after the current revision if since is negative. if
+    // initial is true and since is negative, the watch first receives an ADDED event
+    // for every service currently registered
+    Watch(since int64, initial bool) (*Watch, error)
+}
+
+// a modification of a service or of a stored chain in a registry. the descriptor and
+// the chain are shared by all the watchers and must not be modified
+type Event struct {
+    Type       pb.EventType
+    Service    *pb.ServiceDescriptor   // new descriptor of the service, or the last one when deleted, nil for a chain
+    Chain      *pb.StoredChain   // new stored chain, or the last one when deleted, nil for a service
+    Revision   int64   // revision of the registry after the modification
+}
+
+// a stream of events delivered to one watcher
+type Watch struct {
+    hub      *eventHub
+    rev      int64   // revision of the registry when the watch starts
+    state    *revSnapshot   // view of the registry at rev, nil if it is not known
+    events   chan Event
+    done     chan struct{}
+    err      error   // reason of the watch being ended by the registry, set before closing done
+}
+
+// channel receiving the events, in order of revision
+func (w *Watch) Events() <-chan Event {
+    return w.events
+}
+
+// revision of the registry when the watch starts, any later modification is
+// delivered by the watch
+func (w *Watch) Revision() int64 {
+    return w.rev
+}
+
+// view of the registry at the revision the watch starts from, or nil if it is not
+// known, as when resuming from a past revision
+func (w *Watch) Snapshot() ServiceLookup {
+    if w.state == nil {
+        return nil
+    }
+    return w.state
+}
+
+// channel closed when the watch is ended by the registry, see Err
+func (w *Watch) Done() <-chan struct{} {
+    return w.done
+}
+
+// reason of the watch being ended by the registry
+func (w *Watch) Err() error {
+    select {
+        case <-w.done:
+            return w.err
+        default:
+            return nil
+    }
+}
+
+// stop receiving events and release the watch
+func (w *Watch) Stop() {
+    w.hub.remove(w, nil)
+}
+
+// dispatcher of the events of one registry to its watchers
+type eventHub struct {
+    mu        sync.Mutex   // guards the fields below
+    rev       int64   // revision of the last event
+    history   []Event   // the last events, ordered by revision, without the views of the registry
+    watches   map[*Watch]struct{}
+}
+
+func newEventHub() *eventHub {
+    return &eventHub{watches: make(map[*Watch]struct{})}
+}
+
+// return the revision of the last event
+func (h *eventHub) revision() int64 {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    return h.rev
+}
+
+// record the events and deliver them to the watchers. watchers whose buffer is full
+// are ended so that a slow watcher never blocks the registry
+func (h *eventHub) publish(evs []Event) {
+    if len(evs) == 0 {
+        return
+    }
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    for i := range evs {
+        h.rev++
+        evs[i].Revision = h.rev
+    }
+    h.history = append(h.history, evs...)
+    if len(h.history) > historySize {
+        h.history = append([]Event(nil), h.history[len(h.history) - historySize:]...)
+    }
+    for w := range h.watches {
+        for _, ev := range evs {
+            select {
+                case w.events <- ev:
+                    continue
+                default:
+            }
+            h.removeLocked(w, &RegistryError{Msg: "Watcher is too slow, events are dropped"})
+            break
+        }
+    }
+}
+
+// start a watch of a registry currently holding the services svcs and the chains
+// chains, which are the view of the watch when it starts from the current revision.
+// initial events are delivered before any later modification
+func (h *eventHub) watch(since int64, initial []*pb.ServiceDescriptor, svcs svcSnapshot, chains chainSnapshot) (*Watch, error) {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    var replay []Event
+    rev := since
+    var state *revSnapshot
+    if since < 0 || since == h.rev {
+        rev = h.rev
+        state = &revSnapshot{svcSnapshot: svcs, chainSnapshot: chains, rev: h.rev}
+    }
+    if since < 0 {
+        for _, sd := range initial {
+            replay = append(replay, Event{Type: pb.EventType_ADDED, Service: sd, Revision: h.rev})
+        }
+    } else {
+        if since > h.rev {
+            return nil, &RegistryError{Msg: "Revision to resume from is in the future"}
+        }
+        if since < h.rev && (len(h.history) == 0 || h.history[0].Revision > since + 1) {
+            return nil, &RegistryError{Msg: "Revision to resume from is compacted"}
+        }
+        for _, ev := range h.history {
+            if ev.Revision > since {
+                replay = append(replay, ev)
+            }
+        }
+    }
+
+    w := &Watch{
+             hub:     h,
+             rev:     rev,
+             state:   state,
+             events:  make(chan Event, len(replay) + watchBuffer),
+             done:    make(chan struct{}),
+         }
+    for _, ev := range replay {
+        w.events <- ev
+    }
+    h.watches[w] = struct{}{}
+    return w, nil
+}
+
+func (h *eventHub) remove(w *Watch, err error) {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    h.removeLocked(w, err)
+}
+
+func (h *eventHub) removeLocked(w *Watch, err error) {
+    if _, prs := h.watches[w]; !prs {
+        return
+    }
+    delete(h.watches, w)
+    w.err = err
+    close(w.done)
+}
+
+// compute the events turning the services in old into the ones in cur, ordered by
+// service name
+func diffSnapshots(old, cur svcSnapshot) []Event {
+    var evs []Event
+    for name, sd := range cur {
+        osd, prs := old[name]
+        switch {
+            case !prs:
+                evs = append(evs, Event{Type: pb.EventType_ADDED, Service: sd})
+            case !proto.Equal(osd, sd):
+                evs = append(evs, Event{Type: pb.EventType_UPDATED, Service: sd})
+        }
+    }
+    for name, osd := range old {
+        if _, prs := cur[name]; !prs {
+            evs = append(evs, Event{Type: pb.EventType_DELETED, Service: osd})
+        }
+    }
+    sort.Slice(evs, func(i, j int) bool { return evs[i].Service.GetSvcName() < evs[j].Service.GetSvcName() })
+    return evs
+}
\ No newline at end of file
@@ -0,0 +1,203 @@
+package registry
+
+import (
+    "fmt"
+    "testing"
+
+    pb "mygrpc/mygrpc"
+)
+
+// return the next event of a watch, failing if none is pending
+func nextEvent(t *testing.T, w *Watch) Event {
+    t.Helper()
+    select {
+        case ev := <-w.Events():
+            return ev
+        default:
+            t.Fatal("no pending event")
+            return Event{}
+    }
+}
+
+func svcDesc(name, desc string) *pb.ServiceDescriptor {
+    return &pb.ServiceDescriptor{SvcName: name, SvcDesc: desc}
+}
+
+func TestWatch(t *testing.T) {
+    r, err := NewMemRegistry([]*pb.ServiceDescriptor{svcDesc("svcA", "a")})
+    if err != nil {
+        t.Fatal(err)
+    }
+    w, err := r.Watch(-1, true)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer w.Stop()
+    if ev := nextEvent(t, w); ev.Type != pb.EventType_ADDED || ev.Service.GetSvcName() != "svcA" || ev.Revision != w.Revision() {
+        t.Errorf("initial event = %+v at revision %d", ev, w.Revision())
+    }
+    start := w.Revision()
+
+    if err := r.PutService(svcDesc("svcA", "a2")); err != nil {
+        t.Fatal(err)
+    }
+    if err := r.ReplaceServices([]*pb.ServiceDescriptor{svcDesc("svcA", "a3"), svcDesc("svcB", "b")}); err != nil {
+        t.Fatal(err)
+    }
+    if err := r.DeleteService("svcB"); err != nil {
+        t.Fatal(err)
+    }
+    var got []string
+    for i := 0; i < 4; i++ {
+        ev := nextEvent(t, w)
+        if ev.Revision != start + int64(i + 1) {
+            t.Errorf("event %d at revision %d, want %d", i, ev.Revision, start + int64(i + 1))
+        }
+        got = append(got, fmt.Sprintf("%v %s %s", ev.Type, ev.Service.GetSvcName(), ev.Service.GetSvcDesc()))
+    }
+    want := "[UPDATED svcA a2 UPDATED svcA a3 ADDED svcB b DELETED svcB b]"
+    if fmt.Sprint(got) != want {
+        t.Errorf("events = %v, want %v", got, want)
+    }
+
+    // a watch resumed from a past revision replays the later events, its view is not known
+    rw, err := r.Watch(start + 1, false)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer rw.Stop()
+    if rw.Snapshot() != nil {
+        t.Errorf("view of the resumed watch = %v, want none", rw.Snapshot())
+    }
+    for i := 2; i <= 4; i++ {
+        if ev := nextEvent(t, rw); ev.Revision != start + int64(i) {
+            t.Errorf("replayed event at revision %d, want %d", ev.Revision, start + int64(i))
+        }
+    }
+    // the view of a watch resumed from the current revision is the current one
+    if cw, err := r.Watch(start + 4, false); err != nil {
+        t.Errorf("watch resumed from the current revision: %v", err)
+    } else {
+        if sd, _ := cw.Snapshot().GetService("svcA"); sd.GetSvcDesc() != "a3" {
+            t.Errorf("view of the watch resumed from the current revision has svcA %v", sd)
+        }
+        cw.Stop()
+    }
+    if _, err := r.Watch(start + 5, false); err == nil {
+        t.Errorf("watch resumed from a future revision is started")
+    }
+}
+
+func TestWatchCompacted(t *testing.T) {
+    r, err := NewMemRegistry(nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    for i := 0; i <= historySize; i++ {
+        if err := r.PutService(svcDesc("svcA", fmt.Sprint(i))); err != nil {
+            t.Fatal(err)
+        }
+    }
+    if _, err := r.Watch(0, false); err == nil {
+        t.Errorf("watch resumed from a compacted revision is started")
+    }
+    w, err := r.Watch(1, false)
+    if err != nil {
+        t.Fatalf("watch resumed from the oldest kept revision: %v", err)
+    }
+    defer w.Stop()
+    if len(w.Events()) != historySize {
+        t.Errorf("%d events replayed, want %d", len(w.Events()), historySize)
+    }
+}
+
+func TestSlowWatcher(t *testing.T) {
+    r, err := NewMemRegistry(nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    slow, err := r.Watch(-1, false)
+    if err != nil {
+        t.Fatal(err)
+    }
+    fast, err := r.Watch(-1, false)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer fast.Stop()
+    for i := 0; i <= watchBuffer; i++ {
+        if err := r.PutService(svcDesc("svcA", fmt.Sprint(i))); err != nil {
+            t.Fatal(err)
+        }
+        nextEvent(t, fast)
+    }
+    select {
+        case <-slow.Done():
+            if slow.Err() == nil {
+                t.Errorf("slow watcher is ended without a reason")
+            }
+        default:
+            t.Fatal("slow watcher is not ended")
+    }
+    if len(slow.Events()) != watchBuffer {
+        t.Errorf("slow watcher got %d events, want the %d before it is ended", len(slow.Events()), watchBuffer)
+    }
+    select {
+        case <-fast.Done():
+            t.Errorf("watcher keeping up is ended: %v", fast.Err())
+        default:
+    }
+
+    // a watch stopped by its owner ends without a reason
+    fast.Stop()
+    if fast.Err() != nil {
+        t.Errorf("stopped watch ended with %v", fast.Err())
+    }
+}
+
+func TestWatchChains(t *testing.T) {
+    r, err := NewMemRegistry(nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    w, err := r.Watch(-1, true)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer w.Stop()
+    start := w.Revision()
+
+    c1 := &pb.StoredChain{ChainId: 1, Chain: []*pb.Service{{SvcName: "svcA", SvcPos: 1}}}
+    c2 := &pb.StoredChain{ChainId: 2, Chain: []*pb.Service{{ChainRef: 1, SvcPos: 1}}}
+    if err := r.PutChain(c1); err != nil {
+        t.Fatal(err)
+    }
+    // putting the same chain again is no modification
+    if err := r.PutChain(c1); err != nil {
+        t.Fatal(err)
+    }
+    if err := r.ReplaceChains([]*pb.StoredChain{c1, c2}); err != nil {
+        t.Fatal(err)
+    }
+    // a chain referenced by another one is kept
+    if err := r.DeleteChain(1); !IsChainReferenced(err) {
+        t.Errorf("deletion of a referenced chain = %v", err)
+    }
+    if err := r.DeleteChain(2); err != nil {
+        t.Fatal(err)
+    }
+    var got []string
+    for i := 0; i < 3; i++ {
+        ev := nextEvent(t, w)
+        if ev.Revision != start + int64(i + 1) || ev.Service != nil {
+            t.Errorf("event %d = %+v, want a chain at revision %d", i, ev, start + int64(i + 1))
+        }
+        got = append(got, fmt.Sprintf("%v %d", ev.Type, ev.Chain.GetChainId()))
+    }
+    if want := "[ADDED 1 ADDED 2 DELETED 2]"; fmt.Sprint(got) != want {
+        t.Errorf("events = %v, want %v", got, want)
+    }
+    if len(w.Events()) != 0 {
+        t.Errorf("%d unexpected events", len(w.Events()))
+    }
+}
\ No newline at end of file
@@ -0,0 +1,169 @@
+// Implementations of the admin service of mygrpc
+
+package server
+
+import (
+    "encoding/base64"
+    "sort"
+    "sync"
+
+    pb "mygrpc/mygrpc"
+    reg "mygrpc/mygrpcimpl/registry"
+
+    "github.com/golang/protobuf/proto"
+    "github.com/golang/protobuf/ptypes"
+    "golang.org/x/net/context"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+)
+
+const (
+    defaultPageSize = 50   // number of items per page when not given by the request
+    maxPageSize     = 500   // maximal number of items per page
+)
+
+type myGrpcAdminServer struct {
+    registry   reg.ServiceRegistry   // registry managed by the admin service
+    mu         sync.Mutex   // serializes the modifications so that existence checks hold
+}
+
+func NewMyGrpcAdminServer(registry reg.ServiceRegistry) *myGrpcAdminServer {
+    return &myGrpcAdminServer{registry: registry}
+}
+
+// convert an error raised by the registry into a grpc status
+func registryStatus(err error) error {
+    if reg.IsNotFound(err) || reg.IsChainNotFound(err) {
+        return status.Error(codes.NotFound, err.Error())
+    }
+    if reg.IsChainReferenced(err) {
+        return status.Error(codes.FailedPrecondition, err.Error())
+    }
+    if _, ok := err.(*reg.RegistryError); ok {
+        return status.Error(codes.InvalidArgument, err.Error())
+    }
+    return status.Error(codes.Internal, err.Error())
+}
+
+func (s *myGrpcAdminServer) RegisterService(ctx context.Context, sd *pb.ServiceDescriptor) (*pb.ServiceDescriptor, error) {
+    myGrpcLogger.Info("Received service registration", "svc_name", sd.GetSvcName())
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if _, prs := s.registry.GetService(sd.GetSvcName()); prs {
+        return nil, status.Errorf(codes.AlreadyExists, "Service %s is already registered", sd.GetSvcName())
+    }
+    sd = proto.Clone(sd).(*pb.ServiceDescriptor)
+    sd.CreatedAt = ptypes.TimestampNow()
+    sd.UpdatedAt = sd.CreatedAt
+    if err := s.registry.PutService(sd); err != nil {
+        return nil, registryStatus(err)
+    }
+    return s.get(sd.GetSvcName())
+}
+
+func (s *myGrpcAdminServer) UpdateService(ctx context.Context, sd *pb.ServiceDescriptor) (*pb.ServiceDescriptor, error) {
+    myGrpcLogger.Info("Received service update", "svc_name", sd.GetSvcName())
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    osd, prs := s.registry.GetService(sd.GetSvcName())
+    if !prs {
+        return nil, status.Errorf(codes.NotFound, "Service %s is not registered", sd.GetSvcName())
+    }
+    // the time of the registration is kept
+    sd = proto.Clone(sd).(*pb.ServiceDescriptor)
+    sd.CreatedAt = osd.GetCreatedAt()
+    sd.UpdatedAt = ptypes.TimestampNow()
+    if err := s.registry.PutService(sd); err != nil {
+        return nil, registryStatus(err)
+    }
+    return s.get(sd.GetSvcName())
+}
+
+func (s *myGrpcAdminServer) DeregisterService(ctx context.Context, sr *pb.ServiceRequest) (*pb.ServiceDescriptor, error) {
+    myGrpcLogger.Info("Received service deregistration", "svc_name", sr.GetSvcName())
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    sd, err := s.get(sr.GetSvcName())
+    if err != nil {
+        return nil, err
+    }
+    if err := s.registry.DeleteService(sr.GetSvcName()); err != nil {
+        return nil, registryStatus(err)
+    }
+    return sd, nil
+}
+
+func (s *myGrpcAdminServer) GetService(ctx context.Context, sr *pb.ServiceRequest) (*pb.ServiceDescriptor, error) {
+    return s.get(sr.GetSvcName())
+}
+
+func (s *myGrpcAdminServer) get(name string) (*pb.ServiceDescriptor, error) {
+    sd, prs := s.registry.GetService(name)
+    if !prs {
+        return nil, status.Errorf(codes.NotFound, "Service %s is not registered", name)
+    }
+    return sd, nil
+}
+
+// the page token is the encoded name of the last service in the previous page
+func (s *myGrpcAdminServer) ListServices(ctx context.Context, req *pb.ListServicesRequest) (*pb.ListServicesResponse, error) {
+    sds, err := s.registry.ListServices()
+    if err != nil {
+        return nil, registryStatus(err)
+    }
+    seek := func(last string) (int, error) {
+        return sort.Search(len(sds), func(i int) bool { return sds[i].GetSvcName() > last }), nil
+    }
+    start, end, next, err := listPage(len(sds), req.GetPageSize(), req.GetPageToken(), seek, func(i int) string { return sds[i].GetSvcName() })
+    if err != nil {
+        return nil, err
+    }
+    return &pb.ListServicesResponse{
+               Services:       sds[start:end],
+               NextPageToken:  next,
+               TotalSize:      int32(len(sds)),
+           }, nil
+}
+
+// return the bounds of the page of a list of n items ordered by key, and the token of
+// the next page, empty for the last page. the token is the encoded key of the last
+// item of the previous page, so that paging stays stable while items are added or
+// removed. seek returns the index of the first item after a key, or an error if the
+// key is malformed, and key the key of an item
+func listPage(n int, size int32, token string, seek func(last string) (int, error), key func(i int) string) (int, int, string, error) {
+    start := 0
+    if token != "" {
+        last, err := decodePageToken(token)
+        if err == nil {
+            start, err = seek(last)
+        }
+        if err != nil {
+            return 0, 0, "", status.Errorf(codes.InvalidArgument, "Invalid page token %q", token)
+        }
+    }
+    ps := int(size)
+    if ps <= 0 {
+        ps = defaultPageSize
+    }
+    if ps > maxPageSize {
+        ps = maxPageSize
+    }
+    end := start + ps
+    if end > n {
+        end = n
+    }
+    next := ""
+    if end < n {
+        next = encodePageToken(key(end - 1))
+    }
+    return start, end, next, nil
+}
+
+func encodePageToken(last string) string {
+    return base64.RawURLEncoding.EncodeToString([]byte(last))
+}
+
+func decodePageToken(token string) (string, error) {
+    last, err := base64.RawURLEncoding.DecodeString(token)
+    return string(last), err
+}
\ No newline at end of file
@@ -0,0 +1,114 @@
+package server
+
+import (
+    "reflect"
+    "testing"
+
+    pb "mygrpc/mygrpc"
+
+    "github.com/golang/protobuf/proto"
+    "github.com/golang/protobuf/ptypes"
+    "golang.org/x/net/context"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+)
+
+func TestServiceAdmin(t *testing.T) {
+    s := NewMyGrpcAdminServer(newRegistry(t))
+    ctx := context.Background()
+    registered, err := s.RegisterService(ctx, &pb.ServiceDescriptor{SvcName: "svcD", SvcDesc: "d"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if registered.GetCreatedAt() == nil || !proto.Equal(registered.GetCreatedAt(), registered.GetUpdatedAt()) {
+        t.Errorf("registered service has times %v and %v", registered.GetCreatedAt(), registered.GetUpdatedAt())
+    }
+    if _, err := s.RegisterService(ctx, &pb.ServiceDescriptor{SvcName: "svcD", SvcDesc: "d2"}); status.Code(err) != codes.AlreadyExists {
+        t.Errorf("registration of a registered service returned %v, want AlreadyExists", err)
+    }
+    if _, err := s.RegisterService(ctx, &pb.ServiceDescriptor{SvcName: "bad name"}); status.Code(err) != codes.InvalidArgument {
+        t.Errorf("registration of an invalid service returned %v, want InvalidArgument", err)
+    }
+
+    // the time of the registration is kept, whatever the update carries
+    update := &pb.ServiceDescriptor{SvcName: "svcD", SvcDesc: "d2", CreatedAt: ptypes.TimestampNow()}
+    updated, err := s.UpdateService(ctx, update)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !proto.Equal(updated.GetCreatedAt(), registered.GetCreatedAt()) || updated.GetSvcDesc() != "d2" {
+        t.Errorf("updated service = %v, registered at %v", updated, registered.GetCreatedAt())
+    }
+    created, _ := ptypes.Timestamp(registered.GetCreatedAt())
+    if at, err := ptypes.Timestamp(updated.GetUpdatedAt()); err != nil || at.Before(created) {
+        t.Errorf("service updated at %v, before its registration at %v", at, created)
+    }
+    if got, err := s.GetService(ctx, &pb.ServiceRequest{SvcName: "svcD"}); err != nil || !proto.Equal(got, updated) {
+        t.Errorf("service = %v, %v, want %v", got, err, updated)
+    }
+
+    for name, call := range map[string]func() error{
+        "update":       func() error { _, err := s.UpdateService(ctx, &pb.ServiceDescriptor{SvcName: "svcX"}); return err },
+        "deregister":   func() error { _, err := s.DeregisterService(ctx, &pb.ServiceRequest{SvcName: "svcX"}); return err },
+        "get":          func() error { _, err := s.GetService(ctx, &pb.ServiceRequest{SvcName: "svcX"}); return err },
+    } {
+        if err := call(); status.Code(err) != codes.NotFound {
+            t.Errorf("%s of a missing service returned %v, want NotFound", name, err)
+        }
+    }
+
+    if deregistered, err := s.DeregisterService(ctx, &pb.ServiceRequest{SvcName: "svcD"}); err != nil || !proto.Equal(deregistered, updated) {
+        t.Errorf("deregistered service = %v, %v, want %v", deregistered, err, updated)
+    }
+    if _, err := s.GetService(ctx, &pb.ServiceRequest{SvcName: "svcD"}); status.Code(err) != codes.NotFound {
+        t.Errorf("deregistered service is still registered: %v", err)
+    }
+}
+
+func TestListServices(t *testing.T) {
+    s := NewMyGrpcAdminServer(newRegistry(t))
+    ctx := context.Background()
+    for _, name := range []string{"svcE", "svcD"} {
+        if _, err := s.RegisterService(ctx, &pb.ServiceDescriptor{SvcName: name}); err != nil {
+            t.Fatal(err)
+        }
+    }
+    var names []string
+    var pages int
+    req := &pb.ListServicesRequest{PageSize: 2}
+    for {
+        resp, err := s.ListServices(ctx, req)
+        if err != nil {
+            t.Fatal(err)
+        }
+        pages++
+        for _, sd := range resp.GetServices() {
+            names = append(names, sd.GetSvcName())
+        }
+        if pages == 1 {
+            // a service registered after the last one of the page shows up in the next
+            // pages, one registered before does not shift them
+            for _, name := range []string{"svcBB", "svcAA"} {
+                if _, err := s.RegisterService(ctx, &pb.ServiceDescriptor{SvcName: name}); err != nil {
+                    t.Fatal(err)
+                }
+            }
+        } else if resp.GetTotalSize() != 7 {
+            t.Errorf("total size %d, want 7", resp.GetTotalSize())
+        }
+        if resp.GetNextPageToken() == "" {
+            break
+        }
+        req.PageToken = resp.GetNextPageToken()
+    }
+    if want := []string{"svcA", "svcB", "svcBB", "svcC", "svcD", "svcE"}; !reflect.DeepEqual(names, want) || pages != 3 {
+        t.Errorf("listed %v in %d pages, want %v in 3", names, pages, want)
+    }
+
+    if resp, err := s.ListServices(ctx, &pb.ListServicesRequest{}); err != nil || len(resp.GetServices()) != 7 || resp.GetNextPageToken() != "" {
+        t.Errorf("list with the default page size = %v, %v", resp, err)
+    }
+    if _, err := s.ListServices(ctx, &pb.ListServicesRequest{PageToken: "!"}); status.Code(err) != codes.InvalidArgument {
+        t.Errorf("list with a malformed token returned %v, want InvalidArgument", err)
+    }
+}
\ No newline at end of file
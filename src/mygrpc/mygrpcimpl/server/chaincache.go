@@ -0,0 +1,103 @@
+// Cache of the descriptors of the service chains resolved locally
+
+package server
+
+import (
+    "container/list"
+    "strconv"
+    "strings"
+    "sync"
+
+    pb "mygrpc/mygrpc"
+    "mygrpc/util/metrics"
+)
+
+// bounded LRU cache of resolved service chain descriptors keyed by the canonical
+// form of the chains. an entry is only valid for the revision of the registry it is
+// resolved at, so that any modification of the services invalidates it. the cached
+// descriptors are shared by the rpcs and must not be modified
+type ChainCache struct {
+    mu        sync.Mutex   // guards the fields below
+    size      int   // maximal number of entries
+    lru       *list.List   // entries from the most to the least recently used
+    entries   map[string]*list.Element
+    stats     metrics.CacheStats
+}
+
+type chainEntry struct {
+    key   string
+    rev   int64   // revision of the registry the chain is resolved at
+    scd   *pb.ServiceChainDescriptor
+}
+
+// create a cache holding at most size chains, nil if size is not positive
+func NewChainCache(size int) *ChainCache {
+    if size <= 0 {
+        return nil
+    }
+    return &ChainCache{size: size, lru: list.New(), entries: make(map[string]*list.Element, size)}
+}
+
+// return the canonical form of a valid service chain, its id followed by the names
+// of its services ordered by position, so that the order of the services in the
+// request does not matter
+func chainKey(sc *pb.ServiceChain) string {
+    names := make([]string, sc.GetChainLen())
+    for _, svc := range sc.GetChain() {
+        names[svc.GetSvcPos() - 1] = svc.GetSvcName()
+    }
+    return strconv.Itoa(int(sc.GetChainId())) + ":" + strings.Join(names, ",")
+}
+
+// return the descriptor of a chain resolved at revision rev of the registry. an
+// entry of another revision is out of date and dropped
+func (c *ChainCache) get(key string, rev int64) (*pb.ServiceChainDescriptor, bool) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    el, prs := c.entries[key]
+    if prs && el.Value.(*chainEntry).rev == rev {
+        c.lru.MoveToFront(el)
+        c.stats.Hits++
+        return el.Value.(*chainEntry).scd, true
+    }
+    // an rpc holding an older snapshot leaves the newer entry for the others
+    if prs && el.Value.(*chainEntry).rev < rev {
+        c.removeLocked(el)
+        c.stats.Invalidations++
+    }
+    c.stats.Misses++
+    return nil, false
+}
+
+// add the descriptor of a chain resolved at revision rev of the registry, evicting
+// the least recently used chain if the cache is full
+func (c *ChainCache) put(key string, rev int64, scd *pb.ServiceChainDescriptor) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if el, prs := c.entries[key]; prs {
+        if e := el.Value.(*chainEntry); e.rev <= rev {
+            e.rev, e.scd = rev, scd
+            c.lru.MoveToFront(el)
+        }
+        return
+    }
+    c.entries[key] = c.lru.PushFront(&chainEntry{key: key, rev: rev, scd: scd})
+    if c.lru.Len() > c.size {
+        c.removeLocked(c.lru.Back())
+        c.stats.Evictions++
+    }
+}
+
+func (c *ChainCache) removeLocked(el *list.Element) {
+    c.lru.Remove(el)
+    delete(c.entries, el.Value.(*chainEntry).key)
+}
+
+// return the statistics of the cache
+func (c *ChainCache) Stats() metrics.CacheStats {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    st := c.stats
+    st.Size = c.lru.Len()
+    return st
+}
\ No newline at end of file
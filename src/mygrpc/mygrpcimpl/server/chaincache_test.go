@@ -0,0 +1,153 @@
+package server
+
+import (
+    "io"
+    "net"
+    "testing"
+
+    pb "mygrpc/mygrpc"
+    reg "mygrpc/mygrpcimpl/registry"
+
+    "golang.org/x/net/context"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/test/bufconn"
+)
+
+func newRegistry(t testing.TB) reg.ServiceRegistry {
+    r, err := reg.NewMemRegistry([]*pb.ServiceDescriptor{
+             {SvcName: "svcA", SvcDesc: "a"},
+             {SvcName: "svcB", SvcDesc: "b"},
+             {SvcName: "svcC", SvcDesc: "c"},
+         })
+    if err != nil {
+        t.Fatal(err)
+    }
+    return r
+}
+
+func chainOf(id int32, names ...string) *pb.ServiceChain {
+    sc := &pb.ServiceChain{ChainId: id, ChainLen: int32(len(names))}
+    for i, name := range names {
+        sc.Chain = append(sc.Chain, &pb.Service{SvcName: name, SvcPos: int32(i + 1)})
+    }
+    return sc
+}
+
+func TestChainCache(t *testing.T) {
+    registry := newRegistry(t)
+    cache := NewChainCache(2)
+    s := NewMyGrpcServer(registry, "svcA", nil, cache)
+    resolve := func(sc *pb.ServiceChain) *pb.ServiceChainDescriptor {
+        scd, err := s.getServiceChainDescriptor(s.snapshot(), sc, false)
+        if err != nil {
+            t.Fatal(err)
+        }
+        return scd
+    }
+
+    first := resolve(chainOf(1, "svcA", "svcB"))
+    // the same chain with its services in another order
+    reordered := chainOf(1, "svcA", "svcB")
+    reordered.Chain[0], reordered.Chain[1] = reordered.Chain[1], reordered.Chain[0]
+    if resolve(reordered) != first {
+        t.Errorf("reordered chain not found in the cache")
+    }
+    if st := cache.Stats(); st.Hits != 1 || st.Misses != 1 || st.Size != 1 {
+        t.Errorf("stats = %+v, want 1 hit and 1 miss", st)
+    }
+
+    if err := registry.PutService(&pb.ServiceDescriptor{SvcName: "svcB", SvcDesc: "b2"}); err != nil {
+        t.Fatal(err)
+    }
+    if got := resolve(chainOf(1, "svcA", "svcB")).GetChainDesc()[1].GetSvcDesc(); got != "b2" {
+        t.Errorf("description after the update = %q, want b2", got)
+    }
+    if st := cache.Stats(); st.Invalidations != 1 {
+        t.Errorf("stats = %+v, want 1 invalidation", st)
+    }
+
+    resolve(chainOf(2, "svcC"))
+    resolve(chainOf(1, "svcA", "svcB"))
+    resolve(chainOf(3, "svcB"))
+    if _, hit := cache.get(chainKey(chainOf(2, "svcC")), reg.SnapshotOf(registry).(reg.Revisioned).Revision()); hit {
+        t.Errorf("least recently used chain not evicted")
+    }
+    if st := cache.Stats(); st.Evictions != 1 || st.Size != 2 {
+        t.Errorf("stats = %+v, want 1 eviction and 2 entries", st)
+    }
+
+    if _, err := s.getServiceChainDescriptor(s.snapshot(), chainOf(4, "svcX"), false); err == nil {
+        t.Errorf("resolved a chain of an unknown service")
+    }
+    if err := registry.PutService(&pb.ServiceDescriptor{SvcName: "svcX"}); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := s.getServiceChainDescriptor(s.snapshot(), chainOf(4, "svcX"), false); err != nil {
+        t.Errorf("chain of a new service not resolved: %v", err)
+    }
+}
+
+// serve the chains over an in-memory connection, returning the client and a function
+// stopping the server
+func startServer(b testing.TB, cache *ChainCache) (pb.MyGrpcClient, func()) {
+    lis := bufconn.Listen(1 << 20)
+    srv := grpc.NewServer()
+    pb.RegisterMyGrpcServer(srv, NewMyGrpcServer(newRegistry(b), "svcA", nil, cache))
+    go srv.Serve(lis)
+    conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+        return lis.DialContext(ctx)
+    }))
+    if err != nil {
+        b.Fatal(err)
+    }
+    return pb.NewMyGrpcClient(conn), func() {
+        conn.Close()
+        srv.Stop()
+    }
+}
+
+// resolve the same three chains over and over on one bidirectional stream, as the
+// client does with a large -num
+func BenchmarkGetChainsReqsResps(b *testing.B) {
+    chains := []*pb.ServiceChain{chainOf(1, "svcA", "svcB", "svcC"), chainOf(2, "svcC", "svcB"), chainOf(3, "svcB")}
+    for _, bc := range []struct {
+        name    string
+        cache   *ChainCache
+    }{
+        {"nocache", nil},
+        {"cache", NewChainCache(16)},
+    } {
+        b.Run(bc.name, func(b *testing.B) {
+            client, stop := startServer(b, bc.cache)
+            defer stop()
+            stream, err := client.GetChainsReqsResps(context.Background())
+            if err != nil {
+                b.Fatal(err)
+            }
+            done := make(chan error, 1)
+            go func() {
+                for {
+                    if _, err := stream.Recv(); err != nil {
+                        if err == io.EOF {
+                            err = nil
+                        }
+                        done <- err
+                        return
+                    }
+                }
+            }()
+
+            b.ReportAllocs()
+            b.ResetTimer()
+            for i := 0; i < b.N; i++ {
+                if err := stream.Send(chains[i % len(chains)]); err != nil {
+                    b.Fatal(err)
+                }
+            }
+            stream.CloseSend()
+            if err := <-done; err != nil {
+                b.Fatal(err)
+            }
+        })
+    }
+}
\ No newline at end of file
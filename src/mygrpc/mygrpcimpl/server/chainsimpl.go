@@ -0,0 +1,158 @@
+// Implementations of the service chains stored by the server of mygrpc
+
+package server
+
+import (
+    "sort"
+    "strconv"
+
+    pb "mygrpc/mygrpc"
+    reg "mygrpc/mygrpcimpl/registry"
+    val "mygrpc/util/validate"
+
+    "github.com/golang/protobuf/proto"
+    "github.com/golang/protobuf/ptypes"
+    "golang.org/x/net/context"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+)
+
+const reasonChainNotFound = "CHAIN_NOT_FOUND"
+
+// return the service chain to resolve for a request: the chain stored under its
+// chain_id when the request is a reference, else the request itself
+func expandChain(svcs reg.ServiceLookup, sc *pb.ServiceChain) (*pb.ServiceChain, error) {
+    if !val.IsChainRef(sc) {
+        return sc, nil
+    }
+    if err := val.ValServiceChain(sc); err != nil {
+        return nil, err
+    }
+    var c *pb.StoredChain
+    prs := false
+    if chains, ok := svcs.(reg.ChainLookup); ok {
+        c, prs = chains.GetChain(sc.GetChainId())
+    }
+    if !prs {
+        return nil, &ServiceError{
+                        ChainId: sc.GetChainId(),
+                        Index:   -1,
+                        Field:   "chain_id",
+                        Code:    codes.NotFound,
+                        Reason:  reasonChainNotFound,
+                        Msg:     "No stored service chain found",
+                    }
+    }
+    return &pb.ServiceChain{ChainId: c.GetChainId(), ChainLen: int32(len(c.GetChain())), Chain: c.GetChain()}, nil
+}
+
+// return the registry as a store of chains, or an error if it does not store chains
+func (s *myGrpcAdminServer) chainStore() (reg.ChainStore, error) {
+    cs, ok := s.registry.(reg.ChainStore)
+    if !ok {
+        return nil, status.Error(codes.Unimplemented, "The service registry does not store service chains")
+    }
+    return cs, nil
+}
+
+func (s *myGrpcAdminServer) CreateChain(ctx context.Context, c *pb.StoredChain) (*pb.StoredChain, error) {
+    myGrpcLogger.Info("Received service chain creation", "chain_id", c.GetChainId(), "name", c.GetName())
+    cs, err := s.chainStore()
+    if err != nil {
+        return nil, err
+    }
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if _, prs := cs.GetChain(c.GetChainId()); prs {
+        return nil, status.Errorf(codes.AlreadyExists, "Service chain %d is already stored", c.GetChainId())
+    }
+    c = proto.Clone(c).(*pb.StoredChain)
+    c.CreatedAt = ptypes.TimestampNow()
+    c.UpdatedAt = c.CreatedAt
+    if err := cs.PutChain(c); err != nil {
+        return nil, registryStatus(err)
+    }
+    return getChain(cs, c.GetChainId())
+}
+
+func (s *myGrpcAdminServer) UpdateChain(ctx context.Context, c *pb.StoredChain) (*pb.StoredChain, error) {
+    myGrpcLogger.Info("Received service chain update", "chain_id", c.GetChainId(), "name", c.GetName())
+    cs, err := s.chainStore()
+    if err != nil {
+        return nil, err
+    }
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    oc, err := getChain(cs, c.GetChainId())
+    if err != nil {
+        return nil, err
+    }
+    // the time of the creation is kept
+    c = proto.Clone(c).(*pb.StoredChain)
+    c.CreatedAt = oc.GetCreatedAt()
+    c.UpdatedAt = ptypes.TimestampNow()
+    if err := cs.PutChain(c); err != nil {
+        return nil, registryStatus(err)
+    }
+    return getChain(cs, c.GetChainId())
+}
+
+func (s *myGrpcAdminServer) DeleteChain(ctx context.Context, req *pb.ChainRequest) (*pb.StoredChain, error) {
+    myGrpcLogger.Info("Received service chain deletion", "chain_id", req.GetChainId())
+    cs, err := s.chainStore()
+    if err != nil {
+        return nil, err
+    }
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    c, err := getChain(cs, req.GetChainId())
+    if err != nil {
+        return nil, err
+    }
+    if err := cs.DeleteChain(req.GetChainId()); err != nil {
+        return nil, registryStatus(err)
+    }
+    return c, nil
+}
+
+func (s *myGrpcAdminServer) GetChain(ctx context.Context, req *pb.ChainRequest) (*pb.StoredChain, error) {
+    cs, err := s.chainStore()
+    if err != nil {
+        return nil, err
+    }
+    return getChain(cs, req.GetChainId())
+}
+
+func getChain(cs reg.ChainStore, id int32) (*pb.StoredChain, error) {
+    c, prs := cs.GetChain(id)
+    if !prs {
+        return nil, status.Errorf(codes.NotFound, "Service chain %d is not stored", id)
+    }
+    return c, nil
+}
+
+// the page token is the encoded id of the last chain in the previous page, as for
+// ListServices
+func (s *myGrpcAdminServer) ListChains(ctx context.Context, req *pb.ListChainsRequest) (*pb.ListChainsResponse, error) {
+    cs, err := s.chainStore()
+    if err != nil {
+        return nil, err
+    }
+    chains, err := cs.ListChains()
+    if err != nil {
+        return nil, registryStatus(err)
+    }
+    seek := func(last string) (int, error) {
+        after, err := strconv.ParseInt(last, 10, 32)
+        return sort.Search(len(chains), func(i int) bool { return int64(chains[i].GetChainId()) > after }), err
+    }
+    start, end, next, err := listPage(len(chains), req.GetPageSize(), req.GetPageToken(), seek, func(i int) string { return strconv.Itoa(int(chains[i].GetChainId())) })
+    if err != nil {
+        return nil, err
+    }
+    return &pb.ListChainsResponse{
+               Chains:         chains[start:end],
+               NextPageToken:  next,
+               TotalSize:      int32(len(chains)),
+           }, nil
+}
\ No newline at end of file
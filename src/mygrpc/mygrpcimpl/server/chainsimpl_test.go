@@ -0,0 +1,184 @@
+package server
+
+import (
+    "io"
+    "testing"
+
+    pb "mygrpc/mygrpc"
+
+    "github.com/golang/protobuf/proto"
+    "github.com/golang/protobuf/ptypes"
+    "golang.org/x/net/context"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+)
+
+func TestChainAdmin(t *testing.T) {
+    s := NewMyGrpcAdminServer(newRegistry(t))
+    ctx := context.Background()
+    created, err := s.CreateChain(ctx, storedChain(1, "svcA", "svcB"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if created.GetCreatedAt() == nil || !proto.Equal(created.GetCreatedAt(), created.GetUpdatedAt()) {
+        t.Errorf("created chain has times %v and %v", created.GetCreatedAt(), created.GetUpdatedAt())
+    }
+    if _, err := s.CreateChain(ctx, storedChain(1, "svcC")); status.Code(err) != codes.AlreadyExists {
+        t.Errorf("creation of a stored chain returned %v, want AlreadyExists", err)
+    }
+
+    // the time of the creation is kept, whatever the update carries
+    update := storedChain(1, "svcC")
+    update.CreatedAt = ptypes.TimestampNow()
+    updated, err := s.UpdateChain(ctx, update)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !proto.Equal(updated.GetCreatedAt(), created.GetCreatedAt()) || len(updated.GetChain()) != 1 {
+        t.Errorf("updated chain = %v, created at %v", updated, created.GetCreatedAt())
+    }
+    if got, err := s.GetChain(ctx, &pb.ChainRequest{ChainId: 1}); err != nil || !proto.Equal(got, updated) {
+        t.Errorf("chain = %v, %v, want %v", got, err, updated)
+    }
+
+    for name, call := range map[string]func() error{
+        "update": func() error { _, err := s.UpdateChain(ctx, storedChain(2, "svcA")); return err },
+        "delete": func() error { _, err := s.DeleteChain(ctx, &pb.ChainRequest{ChainId: 2}); return err },
+        "get":    func() error { _, err := s.GetChain(ctx, &pb.ChainRequest{ChainId: 2}); return err },
+    } {
+        if err := call(); status.Code(err) != codes.NotFound {
+            t.Errorf("%s of a missing chain returned %v, want NotFound", name, err)
+        }
+    }
+    if _, err := s.CreateChain(ctx, storedChain(2, "svcA", "svcA", "svcA")); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := s.CreateChain(ctx, &pb.StoredChain{ChainId: 3}); status.Code(err) != codes.InvalidArgument {
+        t.Errorf("creation of a chain without service returned %v, want InvalidArgument", err)
+    }
+
+    // a chain may not be deleted while another one references it
+    if _, err := s.CreateChain(ctx, storedChain(4, "svcA", 1)); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := s.DeleteChain(ctx, &pb.ChainRequest{ChainId: 1}); status.Code(err) != codes.FailedPrecondition {
+        t.Errorf("deletion of a referenced chain returned %v, want FailedPrecondition", err)
+    }
+    if _, err := s.DeleteChain(ctx, &pb.ChainRequest{ChainId: 4}); err != nil {
+        t.Fatal(err)
+    }
+    if deleted, err := s.DeleteChain(ctx, &pb.ChainRequest{ChainId: 1}); err != nil || deleted.GetChainId() != 1 {
+        t.Errorf("deleted chain = %v, %v", deleted, err)
+    }
+    if _, err := s.GetChain(ctx, &pb.ChainRequest{ChainId: 1}); status.Code(err) != codes.NotFound {
+        t.Errorf("deleted chain is still stored: %v", err)
+    }
+}
+
+func TestListChains(t *testing.T) {
+    s := NewMyGrpcAdminServer(newRegistry(t))
+    ctx := context.Background()
+    for _, id := range []int32{5, 3, 10, 1, 7} {
+        if _, err := s.CreateChain(ctx, storedChain(id, "svcA")); err != nil {
+            t.Fatal(err)
+        }
+    }
+    var ids []int32
+    var pages int
+    req := &pb.ListChainsRequest{PageSize: 2}
+    for {
+        resp, err := s.ListChains(ctx, req)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if resp.GetTotalSize() != 5 {
+            t.Errorf("total size %d, want 5", resp.GetTotalSize())
+        }
+        pages++
+        for _, c := range resp.GetChains() {
+            ids = append(ids, c.GetChainId())
+        }
+        if resp.GetNextPageToken() == "" {
+            break
+        }
+        req.PageToken = resp.GetNextPageToken()
+    }
+    if pages != 3 || len(ids) != 5 || ids[0] != 1 || ids[2] != 5 || ids[4] != 10 {
+        t.Errorf("listed chains %v in %d pages", ids, pages)
+    }
+    for _, token := range []string{"x", encodePageToken("x")} {
+        if _, err := s.ListChains(ctx, &pb.ListChainsRequest{PageToken: token}); status.Code(err) != codes.InvalidArgument {
+            t.Errorf("page token %q returned %v, want InvalidArgument", token, err)
+        }
+    }
+}
+
+func TestStoredChainRequests(t *testing.T) {
+    registry := newRegistry(t)
+    if _, err := NewMyGrpcAdminServer(registry).CreateChain(context.Background(), storedChain(7, "svcB", "svcC")); err != nil {
+        t.Fatal(err)
+    }
+    client := serveRegistry(t, registry)
+    ctx := context.Background()
+    ref := &pb.ServiceChain{ChainId: 7}
+    check := func(rpc string, scd *pb.ServiceChainDescriptor) {
+        if scd.GetChainId() != 7 || scd.GetChainLen() != 2 || len(scd.GetChainDesc()) != 2 || scd.GetChainDesc()[1].GetSvcName() != "svcC" {
+            t.Errorf("%s: stored chain resolved to %v", rpc, scd)
+        }
+    }
+
+    scd, err := client.GetChainReqResp(ctx, ref)
+    if err != nil {
+        t.Fatal(err)
+    }
+    check("simple", scd)
+    _, err = client.GetChainReqResp(ctx, &pb.ServiceChain{ChainId: 8})
+    if status.Code(err) != codes.NotFound {
+        t.Errorf("request of a missing chain returned %v, want NotFound", err)
+    }
+
+    sstream, err := client.GetChainsReqResps(ctx, &pb.ServiceChains{Chains: []*pb.ServiceChain{ref, ref}})
+    if err != nil {
+        t.Fatal(err)
+    }
+    for n := 0; ; n++ {
+        cr, err := sstream.Recv()
+        if err == io.EOF {
+            if n != 2 {
+                t.Errorf("server-streaming: %d results, want 2", n)
+            }
+            break
+        }
+        if err != nil {
+            t.Fatal(err)
+        }
+        check("server-streaming", cr)
+    }
+
+    cstream, err := client.GetChainsReqsResp(ctx)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := cstream.Send(ref); err != nil {
+        t.Fatal(err)
+    }
+    crs, err := cstream.CloseAndRecv()
+    if err != nil || len(crs.GetChainDescs()) != 1 {
+        t.Fatalf("client-streaming: %v, %v", crs, err)
+    }
+    check("client-streaming", crs.GetChainDescs()[0])
+
+    bstream, err := client.GetChainsReqsResps(ctx)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := bstream.Send(ref); err != nil {
+        t.Fatal(err)
+    }
+    cr, err := bstream.Recv()
+    if err != nil {
+        t.Fatal(err)
+    }
+    check("bidirectional streaming", cr)
+    bstream.CloseSend()
+}
\ No newline at end of file
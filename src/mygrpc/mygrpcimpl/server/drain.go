@@ -0,0 +1,66 @@
+// Tracking of the rpcs in progress, reporting the ones cut off when the server stops
+
+package server
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+    "sync"
+
+    "golang.org/x/net/context"
+    "google.golang.org/grpc"
+)
+
+// tracker of the rpcs in progress on a server
+type RpcTracker struct {
+    mu        sync.Mutex   // guards the fields below
+    unary     map[string]int   // number of unary rpcs in progress, by method
+    streams   map[string]int   // number of streams open, by method
+}
+
+func NewRpcTracker() *RpcTracker {
+    return &RpcTracker{unary: make(map[string]int), streams: make(map[string]int)}
+}
+
+func (t *RpcTracker) add(counts map[string]int, method string, n int) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    counts[method] += n
+    if counts[method] == 0 {
+        delete(counts, method)
+    }
+}
+
+// return the number of unary rpcs in progress and of streams open, with a description
+// of the streams by method
+func (t *RpcTracker) Open() (unary, streams int, desc string) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    for _, n := range t.unary {
+        unary += n
+    }
+    var ms []string
+    for m, n := range t.streams {
+        streams += n
+        ms = append(ms, fmt.Sprintf("%s x%d", m, n))
+    }
+    sort.Strings(ms)
+    return unary, streams, strings.Join(ms, ", ")
+}
+
+func (t *RpcTracker) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+    return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+        t.add(t.unary, info.FullMethod, 1)
+        defer t.add(t.unary, info.FullMethod, -1)
+        return handler(ctx, req)
+    }
+}
+
+func (t *RpcTracker) StreamServerInterceptor() grpc.StreamServerInterceptor {
+    return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+        t.add(t.streams, info.FullMethod, 1)
+        defer t.add(t.streams, info.FullMethod, -1)
+        return handler(srv, ss)
+    }
+}
\ No newline at end of file
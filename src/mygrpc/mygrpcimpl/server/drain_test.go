@@ -0,0 +1,51 @@
+package server
+
+import (
+    "sync"
+    "testing"
+
+    "golang.org/x/net/context"
+    "google.golang.org/grpc"
+)
+
+func TestRpcTracker(t *testing.T) {
+    tr := NewRpcTracker()
+    unary, stream := tr.UnaryServerInterceptor(), tr.StreamServerInterceptor()
+    release := make(chan struct{})
+    var started, done sync.WaitGroup
+    // rpcs blocked in their handlers until released
+    for _, method := range []string{"/mygrpc.MyGrpc/GetChainReqResp", "/mygrpc.MyGrpc/GetChainReqResp", "/mygrpc.MyGrpc/SearchServices"} {
+        started.Add(1)
+        done.Add(1)
+        go func(method string) {
+            defer done.Done()
+            unary(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+                started.Done()
+                <-release
+                return nil, nil
+            })
+        }(method)
+    }
+    for _, method := range []string{"/mygrpc.MyGrpc/WatchChain", "/mygrpc.MyGrpc/WatchServices", "/mygrpc.MyGrpc/WatchChain"} {
+        started.Add(1)
+        done.Add(1)
+        go func(method string) {
+            defer done.Done()
+            stream(nil, nil, &grpc.StreamServerInfo{FullMethod: method, IsServerStream: true}, func(srv interface{}, ss grpc.ServerStream) error {
+                started.Done()
+                <-release
+                return nil
+            })
+        }(method)
+    }
+    started.Wait()
+    if u, s, desc := tr.Open(); u != 3 || s != 3 || desc != "/mygrpc.MyGrpc/WatchChain x2, /mygrpc.MyGrpc/WatchServices x1" {
+        t.Errorf("open rpcs = %d unary, %d streams: %s", u, s, desc)
+    }
+
+    close(release)
+    done.Wait()
+    if u, s, desc := tr.Open(); u != 0 || s != 0 || desc != "" {
+        t.Errorf("open rpcs after they finish = %d unary, %d streams: %s", u, s, desc)
+    }
+}
\ No newline at end of file
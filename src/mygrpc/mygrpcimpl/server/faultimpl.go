@@ -0,0 +1,310 @@
+// Injection of faults into the rpcs of mygrpc, changeable at runtime by MyGrpcFault
+
+package server
+
+import (
+    "fmt"
+    "io/ioutil"
+    "math"
+    "math/rand"
+    "path"
+    "strings"
+    "sync"
+    "time"
+
+    pb "mygrpc/mygrpc"
+
+    "github.com/golang/protobuf/proto"
+    "golang.org/x/net/context"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+    "google.golang.org/protobuf/encoding/protojson"
+    "google.golang.org/protobuf/protoadapt"
+)
+
+const faultMethodPrefix = "/mygrpc.MyGrpc/"   // faults are only injected into the rpcs of MyGrpc
+
+// injector of the faults into the rpcs of MyGrpc served as a service, following
+// rules the first of which applying to a request decides its faults
+type FaultInjector struct {
+    svcName   string   // name of the service providing by the server
+    mu        sync.RWMutex
+    config    *pb.FaultConfig
+}
+
+func NewFaultInjector(svcName string) *FaultInjector {
+    return &FaultInjector{svcName: svcName, config: &pb.FaultConfig{}}
+}
+
+// read the fault rules from a json file in the form of a FaultConfig, e.g.
+// {"rules": [{"methods": ["/mygrpc.MyGrpc/GetChainReqResp"], "errorRate": 0.1}]}
+func LoadFaultConfig(p string) (*pb.FaultConfig, error) {
+    data, err := ioutil.ReadFile(p)
+    if err != nil {
+        return nil, err
+    }
+    cfg := &pb.FaultConfig{}
+    if err := protojson.Unmarshal(data, protoadapt.MessageV2Of(cfg)); err != nil {
+        return nil, fmt.Errorf("Failed to unmarshal the fault rules from %s: %v", p, err)
+    }
+    return cfg, nil
+}
+
+// validate the rules of a fault config
+func ValFaultConfig(cfg *pb.FaultConfig) error {
+    for i, r := range cfg.GetRules() {
+        for _, pats := range [][]string{r.GetMethods(), r.GetSvcNames()} {
+            for _, pat := range pats {
+                if _, err := path.Match(pat, ""); err != nil {
+                    return fmt.Errorf("Rule %d has malformed pattern %q", i, pat)
+                }
+            }
+        }
+        if r.GetErrorRate() < 0 || r.GetErrorRate() > 1 {
+            return fmt.Errorf("Rule %d has error_rate %v out of [0, 1]", i, r.GetErrorRate())
+        }
+        if r.GetErrorCode() < 0 || r.GetErrorCode() > int32(codes.Unauthenticated) {
+            return fmt.Errorf("Rule %d has invalid error_code %d", i, r.GetErrorCode())
+        }
+        if r.GetAbortAfter() < 0 || r.GetSendDelayMs() < 0 {
+            return fmt.Errorf("Rule %d has negative abort_after or send_delay_ms", i)
+        }
+        if d := r.GetDelay(); d != nil {
+            if d.GetDelayMs() < 0 || d.GetMaxMs() < 0 || d.GetStddevMs() < 0 {
+                return fmt.Errorf("Rule %d has a negative delay", i)
+            }
+            if d.GetFraction() < 0 || d.GetFraction() > 1 {
+                return fmt.Errorf("Rule %d has delay fraction %v out of [0, 1]", i, d.GetFraction())
+            }
+            if d.GetDistribution() == pb.DelayDistribution_DELAY_UNIFORM && d.GetMaxMs() < d.GetDelayMs() {
+                return fmt.Errorf("Rule %d has a uniform delay with max_ms below delay_ms", i)
+            }
+            if _, ok := pb.DelayDistribution_name[int32(d.GetDistribution())]; !ok {
+                return fmt.Errorf("Rule %d has unknown delay distribution %d", i, d.GetDistribution())
+            }
+        }
+    }
+    return nil
+}
+
+// replace the fault rules, which are validated first
+func (f *FaultInjector) SetConfig(cfg *pb.FaultConfig) error {
+    if err := ValFaultConfig(cfg); err != nil {
+        return err
+    }
+    cfg = proto.Clone(cfg).(*pb.FaultConfig)
+    f.mu.Lock()
+    f.config = cfg
+    f.mu.Unlock()
+    myGrpcLogger.Info("Set fault rules", "rules", len(cfg.GetRules()))
+    return nil
+}
+
+// return a copy of the fault rules
+func (f *FaultInjector) Config() *pb.FaultConfig {
+    f.mu.RLock()
+    defer f.mu.RUnlock()
+    return proto.Clone(f.config).(*pb.FaultConfig)
+}
+
+// return the rule deciding the faults of a request of the method for the chain, nil
+// if none applies. chainId is negative for requests of no chain
+func (f *FaultInjector) rule(method string, chainId int32) *pb.FaultRule {
+    f.mu.RLock()
+    defer f.mu.RUnlock()
+    for _, r := range f.config.GetRules() {
+        if len(r.GetMethods()) > 0 && !matchAny(r.GetMethods(), method) {
+            continue
+        }
+        if len(r.GetSvcNames()) > 0 && !matchAny(r.GetSvcNames(), f.svcName) {
+            continue
+        }
+        if len(r.GetChainIds()) > 0 && !containsChain(r.GetChainIds(), chainId) {
+            continue
+        }
+        return r
+    }
+    return nil
+}
+
+func matchAny(pats []string, s string) bool {
+    for _, pat := range pats {
+        if ok, _ := path.Match(pat, s); ok {
+            return true
+        }
+    }
+    return false
+}
+
+func containsChain(ids []int32, id int32) bool {
+    for _, i := range ids {
+        if i == id {
+            return true
+        }
+    }
+    return false
+}
+
+// return the id of the service chain of a message, or -1
+func chainIdOf(m interface{}) int32 {
+    switch msg := m.(type) {
+        case interface{ GetChainId() int32 }:
+            return msg.GetChainId()
+        case interface{ GetChain() *pb.ServiceChain }:
+            if sc := msg.GetChain(); sc != nil {
+                return sc.GetChainId()
+            }
+    }
+    return -1
+}
+
+// return the status of the requests failed, and the streams aborted, by a rule
+func faultStatus(r *pb.FaultRule, msg string) error {
+    code := codes.Code(r.GetErrorCode())
+    if code == codes.OK {
+        code = codes.Unavailable
+    }
+    if r.GetErrorMessage() != "" {
+        msg = r.GetErrorMessage()
+    }
+    return status.Error(code, msg)
+}
+
+// return the delay of a request drawn from the distribution, 0 if not delayed
+func delayOf(d *pb.FaultDelay) time.Duration {
+    if d == nil || (d.GetFraction() > 0 && rand.Float64() >= d.GetFraction()) {
+        return 0
+    }
+    ms := float64(d.GetDelayMs())
+    switch d.GetDistribution() {
+        case pb.DelayDistribution_DELAY_UNIFORM:
+            ms += rand.Float64() * float64(d.GetMaxMs() - d.GetDelayMs())
+        case pb.DelayDistribution_DELAY_EXPONENTIAL:
+            ms *= rand.ExpFloat64()
+        case pb.DelayDistribution_DELAY_NORMAL:
+            ms = math.Max(0, ms + rand.NormFloat64() * float64(d.GetStddevMs()))
+    }
+    return time.Duration(ms * float64(time.Millisecond))
+}
+
+// wait for the delay, unless the rpc ends before
+func sleep(ctx context.Context, d time.Duration) error {
+    if d <= 0 {
+        return nil
+    }
+    t := time.NewTimer(d)
+    defer t.Stop()
+    select {
+        case <-t.C:
+            return nil
+        case <-ctx.Done():
+            return status.FromContextError(ctx.Err()).Err()
+    }
+}
+
+// inject the delay and the error of the rule deciding the faults of a request
+func (f *FaultInjector) injectRequest(ctx context.Context, method string, req interface{}) error {
+    r := f.rule(method, chainIdOf(req))
+    if r == nil {
+        return nil
+    }
+    d := delayOf(r.GetDelay())
+    if d > 0 {
+        myGrpcLogger.Debug("Inject delay", "method", method, "chain_id", chainIdOf(req), "delay", d)
+    }
+    if err := sleep(ctx, d); err != nil {
+        return err
+    }
+    if r.GetErrorRate() > 0 && rand.Float64() < r.GetErrorRate() {
+        err := faultStatus(r, fmt.Sprintf("Fault injected by service %s", f.svcName))
+        myGrpcLogger.Debug("Inject error", "method", method, "chain_id", chainIdOf(req), "code", status.Code(err).String())
+        return err
+    }
+    return nil
+}
+
+func (f *FaultInjector) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+    return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+        if !strings.HasPrefix(info.FullMethod, faultMethodPrefix) {
+            return handler(ctx, req)
+        }
+        if err := f.injectRequest(ctx, info.FullMethod, req); err != nil {
+            return nil, err
+        }
+        return handler(ctx, req)
+    }
+}
+
+func (f *FaultInjector) StreamServerInterceptor() grpc.StreamServerInterceptor {
+    return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+        if !strings.HasPrefix(info.FullMethod, faultMethodPrefix) {
+            return handler(srv, ss)
+        }
+        return handler(srv, &faultyServerStream{ServerStream: ss, injector: f, method: info.FullMethod, chainId: -1})
+    }
+}
+
+// server stream injecting the faults into every request received, and delaying or
+// aborting the messages sent
+type faultyServerStream struct {
+    grpc.ServerStream
+    injector   *FaultInjector
+    method     string
+    mu         sync.Mutex
+    chainId    int32   // chain of the last request received, for the messages sent with no chain
+    sent       int32
+}
+
+func (s *faultyServerStream) RecvMsg(m interface{}) error {
+    if err := s.ServerStream.RecvMsg(m); err != nil {
+        return err
+    }
+    if id := chainIdOf(m); id >= 0 {
+        s.mu.Lock()
+        s.chainId = id
+        s.mu.Unlock()
+    }
+    return s.injector.injectRequest(s.Context(), s.method, m)
+}
+
+func (s *faultyServerStream) SendMsg(m interface{}) error {
+    id := chainIdOf(m)
+    s.mu.Lock()
+    if id < 0 {
+        id = s.chainId
+    }
+    s.sent++
+    sent := s.sent
+    s.mu.Unlock()
+    if r := s.injector.rule(s.method, id); r != nil {
+        if r.GetAbortAfter() > 0 && sent > r.GetAbortAfter() {
+            myGrpcLogger.Debug("Inject stream abort", "method", s.method, "chain_id", id, "sent", sent - 1)
+            return faultStatus(r, fmt.Sprintf("Stream aborted by service %s after %d messages", s.injector.svcName, r.GetAbortAfter()))
+        }
+        if err := sleep(s.Context(), time.Duration(r.GetSendDelayMs()) * time.Millisecond); err != nil {
+            return err
+        }
+    }
+    return s.ServerStream.SendMsg(m)
+}
+
+// the control service of the fault injection
+type myGrpcFaultServer struct {
+    injector   *FaultInjector
+}
+
+func NewMyGrpcFaultServer(injector *FaultInjector) *myGrpcFaultServer {
+    return &myGrpcFaultServer{injector: injector}
+}
+
+func (s *myGrpcFaultServer) SetFaults(ctx context.Context, cfg *pb.FaultConfig) (*pb.FaultConfig, error) {
+    if err := s.injector.SetConfig(cfg); err != nil {
+        return nil, status.Error(codes.InvalidArgument, err.Error())
+    }
+    return s.injector.Config(), nil
+}
+
+func (s *myGrpcFaultServer) GetFaults(ctx context.Context, req *pb.GetFaultsRequest) (*pb.FaultConfig, error) {
+    return s.injector.Config(), nil
+}
\ No newline at end of file
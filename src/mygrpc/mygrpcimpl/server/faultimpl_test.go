@@ -0,0 +1,148 @@
+package server
+
+import (
+    "testing"
+    "time"
+
+    pb "mygrpc/mygrpc"
+
+    "golang.org/x/net/context"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+)
+
+func TestFaultRule(t *testing.T) {
+    f := NewFaultInjector("svcB")
+    if err := f.SetConfig(&pb.FaultConfig{Rules: []*pb.FaultRule{
+                 {ErrorMessage: "svcA only", SvcNames: []string{"svcA"}},
+                 {ErrorMessage: "simple of chain 3", Methods: []string{"/mygrpc.MyGrpc/GetChainReqResp"}, SvcNames: []string{"svc[BC]"}, ChainIds: []int32{3}},
+                 {ErrorMessage: "streams", Methods: []string{"/mygrpc.MyGrpc/GetChains*"}},
+                 {ErrorMessage: "chains 1 and 2", ChainIds: []int32{1, 2}},
+             }}); err != nil {
+        t.Fatal(err)
+    }
+    for _, tc := range []struct {
+        method    string
+        chainId   int32
+        want      string
+    }{
+        {"/mygrpc.MyGrpc/GetChainReqResp", 3, "simple of chain 3"},
+        {"/mygrpc.MyGrpc/GetChainReqResp", 1, "chains 1 and 2"},
+        {"/mygrpc.MyGrpc/GetChainReqResp", 4, ""},
+        {"/mygrpc.MyGrpc/GetChainsReqsResps", 3, "streams"},
+        {"/mygrpc.MyGrpc/GetChainsReqsResps", -1, "streams"},
+        {"/mygrpc.MyGrpc/WatchChain", 2, "chains 1 and 2"},
+        {"/mygrpc.MyGrpc/WatchChain", -1, ""},
+    } {
+        if got := f.rule(tc.method, tc.chainId).GetErrorMessage(); got != tc.want {
+            t.Errorf("rule of %s for chain %d = %q, want %q", tc.method, tc.chainId, got, tc.want)
+        }
+    }
+
+    // the rules scoped by service apply to the servers of the service only
+    if got := NewFaultInjector("svcA"); got.SetConfig(f.Config()) != nil || got.rule("/mygrpc.MyGrpc/WatchChain", -1).GetErrorMessage() != "svcA only" {
+        t.Errorf("rule of svcA = %v", got.rule("/mygrpc.MyGrpc/WatchChain", -1))
+    }
+}
+
+func TestDelayOf(t *testing.T) {
+    ms := time.Millisecond
+    for _, tc := range []struct {
+        delay      *pb.FaultDelay
+        min, max   time.Duration
+    }{
+        {nil, 0, 0},
+        {&pb.FaultDelay{DelayMs: 50}, 50 * ms, 50 * ms},
+        {&pb.FaultDelay{Distribution: pb.DelayDistribution_DELAY_UNIFORM, DelayMs: 10, MaxMs: 20}, 10 * ms, 20 * ms},
+        {&pb.FaultDelay{Distribution: pb.DelayDistribution_DELAY_EXPONENTIAL, DelayMs: 10}, 0, time.Hour},
+        {&pb.FaultDelay{Distribution: pb.DelayDistribution_DELAY_NORMAL, DelayMs: 10, StddevMs: 100}, 0, time.Hour},
+    } {
+        for i := 0; i < 1000; i++ {
+            if d := delayOf(tc.delay); d < tc.min || d > tc.max {
+                t.Errorf("delay %v out of [%v, %v] for %v", d, tc.min, tc.max, tc.delay)
+                break
+            }
+        }
+    }
+
+    delayed := 0
+    for i := 0; i < 1000; i++ {
+        if delayOf(&pb.FaultDelay{DelayMs: 10, Fraction: 0.3}) > 0 {
+            delayed++
+        }
+    }
+    if delayed < 200 || delayed > 400 {
+        t.Errorf("%d requests in 1000 delayed, want about 300", delayed)
+    }
+}
+
+// server stream recording the messages sent
+type recordingStream struct {
+    grpc.ServerStream
+    sent   []interface{}
+}
+
+func (s *recordingStream) Context() context.Context {
+    return context.Background()
+}
+
+func (s *recordingStream) SendMsg(m interface{}) error {
+    s.sent = append(s.sent, m)
+    return nil
+}
+
+func TestFaultyServerStream(t *testing.T) {
+    f := NewFaultInjector("svcA")
+    if err := f.SetConfig(&pb.FaultConfig{Rules: []*pb.FaultRule{
+                 {ChainIds: []int32{3}, AbortAfter: 1},
+                 {Methods: []string{"/mygrpc.MyGrpc/GetChainsReqResps"}, AbortAfter: 2, ErrorCode: int32(codes.Aborted)},
+             }}); err != nil {
+        t.Fatal(err)
+    }
+    rs := &recordingStream{}
+    ss := &faultyServerStream{ServerStream: rs, injector: f, method: "/mygrpc.MyGrpc/GetChainsReqResps", chainId: -1}
+    var errs []error
+    for i := int32(4); i <= 6; i++ {
+        errs = append(errs, ss.SendMsg(&pb.ServiceChainDescriptor{ChainId: i}))
+    }
+    if errs[0] != nil || errs[1] != nil || status.Code(errs[2]) != codes.Aborted || len(rs.sent) != 2 {
+        t.Errorf("stream aborted after 2 messages: sent %d, errors %v", len(rs.sent), errs)
+    }
+
+    // the rule of a chain applies to the messages of the chain, and the default code is UNAVAILABLE
+    rs = &recordingStream{}
+    ss = &faultyServerStream{ServerStream: rs, injector: f, method: "/mygrpc.MyGrpc/GetChainsReqsResps", chainId: -1}
+    if err := ss.SendMsg(&pb.ServiceChainDescriptor{ChainId: 1}); err != nil {
+        t.Errorf("message of chain 1 is not sent: %v", err)
+    }
+    if err := ss.SendMsg(&pb.ServiceChainDescriptor{ChainId: 3}); status.Code(err) != codes.Unavailable || len(rs.sent) != 1 {
+        t.Errorf("second message, of chain 3, sent %d, error %v", len(rs.sent), err)
+    }
+}
+
+func TestValFaultConfig(t *testing.T) {
+    for _, tc := range []struct {
+        rule   *pb.FaultRule
+        ok     bool
+    }{
+        {&pb.FaultRule{Methods: []string{"/mygrpc.MyGrpc/Get*"}, ErrorRate: 1, ErrorCode: int32(codes.Internal)}, true},
+        {&pb.FaultRule{Delay: &pb.FaultDelay{Distribution: pb.DelayDistribution_DELAY_UNIFORM, DelayMs: 10, MaxMs: 10, Fraction: 1}}, true},
+        {&pb.FaultRule{Methods: []string{"/mygrpc.MyGrpc/[Get"}}, false},
+        {&pb.FaultRule{SvcNames: []string{"svc["}}, false},
+        {&pb.FaultRule{ErrorRate: 1.5}, false},
+        {&pb.FaultRule{ErrorRate: -0.1}, false},
+        {&pb.FaultRule{ErrorCode: 17}, false},
+        {&pb.FaultRule{AbortAfter: -1}, false},
+        {&pb.FaultRule{SendDelayMs: -1}, false},
+        {&pb.FaultRule{Delay: &pb.FaultDelay{DelayMs: -1}}, false},
+        {&pb.FaultRule{Delay: &pb.FaultDelay{Fraction: 1.1}}, false},
+        {&pb.FaultRule{Delay: &pb.FaultDelay{Distribution: pb.DelayDistribution_DELAY_UNIFORM, DelayMs: 20, MaxMs: 10}}, false},
+        {&pb.FaultRule{Delay: &pb.FaultDelay{Distribution: 9}}, false},
+    } {
+        err := ValFaultConfig(&pb.FaultConfig{Rules: []*pb.FaultRule{tc.rule}})
+        if (err == nil) != tc.ok {
+            t.Errorf("rule %v: error %v, want ok %v", tc.rule, err, tc.ok)
+        }
+    }
+}
\ No newline at end of file
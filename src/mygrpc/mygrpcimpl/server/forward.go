@@ -0,0 +1,225 @@
+// Chain forwarding mode of the server of mygrpc, in which a service chain is executed
+// by every server of the chain calling the server of the next service
+
+package server
+
+import (
+    "fmt"
+    "net"
+    "os"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+
+    pb "mygrpc/mygrpc"
+    reg "mygrpc/mygrpcimpl/registry"
+    "mygrpc/util/auth"
+    val "mygrpc/util/validate"
+
+    "golang.org/x/net/context"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/metadata"
+)
+
+const (
+    chainPosKey      = "mygrpc-chain-pos"   // metadata key telling the next server the position it serves
+    chainHopsKey     = "mygrpc-chain-hops"   // metadata key counting the hops a service chain went through
+    reasonMisrouted  = "CHAIN_MISROUTED"
+    reasonForward    = "FORWARD_FAILED"
+)
+
+// forwarder of service chains to the servers of the next services. the address of
+// a server is looked up in a static map, else derived from the service name as a dns
+// name with the default port, e.g. svcC:8082
+type Forwarder struct {
+    addrs   map[string]string   // address of the server of each service
+    port    int   // port of the servers addressed by their service name
+    pod     string   // pod, or host, running this server
+    opts    []grpc.DialOption   // options dialing the next servers, including their transport credentials
+
+    mu      sync.Mutex   // guards conns
+    conns   map[string]*grpc.ClientConn   // connections to the next servers, by address
+}
+
+func NewForwarder(addrs map[string]string, port int, opts ...grpc.DialOption) *Forwarder {
+    pod := os.Getenv("POD_NAME")
+    if pod == "" {
+        pod, _ = os.Hostname()
+    }
+    return &Forwarder{addrs: addrs, port: port, pod: pod, opts: opts, conns: make(map[string]*grpc.ClientConn)}
+}
+
+// parse a list of service addresses like 'svcB=10.0.0.2:8082,svcC=svc-c:8082'
+func ParseServiceAddrs(s string) (map[string]string, error) {
+    addrs := make(map[string]string)
+    for _, kv := range strings.Split(s, ",") {
+        kv = strings.TrimSpace(kv)
+        if kv == "" {
+            continue
+        }
+        i := strings.Index(kv, "=")
+        if i <= 0 || i == len(kv) - 1 {
+            return nil, fmt.Errorf("Malformed service address %q, expecting name=host:port", kv)
+        }
+        addrs[kv[:i]] = kv[i + 1:]
+    }
+    return addrs, nil
+}
+
+// return the address of the server of a service
+func (f *Forwarder) addr(name string) string {
+    if a, prs := f.addrs[name]; prs {
+        return a
+    }
+    return net.JoinHostPort(name, strconv.Itoa(f.port))
+}
+
+// return a client of the server at an address. connections are created lazily and
+// shared by all the rpcs
+func (f *Forwarder) client(addr string) (pb.MyGrpcClient, error) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    conn, prs := f.conns[addr]
+    if !prs {
+        var err error
+        conn, err = grpc.Dial(addr, f.opts...)
+        if err != nil {
+            return nil, err
+        }
+        f.conns[addr] = conn
+    }
+    return pb.NewMyGrpcClient(conn), nil
+}
+
+// read an integer from the incoming metadata, ok is false if it's absent
+func incomingInt(ctx context.Context, key string) (n int, ok bool, err error) {
+    md, prs := metadata.FromIncomingContext(ctx)
+    if !prs || len(md.Get(key)) == 0 {
+        return 0, false, nil
+    }
+    n, err = strconv.Atoi(md.Get(key)[0])
+    return n, true, err
+}
+
+// return the index in the chain of the service at a position
+func indexOfPos(sc *pb.ServiceChain, pos int32) int {
+    for i, svc := range sc.GetChain() {
+        if svc.GetSvcPos() == pos {
+            return i
+        }
+    }
+    return -1
+}
+
+// execute a service chain by forwarding. the server serves the position told by the
+// previous server. without a position, the chain enters at this server, which serves
+// the first position if it is the one of its service, else forwards to the first
+// service, so that the chain is always executed from its start. the descriptor of its
+// service and its hop are added to the descriptor returned by the following servers
+func (s *myGrpcServer) forwardChain(ctx context.Context, svcs reg.ServiceLookup, sc *pb.ServiceChain) (*pb.ServiceChainDescriptor, error) {
+    start := time.Now()
+    if err := val.ValServiceChain(sc); err != nil {
+        return nil, err
+    }
+    hops, _, err := incomingInt(ctx, chainHopsKey)
+    if err != nil {
+        return nil, &ServiceError{SvcName: s.svcName, ChainId: sc.GetChainId(), Index: -1, Field: chainHopsKey, Code: codes.InvalidArgument, Reason: reasonForward, Msg: "Malformed hop count in metadata", Err: err}
+    }
+    if hops > val.MaxChainLen {
+        return nil, &ServiceError{SvcName: s.svcName, ChainId: sc.GetChainId(), Index: -1, Field: chainHopsKey, Code: codes.FailedPrecondition, Reason: reasonMisrouted, Msg: fmt.Sprintf("Service chain went through more than %d hops, the forwarding loops", val.MaxChainLen)}
+    }
+
+    var pos int32   // position served by this server, 0 if outside the chain
+    idx := -1   // index in the chain of the service at pos
+    p, told, err := incomingInt(ctx, chainPosKey)
+    switch {
+        case err != nil:
+            return nil, &ServiceError{SvcName: s.svcName, ChainId: sc.GetChainId(), Index: -1, Field: chainPosKey, Code: codes.InvalidArgument, Reason: reasonForward, Msg: "Malformed chain position in metadata", Err: err}
+        case told:
+            pos = int32(p)
+            idx = indexOfPos(sc, pos)
+            if idx < 0 || sc.GetChain()[idx].GetSvcName() != s.svcName {
+                return nil, &ServiceError{SvcName: s.svcName, SvcPos: pos, ChainId: sc.GetChainId(), Index: -1, Field: chainPosKey, Code: codes.FailedPrecondition, Reason: reasonMisrouted, Msg: fmt.Sprintf("Position %d of the chain is not served by service %s", pos, s.svcName)}
+            }
+        default:
+            // serving a later position would leave the earlier ones unresolved
+            if i := indexOfPos(sc, 1); sc.GetChain()[i].GetSvcName() == s.svcName {
+                pos, idx = 1, i
+            }
+    }
+
+    var sd *pb.ServiceDescriptor
+    if idx >= 0 {
+        var prs bool
+        sd, prs = svcs.GetService(s.svcName)
+        if !prs {
+            return nil, &ServiceError{
+                            SvcName: s.svcName,
+                            SvcPos:  pos,
+                            ChainId: sc.GetChainId(),
+                            Index:   idx,
+                            Field:   "svc_name",
+                            Code:    codes.NotFound,
+                            Reason:  reasonServiceNotFound,
+                            Msg:     "No service found",
+                        }
+        }
+        sd.SvcPos = pos
+    }
+
+    var scd *pb.ServiceChainDescriptor
+    if pos < sc.GetChainLen() {
+        scd, err = s.forwardNext(ctx, sc, pos + 1, hops + 1)
+        if err != nil {
+            return nil, err
+        }
+    } else {
+        scd = &pb.ServiceChainDescriptor{
+                  ChainId:    sc.GetChainId(),
+                  ChainLen:   sc.GetChainLen(),
+                  ChainDesc:  make([]*pb.ServiceDescriptor, sc.GetChainLen()),
+              }
+    }
+    if sd != nil {
+        scd.ChainDesc[pos - 1] = sd
+    }
+    hop := &pb.Hop{
+               SvcName:      s.svcName,
+               SvcPos:       pos,
+               Pod:          s.forwarder.pod,
+               StartTimeNs:  start.UnixNano(),
+               ElapsedNs:    int64(time.Since(start)),
+           }
+    scd.Hops = append([]*pb.Hop{hop}, scd.Hops...)
+    return scd, nil
+}
+
+// call the server of the service at position next, and check that the descriptor it
+// returns covers the whole chain from position next
+func (s *myGrpcServer) forwardNext(ctx context.Context, sc *pb.ServiceChain, next int32, hops int) (*pb.ServiceChainDescriptor, error) {
+    idx := indexOfPos(sc, next)
+    name := sc.GetChain()[idx].GetSvcName()
+    addr := s.forwarder.addr(name)
+    client, err := s.forwarder.client(addr)
+    if err != nil {
+        return nil, &ServiceError{SvcName: name, SvcPos: next, ChainId: sc.GetChainId(), Index: idx, Field: "svc_name", Code: codes.Unavailable, Reason: reasonForward, Msg: "Failed to connect to " + addr, Err: err}
+    }
+    md := metadata.Pairs(chainPosKey, strconv.Itoa(int(next)), chainHopsKey, strconv.Itoa(hops))
+    scd, err := client.GetChainReqResp(auth.ForwardToken(metadata.NewOutgoingContext(ctx, md)), sc)
+    if err != nil {
+        myGrpcLogger.Warn("Failed to forward service chain", "chain_id", sc.GetChainId(), "svc_name", name, "addr", addr, "err", err)
+        return nil, err
+    }
+    if len(scd.GetChainDesc()) != int(sc.GetChainLen()) {
+        return nil, &ServiceError{SvcName: name, SvcPos: next, ChainId: sc.GetChainId(), Index: idx, Field: "svc_name", Code: codes.Internal, Reason: reasonForward, Msg: fmt.Sprintf("Server at %s returned %d service descriptors for a chain of %d", addr, len(scd.GetChainDesc()), sc.GetChainLen())}
+    }
+    // a missing descriptor is received as an empty one
+    for i := next - 1; i < sc.GetChainLen(); i++ {
+        if scd.GetChainDesc()[i].GetSvcName() == "" {
+            return nil, &ServiceError{SvcName: name, SvcPos: next, ChainId: sc.GetChainId(), Index: idx, Field: "svc_name", Code: codes.Internal, Reason: reasonForward, Msg: fmt.Sprintf("Server at %s returned no service descriptor for position %d", addr, i + 1)}
+        }
+    }
+    return scd, nil
+}
\ No newline at end of file
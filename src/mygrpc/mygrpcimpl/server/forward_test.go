@@ -0,0 +1,138 @@
+package server
+
+import (
+    "fmt"
+    "net"
+    "strconv"
+    "testing"
+
+    pb "mygrpc/mygrpc"
+    val "mygrpc/util/validate"
+
+    "golang.org/x/net/context"
+    "google.golang.org/genproto/googleapis/rpc/errdetails"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/metadata"
+    "google.golang.org/grpc/status"
+    "google.golang.org/grpc/test/bufconn"
+)
+
+// server of a service returning a descriptor of the right length but without the
+// descriptors of the services, as a broken next server would
+type holeyServer struct {
+    pb.MyGrpcServer
+}
+
+func (holeyServer) GetChainReqResp(ctx context.Context, sc *pb.ServiceChain) (*pb.ServiceChainDescriptor, error) {
+    return &pb.ServiceChainDescriptor{ChainId: sc.GetChainId(), ChainLen: sc.GetChainLen(), ChainDesc: make([]*pb.ServiceDescriptor, sc.GetChainLen())}, nil
+}
+
+// serve svcA, svcB and svcC by forwarding servers over in-memory connections, the
+// address of each server being its service name. holey names the service served by
+// a holeyServer, if any. return a client of each server
+func startForwarders(t *testing.T, holey string) map[string]pb.MyGrpcClient {
+    lis := make(map[string]*bufconn.Listener)
+    dialer := grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+        return lis[addr].DialContext(ctx)
+    })
+    addrs := map[string]string{"svcA": "svcA", "svcB": "svcB", "svcC": "svcC"}
+    clients := make(map[string]pb.MyGrpcClient)
+    for name := range addrs {
+        lis[name] = bufconn.Listen(1 << 20)
+    }
+    for name := range addrs {
+        srv := grpc.NewServer()
+        if name == holey {
+            pb.RegisterMyGrpcServer(srv, holeyServer{})
+        } else {
+            pb.RegisterMyGrpcServer(srv, NewMyGrpcServer(newRegistry(t), name, NewForwarder(addrs, 0, grpc.WithInsecure(), dialer), nil))
+        }
+        go srv.Serve(lis[name])
+        t.Cleanup(srv.Stop)
+        conn, err := grpc.Dial(name, grpc.WithInsecure(), dialer)
+        if err != nil {
+            t.Fatal(err)
+        }
+        t.Cleanup(func() { conn.Close() })
+        clients[name] = pb.NewMyGrpcClient(conn)
+    }
+    return clients
+}
+
+func hopsOf(scd *pb.ServiceChainDescriptor) []string {
+    var hops []string
+    for _, h := range scd.GetHops() {
+        hops = append(hops, h.GetSvcName() + ":" + strconv.Itoa(int(h.GetSvcPos())))
+    }
+    return hops
+}
+
+func TestForwardChain(t *testing.T) {
+    clients := startForwarders(t, "")
+    sc := chainOf(1, "svcA", "svcB", "svcC")
+    for _, tc := range []struct {
+        entry   string
+        hops    string
+    }{
+        // the first service serves its position itself
+        {"svcA", "[svcA:1 svcB:2 svcC:3]"},
+        // a server in the middle of the chain, or outside of it, starts from the first service
+        {"svcB", "[svcB:0 svcA:1 svcB:2 svcC:3]"},
+        {"svcC", "[svcC:0 svcA:1 svcB:2 svcC:3]"},
+    } {
+        scd, err := clients[tc.entry].GetChainReqResp(context.Background(), sc)
+        if err != nil {
+            t.Fatalf("entering at %s: %v", tc.entry, err)
+        }
+        for i, sd := range scd.GetChainDesc() {
+            if sd.GetSvcName() != sc.GetChain()[i].GetSvcName() || sd.GetSvcPos() != int32(i + 1) {
+                t.Errorf("entering at %s: descriptor %d = %v", tc.entry, i, sd)
+            }
+        }
+        if got := fmt.Sprint(hopsOf(scd)); got != tc.hops {
+            t.Errorf("entering at %s: hops = %s, want %s", tc.entry, got, tc.hops)
+        }
+    }
+
+    // a server told its position serves it and forwards to the following ones only
+    ctx := metadata.AppendToOutgoingContext(context.Background(), chainPosKey, "2", chainHopsKey, "1")
+    scd, err := clients["svcB"].GetChainReqResp(ctx, sc)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got := fmt.Sprint(hopsOf(scd)); got != "[svcB:2 svcC:3]" || scd.GetChainDesc()[1].GetSvcName() != "svcB" {
+        t.Errorf("forwarding from position 2: hops = %s, descriptors %v", got, scd.GetChainDesc())
+    }
+
+    for _, tc := range []struct {
+        md      []string
+        code    codes.Code
+        field   string
+    }{
+        {[]string{chainPosKey, "2"}, codes.FailedPrecondition, chainPosKey},
+        {[]string{chainPosKey, "x"}, codes.InvalidArgument, chainPosKey},
+        {[]string{chainHopsKey, strconv.Itoa(val.MaxChainLen + 1)}, codes.FailedPrecondition, chainHopsKey},
+    } {
+        ctx := metadata.AppendToOutgoingContext(context.Background(), tc.md...)
+        _, err := clients["svcC"].GetChainReqResp(ctx, sc)
+        st := status.Convert(err)
+        if st.Code() != tc.code {
+            t.Errorf("metadata %v: code %v, want %v", tc.md, st.Code(), tc.code)
+            continue
+        }
+        for _, d := range st.Details() {
+            if br, ok := d.(*errdetails.BadRequest); ok && br.GetFieldViolations()[0].GetField() != tc.field {
+                t.Errorf("metadata %v: field %s, want %s", tc.md, br.GetFieldViolations()[0].GetField(), tc.field)
+            }
+        }
+    }
+}
+
+func TestForwardChainHoles(t *testing.T) {
+    clients := startForwarders(t, "svcC")
+    _, err := clients["svcA"].GetChainReqResp(context.Background(), chainOf(1, "svcA", "svcB", "svcC"))
+    if status.Code(err) != codes.Internal {
+        t.Errorf("descriptor with holes returned %v, want Internal", err)
+    }
+}
\ No newline at end of file
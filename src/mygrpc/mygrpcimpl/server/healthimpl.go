@@ -0,0 +1,103 @@
+// Health of the server of mygrpc, reported by the standard grpc health service
+
+package server
+
+import (
+    "sync"
+
+    reg "mygrpc/mygrpcimpl/registry"
+
+    "google.golang.org/grpc/health"
+    healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// reporter of the health of the server. the server, i.e. the empty service name, is
+// SERVING once the registry holds some service, and the service of the server once
+// it is registered itself. everything is NOT_SERVING after shutdown
+type HealthReporter struct {
+    hs         *health.Server
+    registry   reg.ServiceRegistry
+    svcName    string   // name of the service providing by the server
+
+    mu         sync.Mutex   // serializes the updates so that shutdown is final
+    shutdown   bool
+}
+
+// create a reporter with everything NOT_SERVING until Start
+func NewHealthReporter(registry reg.ServiceRegistry, name string) *HealthReporter {
+    h := &HealthReporter{hs: health.NewServer(), registry: registry, svcName: name}
+    h.hs.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+    h.hs.SetServingStatus(name, healthpb.HealthCheckResponse_NOT_SERVING)
+    return h
+}
+
+// the health service to register on the grpc server
+func (h *HealthReporter) Server() healthpb.HealthServer {
+    return h.hs
+}
+
+// report the current state of the registry, and follow its modifications if supported
+func (h *HealthReporter) Start() {
+    h.update()
+    if _, ok := h.registry.(reg.Watchable); ok {
+        go h.follow()
+    }
+}
+
+// update the status on every modification of the registry. a watch ended by the
+// registry is started again, after catching up with the current state
+func (h *HealthReporter) follow() {
+    wr := h.registry.(reg.Watchable)
+    for {
+        w, err := wr.Watch(-1, false)
+        if err != nil {
+            myGrpcLogger.Error("Failed to watch the registry for health, status is no longer updated", "err", err)
+            return
+        }
+        h.update()
+        for stop := false; !stop; {
+            select {
+                case <-w.Events():
+                    h.update()
+                case <-w.Done():
+                    stop = true
+            }
+        }
+        w.Stop()
+    }
+}
+
+func (h *HealthReporter) update() {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    if h.shutdown {
+        return
+    }
+    // a view knowing its size spares listing the whole registry on every modification
+    svcs := reg.SnapshotOf(h.registry)
+    var nonEmpty bool
+    if sz, ok := svcs.(reg.Sized); ok {
+        nonEmpty = sz.Len() > 0
+    } else {
+        sds, err := h.registry.ListServices()
+        nonEmpty = err == nil && len(sds) > 0
+    }
+    h.hs.SetServingStatus("", servingStatus(nonEmpty))
+    _, prs := svcs.GetService(h.svcName)
+    h.hs.SetServingStatus(h.svcName, servingStatus(prs))
+}
+
+// set everything NOT_SERVING for good, when the server begins shutting down
+func (h *HealthReporter) Shutdown() {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    h.shutdown = true
+    h.hs.Shutdown()
+}
+
+func servingStatus(serving bool) healthpb.HealthCheckResponse_ServingStatus {
+    if serving {
+        return healthpb.HealthCheckResponse_SERVING
+    }
+    return healthpb.HealthCheckResponse_NOT_SERVING
+}
\ No newline at end of file
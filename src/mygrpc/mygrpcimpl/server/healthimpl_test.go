@@ -0,0 +1,61 @@
+package server
+
+import (
+    "testing"
+    "time"
+
+    pb "mygrpc/mygrpc"
+    reg "mygrpc/mygrpcimpl/registry"
+
+    "golang.org/x/net/context"
+    healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// wait for the statuses of the server and of svcA to be reported
+func waitHealth(t *testing.T, h *HealthReporter, server, svc healthpb.HealthCheckResponse_ServingStatus) {
+    t.Helper()
+    var got [2]healthpb.HealthCheckResponse_ServingStatus
+    for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+        for i, name := range []string{"", "svcA"} {
+            resp, err := h.Server().Check(context.Background(), &healthpb.HealthCheckRequest{Service: name})
+            if err != nil {
+                t.Fatal(err)
+            }
+            got[i] = resp.GetStatus()
+        }
+        if got[0] == server && got[1] == svc {
+            return
+        }
+    }
+    t.Fatalf("statuses = %v, want %v and %v", got, server, svc)
+}
+
+func TestHealthReporter(t *testing.T) {
+    registry, err := reg.NewMemRegistry(nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    h := NewHealthReporter(registry, "svcA")
+    h.Start()
+    serving, notServing := healthpb.HealthCheckResponse_SERVING, healthpb.HealthCheckResponse_NOT_SERVING
+    waitHealth(t, h, notServing, notServing)
+
+    mustPut := func(name string) {
+        if err := registry.PutService(&pb.ServiceDescriptor{SvcName: name}); err != nil {
+            t.Fatal(err)
+        }
+    }
+    mustPut("svcB")
+    waitHealth(t, h, serving, notServing)
+    mustPut("svcA")
+    waitHealth(t, h, serving, serving)
+    if err := registry.ReplaceServices(nil); err != nil {
+        t.Fatal(err)
+    }
+    waitHealth(t, h, notServing, notServing)
+
+    mustPut("svcA")
+    waitHealth(t, h, serving, serving)
+    h.Shutdown()
+    waitHealth(t, h, notServing, notServing)
+}
\ No newline at end of file
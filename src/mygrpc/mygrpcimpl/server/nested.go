@@ -0,0 +1,159 @@
+// Expansion of the service chains nested in others by chain_ref
+
+package server
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+
+    pb "mygrpc/mygrpc"
+    reg "mygrpc/mygrpcimpl/registry"
+    val "mygrpc/util/validate"
+
+    "google.golang.org/grpc/codes"
+)
+
+const (
+    reasonChainCycle     = "CHAIN_CYCLE"
+    reasonChainTooDeep   = "CHAIN_TOO_DEEP"
+    reasonChainTooLong   = "CHAIN_TOO_LONG"
+)
+
+// origin of a service of a flattened chain
+type nestedService struct {
+    index   int   // index of the entry of the requested chain the service is expanded from
+    path    []int32   // ids of the nested chains holding the service, outermost first
+}
+
+// report whether a service chain references other chains
+func hasNestedChains(sc *pb.ServiceChain) bool {
+    for _, svc := range sc.GetChain() {
+        if svc.GetChainRef() != 0 {
+            return true
+        }
+    }
+    return false
+}
+
+// expand the chains referenced by a valid service chain, recursively, into a flat
+// chain of services numbered in order, along with the origin of each of them. stored
+// tells whether the chain is the stored chain of its chain_id, which may then not be
+// referenced by its nested chains. the chain is returned as is with a nil nesting
+// when it references no chain
+func flattenChain(svcs reg.ServiceLookup, sc *pb.ServiceChain, stored bool) (*pb.ServiceChain, []nestedService, error) {
+    if !hasNestedChains(sc) {
+        return sc, nil, nil
+    }
+    chains, _ := svcs.(reg.ChainLookup)
+    f := &flattener{chains: chains, top: sc.GetChainId(), stored: stored, flat: &pb.ServiceChain{ChainId: sc.GetChainId()}}
+    if err := f.expand(sc.GetChain(), nil, -1); err != nil {
+        return nil, nil, err
+    }
+    f.flat.ChainLen = int32(len(f.flat.Chain))
+    return f.flat, f.nesting, nil
+}
+
+type flattener struct {
+    chains    reg.ChainLookup   // stored chains, nil if the registry stores none
+    top       int32   // id of the requested chain
+    stored    bool   // whether the requested chain is the stored chain of top
+    flat      *pb.ServiceChain
+    nesting   []nestedService
+}
+
+// append the services of the entries in the order of their positions. path holds
+// the ids of the chains being expanded, and index the entry of the requested chain
+// they come from, -1 for the entries of the requested chain itself
+func (f *flattener) expand(entries []*pb.Service, path []int32, index int) error {
+    byPos := make([]int, len(entries))
+    for i, svc := range entries {
+        byPos[svc.GetSvcPos() - 1] = i
+    }
+    for _, i := range byPos {
+        svc := entries[i]
+        top := index
+        if top < 0 {
+            top = i
+        }
+        ref := svc.GetChainRef()
+        if ref == 0 {
+            if len(f.flat.Chain) == val.MaxChainLen {
+                return f.error(top, reasonChainTooLong, fmt.Sprintf("Service chain expands to more than %d services", val.MaxChainLen))
+            }
+            f.flat.Chain = append(f.flat.Chain, &pb.Service{SvcName: svc.GetSvcName(), SvcPos: int32(len(f.flat.Chain) + 1)})
+            f.nesting = append(f.nesting, nestedService{index: top, path: path})
+            continue
+        }
+
+        sub := append(path[:len(path):len(path)], ref)
+        // a stored chain is the root of the nesting, so that it may not be referenced
+        // either. the id of an inline chain is only a label given by the client
+        cycle := f.stored && ref == f.top
+        for _, id := range path {
+            cycle = cycle || id == ref
+        }
+        if cycle {
+            return f.error(top, reasonChainCycle, "Nested chains form a cycle " + formatChainPath(f.top, sub))
+        }
+        if len(path) == val.MaxChainDepth {
+            return f.error(top, reasonChainTooDeep, fmt.Sprintf("Nested chains %s are deeper than %d levels", formatChainPath(f.top, sub), val.MaxChainDepth))
+        }
+        var c *pb.StoredChain
+        prs := false
+        if f.chains != nil {
+            c, prs = f.chains.GetChain(ref)
+        }
+        if !prs {
+            return &ServiceError{
+                       ChainId: f.top,
+                       Index:   top,
+                       Field:   "chain_ref",
+                       Code:    codes.NotFound,
+                       Reason:  reasonChainNotFound,
+                       Msg:     "No stored service chain found for " + formatChainPath(f.top, sub),
+                   }
+        }
+        if err := f.expand(c.GetChain(), sub, top); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+func (f *flattener) error(index int, reason, msg string) error {
+    return &ServiceError{ChainId: f.top, Index: index, Field: "chain_ref", Code: codes.InvalidArgument, Reason: reason, Msg: msg}
+}
+
+// format the path of nested chains from the requested chain top, e.g. chain 1 -> 4 -> 5
+func formatChainPath(top int32, path []int32) string {
+    ids := []string{strconv.Itoa(int(top))}
+    for _, id := range path {
+        ids = append(ids, strconv.Itoa(int(id)))
+    }
+    return "chain " + strings.Join(ids, " -> ")
+}
+
+// record in the descriptors of a flattened chain the nested chains their services
+// are expanded from
+func setChainPaths(scd *pb.ServiceChainDescriptor, nesting []nestedService) {
+    for i, sd := range scd.GetChainDesc() {
+        if i < len(nesting) && sd != nil {
+            sd.ChainPath = nesting[i].path
+        }
+    }
+}
+
+// return the part of the cache key of a flattened chain telling its nesting, so that
+// chains of the same services nested differently are kept apart
+func nestingKey(nesting []nestedService) string {
+    var b strings.Builder
+    for _, ns := range nesting {
+        b.WriteByte('/')
+        for _, id := range ns.path {
+            b.WriteString(strconv.Itoa(int(id)))
+            b.WriteByte('.')
+        }
+    }
+    return b.String()
+}
\ No newline at end of file
@@ -0,0 +1,123 @@
+package server
+
+import (
+    "reflect"
+    "testing"
+
+    pb "mygrpc/mygrpc"
+    reg "mygrpc/mygrpcimpl/registry"
+    val "mygrpc/util/validate"
+
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+)
+
+// return a stored chain of services, or of chains for the entries given as ids
+func storedChain(id int32, entries ...interface{}) *pb.StoredChain {
+    c := &pb.StoredChain{ChainId: id}
+    for i, e := range entries {
+        svc := &pb.Service{SvcPos: int32(i + 1)}
+        switch e := e.(type) {
+            case string:
+                svc.SvcName = e
+            case int:
+                svc.ChainRef = int32(e)
+        }
+        c.Chain = append(c.Chain, svc)
+    }
+    return c
+}
+
+func TestNestedChains(t *testing.T) {
+    registry := newRegistry(t)
+    chains := registry.(reg.ChainStore)
+    if err := chains.ReplaceChains([]*pb.StoredChain{
+                  storedChain(10, "svcB", 11),
+                  storedChain(11, "svcC"),
+                  storedChain(20, "svcA", 21),
+                  storedChain(21, 22),
+                  storedChain(22, 20),
+                  storedChain(23, 2),
+                  storedChain(2, "svcB"),
+                  storedChain(30, "svcX"),
+              }); err != nil {
+        t.Fatal(err)
+    }
+    s := NewMyGrpcServer(registry, "svcA", nil, NewChainCache(4))
+
+    // svcA -> (10: svcB -> (11: svcC)) -> svcA, with its entries out of order
+    sc := chainOf(1, "svcA", "", "svcA")
+    sc.Chain[1].ChainRef = 10
+    sc.Chain[0], sc.Chain[2] = sc.Chain[2], sc.Chain[0]
+    for i := 0; i < 2; i++ {
+        scd, err := s.getServiceChainDescriptor(s.snapshot(), sc, false)
+        if err != nil {
+            t.Fatal(err)
+        }
+        var names []string
+        var paths [][]int32
+        for j, sd := range scd.GetChainDesc() {
+            if sd.GetSvcPos() != int32(j + 1) {
+                t.Errorf("service %d at position %d", j, sd.GetSvcPos())
+            }
+            names = append(names, sd.GetSvcName())
+            paths = append(paths, sd.GetChainPath())
+        }
+        wantPaths := [][]int32{nil, {10}, {10, 11}, nil}
+        if !reflect.DeepEqual(names, []string{"svcA", "svcB", "svcC", "svcA"}) || !reflect.DeepEqual(paths, wantPaths) || scd.GetChainLen() != 4 {
+            t.Errorf("flattened chain = %v %v of len %d", names, paths, scd.GetChainLen())
+        }
+    }
+    if st := s.cache.Stats(); st.Hits != 1 {
+        t.Errorf("stats = %+v, want 1 hit", st)
+    }
+
+    // the same chain is expanded anew when a nested chain changes
+    if err := chains.PutChain(storedChain(11, "svcA")); err != nil {
+        t.Fatal(err)
+    }
+    scd, err := s.getServiceChainDescriptor(s.snapshot(), sc, false)
+    if err != nil || scd.GetChainDesc()[2].GetSvcName() != "svcA" {
+        t.Errorf("chain after the update of a nested chain = %v, %v", scd, err)
+    }
+
+    deep := make([]*pb.StoredChain, val.MaxChainDepth + 1)
+    for i := range deep {
+        deep[i] = storedChain(int32(100 + i), 101 + i)
+    }
+    deep[len(deep) - 1] = storedChain(int32(100 + len(deep) - 1), "svcA")
+    for _, c := range deep {
+        if err := chains.PutChain(c); err != nil {
+            t.Fatal(err)
+        }
+    }
+    for _, tc := range []struct {
+        ref      int32
+        code     codes.Code
+        reason   string
+    }{
+        {20, codes.InvalidArgument, reasonChainCycle},
+        {22, codes.InvalidArgument, reasonChainCycle},
+        // the id of an inline chain is no reference, so that it may be the one of a nested chain
+        {23, codes.OK, ""},
+        {100, codes.InvalidArgument, reasonChainTooDeep},
+        {101, codes.OK, ""},
+        {40, codes.NotFound, reasonChainNotFound},
+        {30, codes.NotFound, reasonServiceNotFound},
+    } {
+        sc := chainOf(2, "svcA", "")
+        sc.Chain[1].ChainRef = tc.ref
+        _, err := s.getServiceChainDescriptor(s.snapshot(), sc, false)
+        st := status.Convert(err)
+        if st.Code() != tc.code {
+            t.Errorf("chain referencing %d: code %v, want %v: %v", tc.ref, st.Code(), tc.code, err)
+            continue
+        }
+        if err != nil && err.(*ServiceError).Reason != tc.reason {
+            t.Errorf("chain referencing %d: reason %s, want %s", tc.ref, err.(*ServiceError).Reason, tc.reason)
+        }
+        if err != nil && err.(*ServiceError).Index != 1 {
+            t.Errorf("chain referencing %d: error at entry %d, want 1", tc.ref, err.(*ServiceError).Index)
+        }
+    }
+}
\ No newline at end of file
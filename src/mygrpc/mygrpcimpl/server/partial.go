@@ -0,0 +1,64 @@
+// Partial results mode of the streaming rpcs of the server of mygrpc
+
+package server
+
+import (
+    "strconv"
+
+    pb "mygrpc/mygrpc"
+    reg "mygrpc/mygrpcimpl/registry"
+
+    "golang.org/x/net/context"
+    "google.golang.org/genproto/googleapis/rpc/errdetails"
+    "google.golang.org/grpc/metadata"
+    "google.golang.org/grpc/status"
+)
+
+// report whether the client opts in the partial results mode through the metadata
+func partialResultsRequested(ctx context.Context) bool {
+    md, ok := metadata.FromIncomingContext(ctx)
+    if !ok {
+        return false
+    }
+    for _, v := range md.Get(pb.PartialResultsKey) {
+        if b, _ := strconv.ParseBool(v); b {
+            return true
+        }
+    }
+    return false
+}
+
+// return the result of a failing service chain, carrying the status of the error
+func failedChainDescriptor(sc *pb.ServiceChain, err error) *pb.ServiceChainDescriptor {
+    st := status.Convert(err)
+    cs := &pb.ChainStatus{
+              Code:     int32(st.Code()),
+              Message:  st.Message(),
+          }
+    for _, d := range st.Details() {
+        switch detail := d.(type) {
+            case *errdetails.ErrorInfo:
+                cs.Reason = detail.GetReason()
+            case *errdetails.BadRequest:
+                for _, fv := range detail.GetFieldViolations() {
+                    cs.Violations = append(cs.Violations, &pb.FieldViolation{Field: fv.GetField(), Description: fv.GetDescription()})
+                }
+        }
+    }
+    return &pb.ServiceChainDescriptor{
+               ChainId:   sc.GetChainId(),
+               ChainLen:  sc.GetChainLen(),
+               Status:    cs,
+           }
+}
+
+// return a descriptor of a service chain. in the partial results mode, a failure is
+// returned as the result of the chain instead of an error ending the rpc
+func (s *myGrpcServer) resolveChain(ctx context.Context, svcs reg.ServiceLookup, sc *pb.ServiceChain, partial bool) (*pb.ServiceChainDescriptor, error) {
+    scd, err := s.resolve(ctx, svcs, sc)
+    if err != nil && partial {
+        myGrpcLogger.Debug("Failed to resolve service chain, reported in its result", "chain_id", sc.GetChainId(), "err", err)
+        return failedChainDescriptor(sc, err), nil
+    }
+    return scd, err
+}
\ No newline at end of file
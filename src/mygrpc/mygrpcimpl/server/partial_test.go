@@ -0,0 +1,124 @@
+package server
+
+import (
+    "io"
+    "testing"
+
+    pb "mygrpc/mygrpc"
+
+    "golang.org/x/net/context"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/metadata"
+    "google.golang.org/grpc/status"
+)
+
+func TestPartialResults(t *testing.T) {
+    client, stop := startServer(t, nil)
+    defer stop()
+    badPos := chainOf(3, "svcA", "svcB")
+    badPos.Chain[1].SvcPos = 5
+    chains := []*pb.ServiceChain{chainOf(1, "svcA", "svcB"), chainOf(2, "svcA", "svcX"), badPos, chainOf(4, "svcC")}
+    want := []struct {
+        code    codes.Code
+        field   string
+    }{
+        {codes.OK, ""},
+        {codes.NotFound, "chain[1].svc_name"},
+        {codes.InvalidArgument, "chain[1].svc_pos"},
+        {codes.OK, ""},
+    }
+    check := func(rpc string, results []*pb.ServiceChainDescriptor) {
+        if len(results) != len(chains) {
+            t.Fatalf("%s: %d results, want %d", rpc, len(results), len(chains))
+        }
+        for i, cr := range results {
+            if cr.GetChainId() != chains[i].GetChainId() {
+                t.Errorf("%s: result %d for chain %d", rpc, i, cr.GetChainId())
+            }
+            if want[i].code == codes.OK {
+                if cr.GetStatus() != nil || len(cr.GetChainDesc()) != len(chains[i].GetChain()) {
+                    t.Errorf("%s: chain %d resolved to %v", rpc, cr.GetChainId(), cr)
+                }
+                continue
+            }
+            st := cr.GetStatus()
+            if len(cr.GetChainDesc()) != 0 || codes.Code(st.GetCode()) != want[i].code {
+                t.Errorf("%s: chain %d failed with %v, want %v", rpc, cr.GetChainId(), cr, want[i].code)
+                continue
+            }
+            if len(st.GetViolations()) == 0 || st.GetViolations()[0].GetField() != want[i].field {
+                t.Errorf("%s: chain %d violations %v, want field %s", rpc, cr.GetChainId(), st.GetViolations(), want[i].field)
+            }
+        }
+    }
+    ctx := metadata.AppendToOutgoingContext(context.Background(), pb.PartialResultsKey, "true")
+
+    // server-streaming, opted in by the request as well as by the metadata
+    for _, tc := range []struct {
+        ctx       context.Context
+        partial   bool
+    }{
+        {context.Background(), true},
+        {ctx, false},
+    } {
+        stream, err := client.GetChainsReqResps(tc.ctx, &pb.ServiceChains{Chains: chains, PartialResults: tc.partial})
+        if err != nil {
+            t.Fatal(err)
+        }
+        var results []*pb.ServiceChainDescriptor
+        for {
+            cr, err := stream.Recv()
+            if err == io.EOF {
+                break
+            }
+            if err != nil {
+                t.Fatalf("server-streaming: %v", err)
+            }
+            results = append(results, cr)
+        }
+        check("server-streaming", results)
+    }
+
+    cstream, err := client.GetChainsReqsResp(ctx)
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, sc := range chains {
+        if err := cstream.Send(sc); err != nil {
+            t.Fatal(err)
+        }
+    }
+    crs, err := cstream.CloseAndRecv()
+    if err != nil {
+        t.Fatalf("client-streaming: %v", err)
+    }
+    check("client-streaming", crs.GetChainDescs())
+
+    bstream, err := client.GetChainsReqsResps(ctx)
+    if err != nil {
+        t.Fatal(err)
+    }
+    var results []*pb.ServiceChainDescriptor
+    for _, sc := range chains {
+        if err := bstream.Send(sc); err != nil {
+            t.Fatal(err)
+        }
+        cr, err := bstream.Recv()
+        if err != nil {
+            t.Fatalf("bidirectional streaming: %v", err)
+        }
+        results = append(results, cr)
+    }
+    bstream.CloseSend()
+    check("bidirectional streaming", results)
+
+    // without the mode, the first failing chain ends the rpc
+    bstream, err = client.GetChainsReqsResps(context.Background())
+    if err != nil {
+        t.Fatal(err)
+    }
+    bstream.Send(chains[1])
+    if _, err := bstream.Recv(); status.Code(err) != codes.NotFound {
+        t.Errorf("failing chain without the partial results mode returned %v, want NotFound", err)
+    }
+}
\ No newline at end of file
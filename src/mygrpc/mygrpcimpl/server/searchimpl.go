@@ -0,0 +1,51 @@
+// Implementation of the search rpc of the server of mygrpc
+
+package server
+
+import (
+    "path"
+
+    pb "mygrpc/mygrpc"
+    reg "mygrpc/mygrpcimpl/registry"
+
+    "golang.org/x/net/context"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+)
+
+// the page token is the encoded name of the last service in the previous page, as
+// for ListServices, so that paging stays stable while services are added or removed
+func (s *myGrpcServer) SearchServices(ctx context.Context, req *pb.SearchServicesRequest) (*pb.SearchServicesResponse, error) {
+    sel, err := reg.ParseSelector(req.GetLabelSelector())
+    if err != nil {
+        return nil, status.Error(codes.InvalidArgument, err.Error())
+    }
+    if _, err := path.Match(req.GetName(), ""); err != nil {
+        return nil, status.Errorf(codes.InvalidArgument, "Invalid name pattern %q", req.GetName())
+    }
+    after, err := decodePageToken(req.GetPageToken())
+    if err != nil {
+        return nil, status.Errorf(codes.InvalidArgument, "Invalid page token %q", req.GetPageToken())
+    }
+    size := int(req.GetPageSize())
+    if size <= 0 {
+        size = defaultPageSize
+    }
+    if size > maxPageSize {
+        size = maxPageSize
+    }
+
+    // one more service tells whether there is a next page
+    q := &reg.Query{Selector: sel, Name: req.GetName(), Text: req.GetQuery(), After: after, Limit: size + 1}
+    sds, total, err := reg.Search(s.registry, q)
+    if err != nil {
+        return nil, registryStatus(err)
+    }
+    resp := &pb.SearchServicesResponse{TotalSize: int32(total)}
+    if len(sds) > size {
+        sds = sds[:size]
+        resp.NextPageToken = encodePageToken(sds[size - 1].GetSvcName())
+    }
+    resp.Services = sds
+    return resp, nil
+}
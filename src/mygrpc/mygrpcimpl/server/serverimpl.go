@@ -3,106 +3,237 @@
 package server
 
 import (
-    "encoding/json"
     "fmt"
     "io"
-    "log"
-    "os"
+    "strconv"
     
     pb "mygrpc/mygrpc"
+    reg "mygrpc/mygrpcimpl/registry"
+    "mygrpc/util/logging"
+    val "mygrpc/util/validate"
     
     "golang.org/x/net/context"
+    "google.golang.org/genproto/googleapis/rpc/errdetails"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
 )
 
-var myGrpcLogger = log.New(os.Stderr, "mygrpc_server_", log.LstdFlags|log.Lshortfile)
+const (
+    errDomain                = "mygrpc"   // domain of the ErrorInfo details attached to errors
+    reasonServiceNotFound    = "SERVICE_NOT_FOUND"
+)
+
+var myGrpcLogger = logging.Logger("server")
 
 type myGrpcServer struct {
-    useTestFile   bool   // whether use the testing data from a json file
-    testSvcInfo   map[string]*pb.ServiceDescriptor  // map of service descriptors read from the testing file
+    registry      reg.ServiceRegistry   // registry providing the service descriptors
     svcName       string   // name of the service providing by the server
+    forwarder     *Forwarder   // forwarder of the service chains to the next servers, nil unless in the chain forwarding mode
+    cache         *ChainCache   // cache of the chains resolved locally, nil if disabled
 }
 
 // error type used to raise exceptions when dealing with service info
 type ServiceError struct {
     SvcName   string
+    SvcPos    int32
     ChainId   int32
+    Index     int   // index of the service in the chain of the request, -1 for an error of the whole chain
+    Field     string   // name of the field of the service causing the error
+    Code      codes.Code   // status code reported to clients
+    Reason    string   // reason reported to clients in the error details
     Msg       string
     Err       error    
 }
 
 func (e *ServiceError) Error() string {
-    if e.Err == nil {
-        return fmt.Sprintf("Error for service %s in chain %d: %s", e.SvcName, e.ChainId, e.Msg)
+    msg := e.Msg
+    if e.Err != nil {
+        msg = e.Err.Error()
+    }
+    // errors of a whole chain, such as a missing stored chain, name no service
+    if e.SvcName == "" {
+        return fmt.Sprintf("Error for service chain %d: %s", e.ChainId, msg)
     }
     
-    return fmt.Sprintf("Error for service %s in chain %d: %s", e.SvcName, e.ChainId, e.Err.Error())
+    return fmt.Sprintf("Error for service %s in chain %d: %s", e.SvcName, e.ChainId, msg)
+}
+
+// convert the error into a grpc status carrying a BadRequest naming the offending
+// field and an ErrorInfo with the chain_id, svc_name and svc_pos. the status is
+// picked up by grpc when the error is returned by a handler
+func (e *ServiceError) GRPCStatus() *status.Status {
+    code := e.Code
+    if code == codes.OK {
+        code = codes.Unknown
+    }
+    st := status.New(code, e.Error())
+    field := fmt.Sprintf("chain[%d].%s", e.Index, e.Field)
+    if e.Index < 0 {
+        field = e.Field
+    }
+    br := &errdetails.BadRequest{
+              FieldViolations: []*errdetails.BadRequest_FieldViolation{
+                  {
+                      Field:        field,
+                      Description:  e.Msg,
+                  },
+              },
+          }
+    info := &errdetails.ErrorInfo{
+                Reason:    e.Reason,
+                Domain:    errDomain,
+                Metadata:  map[string]string{
+                               "chain_id":  strconv.Itoa(int(e.ChainId)),
+                               "svc_name":  e.SvcName,
+                               "svc_pos":   strconv.Itoa(int(e.SvcPos)),
+                           },
+            }
+    ds, err := st.WithDetails(br, info)
+    if err != nil {
+        return st
+    }
+    return ds
+}
+
+// create a server resolving the service chains from the registry, or executing them
+// by forwarding between servers if a forwarder is given. the chains resolved locally
+// are cached if a cache is given
+func NewMyGrpcServer(registry reg.ServiceRegistry, name string, forwarder *Forwarder, cache *ChainCache) *myGrpcServer {
+    svcs, err := registry.ListServices()
+    if err == nil {
+        myGrpcLogger.Info("Generate mygrpc server", "svc_name", name, "services", len(svcs), "forwarding", forwarder != nil, "chain_cache", cache != nil)
+    }
+    return &myGrpcServer{registry: registry, svcName: name, forwarder: forwarder, cache: cache}
 }
 
-func NewMyGrpcServer(useTest bool, testData []*pb.ServiceDescriptor, name string) *myGrpcServer {
-    if useTest {
-        jsonStr, _ := json.Marshal(testData)
-        myGrpcLogger.Printf("Generate mygrpc server with testing svc info: \n%s", string(jsonStr))
-        svcMap := make(map[string]*pb.ServiceDescriptor)
-        for _, svc := range testData {
-            svcMap[svc.GetSvcName()] = svc
+// return a descriptor of a service chain, either resolved from the registry or by
+// executing the chain through the servers of its services. a reference to a stored
+// chain is expanded first
+func (s *myGrpcServer) resolve(ctx context.Context, svcs reg.ServiceLookup, sc *pb.ServiceChain) (*pb.ServiceChainDescriptor, error) {
+    stored := val.IsChainRef(sc)
+    sc, err := expandChain(svcs, sc)
+    if err != nil {
+        return nil, err
+    }
+    if s.forwarder != nil {
+        // the nested chains are expanded by the first server, the next ones get a flat chain
+        if err := val.ValServiceChain(sc); err != nil {
+            return nil, err
+        }
+        flat, nesting, err := flattenChain(svcs, sc, stored)
+        if err != nil {
+            return nil, err
+        }
+        scd, err := s.forwardChain(ctx, svcs, flat)
+        if err == nil {
+            setChainPaths(scd, nesting)
         }
-        return &myGrpcServer{useTestFile: useTest, testSvcInfo: svcMap, svcName: name}
+        return scd, err
     }
-    
-    return nil
+    return s.getServiceChainDescriptor(svcs, sc, stored)
 }
 
-// return a descriptor of a service chain
-func (s *myGrpcServer) getServiceChainDescriptor(sc *pb.ServiceChain) (*pb.ServiceChainDescriptor, error) {
-    if s.useTestFile {
-        cd := make([]*pb.ServiceDescriptor, sc.GetChainLen())
-        for _, svc := range sc.GetChain() {
-            sd, prs := s.testSvcInfo[svc.GetSvcName()]
-            if !prs {
-                return nil, &ServiceError{
-                                SvcName: svc.GetSvcName(),
-                                ChainId: sc.GetChainId(),
-                                Msg:     "No service found",
-                                Err:     nil,
-                            }
-            }
-            if svc.GetSvcPos() > sc.GetChainLen() || svc.GetSvcPos() < 1 {
-                return nil, &ServiceError{
-                                SvcName: svc.GetSvcName(),
-                                ChainId: sc.GetChainId(),
-                                Msg:     fmt.Sprintf("Wrong service position %d with chain len %d", svc.GetSvcPos(), sc.GetChainLen()),
-                                Err:     nil,
-                            }
+// return a consistent view of the services, which should be taken once per rpc
+// so that a stream is not affected by the registry changing in the middle of it
+func (s *myGrpcServer) snapshot() reg.ServiceLookup {
+    return reg.SnapshotOf(s.registry)
+}
+
+// return a descriptor of a service chain, which is validated first. the chains it
+// references are expanded recursively into a flat descriptor, whose services record
+// the nested chains they come from. the descriptor may come from the cache, valid as
+// long as the view of the services is of the same revision, and must not be modified.
+// stored tells whether the chain is the stored chain of its chain_id
+func (s *myGrpcServer) getServiceChainDescriptor(svcs reg.ServiceLookup, sc *pb.ServiceChain, stored bool) (*pb.ServiceChainDescriptor, error) {
+    if err := val.ValServiceChain(sc); err != nil {
+        return nil, err
+    }
+    sc, nesting, err := flattenChain(svcs, sc, stored)
+    if err != nil {
+        return nil, err
+    }
+    rv, ok := svcs.(reg.Revisioned)
+    if s.cache == nil || !ok {
+        return lookupServiceChain(svcs, sc, nesting)
+    }
+    // the stored chains are part of the revision, the key holds their nesting which the
+    // descriptor reports in the chain paths
+    key := chainKey(sc) + nestingKey(nesting)
+    if scd, hit := s.cache.get(key, rv.Revision()); hit {
+        return scd, nil
+    }
+    scd, err := lookupServiceChain(svcs, sc, nesting)
+    if err == nil {
+        s.cache.put(key, rv.Revision(), scd)
+    }
+    return scd, err
+}
+
+// return a descriptor of a valid service chain by looking up its services. nesting
+// tells the origin of the services of a flattened chain, nil for a chain of its own
+func lookupServiceChain(svcs reg.ServiceLookup, sc *pb.ServiceChain, nesting []nestedService) (*pb.ServiceChainDescriptor, error) {
+    cd := make([]*pb.ServiceDescriptor, sc.GetChainLen())
+    for i, svc := range sc.GetChain() {
+        sd, prs := svcs.GetService(svc.GetSvcName())
+        if !prs {
+            se := &ServiceError{
+                      SvcName: svc.GetSvcName(),
+                      SvcPos:  svc.GetSvcPos(),
+                      ChainId: sc.GetChainId(),
+                      Index:   i,
+                      Field:   "svc_name",
+                      Code:    codes.NotFound,
+                      Reason:  reasonServiceNotFound,
+                      Msg:     "No service found",
+                      Err:     nil,
+                  }
+            if nesting != nil {
+                se.Index = nesting[i].index
+                se.Field = "chain_ref"
+                se.Msg = "No service found in nested " + formatChainPath(sc.GetChainId(), nesting[i].path)
             }
-            sd.SvcPos = svc.GetSvcPos()
-            cd[sd.SvcPos - 1] = sd
+            return nil, se
         }
-        scd := &pb.ServiceChainDescriptor{
-                   ChainId:    sc.GetChainId(),
-                   ChainLen:   sc.GetChainLen(),
-                   ChainDesc:  cd,
-               }
-        return scd, nil
+        if nesting != nil {
+            sd.ChainPath = nesting[i].path
+        }
+        sd.SvcPos = svc.GetSvcPos()
+        cd[sd.SvcPos - 1] = sd
     }
-    
-    return nil, nil
+    scd := &pb.ServiceChainDescriptor{
+               ChainId:    sc.GetChainId(),
+               ChainLen:   sc.GetChainLen(),
+               ChainDesc:  cd,
+           }
+    return scd, nil
 }
 
 func (s *myGrpcServer) GetChainReqResp(ctx context.Context, sc *pb.ServiceChain) (*pb.ServiceChainDescriptor, error) {
-    jsonStr, _ := json.Marshal(sc)
-    myGrpcLogger.Printf("Received service chain request: \n%s", string(jsonStr))
-    return s.getServiceChainDescriptor(sc)
+    return s.resolve(ctx, s.snapshot(), sc)
 }
 
 func (s *myGrpcServer) GetChainsReqResps(scs *pb.ServiceChains, srv pb.MyGrpc_GetChainsReqRespsServer) error {
-    jsonStr, _ := json.Marshal(scs)
-    myGrpcLogger.Printf("Received service chains request: \n%s", string(jsonStr))
+    partial := scs.GetPartialResults() || partialResultsRequested(srv.Context())
+    batchErr, chainErrs := val.ValServiceChainsEach(scs)
+    if batchErr != nil {
+        return batchErr
+    }
+    if !partial {
+        if err := val.ValServiceChains(scs); err != nil {
+            return err
+        }
+    }
+    svcs := s.snapshot()
     var scd *pb.ServiceChainDescriptor
     var err error
-    for _, sc := range scs.GetChains() {
-        scd, err = s.getServiceChainDescriptor(sc)
-        if err != nil {
-            return err
+    for i, sc := range scs.GetChains() {
+        if chainErrs[i] != nil {
+            scd = failedChainDescriptor(sc, chainErrs[i])
+        } else {
+            scd, err = s.resolveChain(srv.Context(), svcs, sc, partial)
+            if err != nil {
+                return err
+            }
         }
         
         err = srv.Send(scd)
@@ -116,6 +247,8 @@ func (s *myGrpcServer) GetChainsReqResps(scs *pb.ServiceChains, srv pb.MyGrpc_Ge
 
 func (s *myGrpcServer) GetChainsReqsResp(srv pb.MyGrpc_GetChainsReqsRespServer) error {
     scds := &pb.ServiceChainDescriptors{ChainDescs: make([]*pb.ServiceChainDescriptor, 1)}
+    svcs := s.snapshot()
+    partial := partialResultsRequested(srv.Context())
     
     // continuously receiving messages from clients
     var scd *pb.ServiceChainDescriptor
@@ -131,10 +264,7 @@ func (s *myGrpcServer) GetChainsReqsResp(srv pb.MyGrpc_GetChainsReqsRespServer)
             return e
         }
         
-        jsonStr, _ := json.Marshal(sc)
-        myGrpcLogger.Printf("Received service chain request: \n%s", string(jsonStr))
-        
-        scd, err = s.getServiceChainDescriptor(sc);
+        scd, err = s.resolveChain(srv.Context(), svcs, sc, partial);
         if err != nil {
             return err
         }
@@ -144,6 +274,8 @@ func (s *myGrpcServer) GetChainsReqsResp(srv pb.MyGrpc_GetChainsReqsRespServer)
 }
 
 func (s *myGrpcServer) GetChainsReqsResps(srv pb.MyGrpc_GetChainsReqsRespsServer) error {
+    svcs := s.snapshot()
+    partial := partialResultsRequested(srv.Context())
     var scd *pb.ServiceChainDescriptor
     var err error
     for {
@@ -153,13 +285,10 @@ func (s *myGrpcServer) GetChainsReqsResps(srv pb.MyGrpc_GetChainsReqsRespsServer
         }
         
         if e != nil {
-            return err
+            return e
         }
         
-        jsonStr, _ := json.Marshal(sc)
-        myGrpcLogger.Printf("Received service chain request: \n%s", string(jsonStr))
-        
-        scd, err = s.getServiceChainDescriptor(sc);
+        scd, err = s.resolveChain(srv.Context(), svcs, sc, partial);
         if err != nil {
             return err
         }
@@ -0,0 +1,75 @@
+package server
+
+import (
+    "strings"
+    "testing"
+
+    pb "mygrpc/mygrpc"
+
+    "golang.org/x/net/context"
+    "google.golang.org/genproto/googleapis/rpc/errdetails"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+)
+
+// return the details of a status decoded by a client
+func statusDetails(t *testing.T, err error) (*status.Status, *errdetails.BadRequest, *errdetails.ErrorInfo) {
+    st := status.Convert(err)
+    var br *errdetails.BadRequest
+    var info *errdetails.ErrorInfo
+    for _, d := range st.Details() {
+        switch d := d.(type) {
+            case *errdetails.BadRequest:
+                br = d
+            case *errdetails.ErrorInfo:
+                info = d
+            case error:
+                t.Errorf("undecodable detail: %v", d)
+        }
+    }
+    return st, br, info
+}
+
+func TestServiceErrorStatus(t *testing.T) {
+    client := serveRegistry(t, newRegistry(t))
+
+    _, err := client.GetChainReqResp(context.Background(), chainOf(4, "svcA", "svcX"))
+    st, br, info := statusDetails(t, err)
+    if st.Code() != codes.NotFound {
+        t.Fatalf("unknown service returned %v, want NotFound", err)
+    }
+    if br == nil || len(br.GetFieldViolations()) != 1 || br.GetFieldViolations()[0].GetField() != "chain[1].svc_name" {
+        t.Errorf("bad request of the unknown service = %v", br)
+    }
+    want := map[string]string{"chain_id": "4", "svc_name": "svcX", "svc_pos": "2"}
+    if info == nil || info.GetReason() != reasonServiceNotFound || info.GetDomain() != errDomain {
+        t.Fatalf("error info of the unknown service = %v", info)
+    }
+    for k, v := range want {
+        if info.GetMetadata()[k] != v {
+            t.Errorf("error info has %s = %q, want %q", k, info.GetMetadata()[k], v)
+        }
+    }
+
+    sc := chainOf(5, "svcA", "svcB", "svcC")
+    sc.Chain[2].SvcPos = 2
+    _, err = client.GetChainReqResp(context.Background(), sc)
+    st, br, _ = statusDetails(t, err)
+    if st.Code() != codes.InvalidArgument {
+        t.Fatalf("bad position returned %v, want InvalidArgument", err)
+    }
+    if br == nil || len(br.GetFieldViolations()) != 1 {
+        t.Fatalf("bad request of the bad position = %v", br)
+    }
+    fv := br.GetFieldViolations()[0]
+    if fv.GetField() != "chain[2].svc_pos" || !strings.Contains(fv.GetDescription(), "svcC") || !strings.Contains(fv.GetDescription(), "chain 5") {
+        t.Errorf("violation of the bad position = %v", fv)
+    }
+
+    // a chain-level error names no service
+    _, err = client.GetChainReqResp(context.Background(), &pb.ServiceChain{ChainId: 9})
+    st, br, info = statusDetails(t, err)
+    if st.Code() != codes.NotFound || br.GetFieldViolations()[0].GetField() != "chain_id" || info.GetReason() != reasonChainNotFound || info.GetMetadata()["svc_name"] != "" {
+        t.Errorf("missing stored chain returned %v with %v, %v", err, br, info)
+    }
+}
\ No newline at end of file
@@ -0,0 +1,189 @@
+package server
+
+import (
+    "net"
+    "testing"
+    "time"
+
+    pb "mygrpc/mygrpc"
+    reg "mygrpc/mygrpcimpl/registry"
+
+    "golang.org/x/net/context"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+    "google.golang.org/grpc/test/bufconn"
+)
+
+// serve the registry over an in-memory connection for the duration of the test
+func serveRegistry(t *testing.T, registry reg.ServiceRegistry) pb.MyGrpcClient {
+    lis := bufconn.Listen(1 << 20)
+    srv := grpc.NewServer()
+    pb.RegisterMyGrpcServer(srv, NewMyGrpcServer(registry, "svcA", nil, NewChainCache(16)))
+    go srv.Serve(lis)
+    t.Cleanup(srv.Stop)
+    conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+        return lis.DialContext(ctx)
+    }))
+    if err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() { conn.Close() })
+    return pb.NewMyGrpcClient(conn)
+}
+
+func TestParseResumeToken(t *testing.T) {
+    for _, tc := range []struct {
+        token   string
+        rev     int64
+        code    codes.Code
+    }{
+        {"", -1, codes.OK},
+        {resumeToken(0), 0, codes.OK},
+        {resumeToken(42), 42, codes.OK},
+        {"42", 0, codes.InvalidArgument},
+        {tokenEpoch + ".x", 0, codes.InvalidArgument},
+        {tokenEpoch + ".-1", 0, codes.InvalidArgument},
+        {"other.42", 0, codes.FailedPrecondition},
+    } {
+        rev, err := parseResumeToken(tc.token)
+        if status.Code(err) != tc.code || err == nil && rev != tc.rev {
+            t.Errorf("token %q = %d, %v, want %d, %v", tc.token, rev, err, tc.rev, tc.code)
+        }
+    }
+}
+
+func TestWatchServices(t *testing.T) {
+    defer func(d time.Duration) { progressInterval = d }(progressInterval)
+    progressInterval = 10 * time.Millisecond
+    registry := newRegistry(t)
+    client := serveRegistry(t, registry)
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+    stream, err := client.WatchServices(ctx, &pb.WatchServicesRequest{SvcNames: []string{"svcB"}, SendInitial: true})
+    if err != nil {
+        t.Fatal(err)
+    }
+    // the watch is started once the first event is received
+    if ev, err := stream.Recv(); err != nil || ev.GetType() != pb.EventType_ADDED || ev.GetService().GetSvcName() != "svcB" {
+        t.Fatalf("initial event = %v, %v", ev, err)
+    }
+    if err := registry.PutService(&pb.ServiceDescriptor{SvcName: "svcB", SvcDesc: "b2"}); err != nil {
+        t.Fatal(err)
+    }
+    ev, err := stream.Recv()
+    if err != nil || ev.GetType() != pb.EventType_UPDATED || ev.GetService().GetSvcDesc() != "b2" {
+        t.Fatalf("event = %v, %v", ev, err)
+    }
+
+    // the modifications of the other services advance the token by PROGRESS events
+    if err := registry.PutService(&pb.ServiceDescriptor{SvcName: "svcA", SvcDesc: "a2"}); err != nil {
+        t.Fatal(err)
+    }
+    progress, err := stream.Recv()
+    if err != nil || progress.GetType() != pb.EventType_PROGRESS || progress.GetService() != nil {
+        t.Fatalf("event = %v, %v, want PROGRESS", progress, err)
+    }
+    last, _ := parseResumeToken(ev.GetResumeToken())
+    if rev, _ := parseResumeToken(progress.GetResumeToken()); rev != last + 1 {
+        t.Errorf("PROGRESS at revision %d, want %d", rev, last + 1)
+    }
+    cancel()
+
+    // resuming from the PROGRESS event skips the modification of svcA
+    stream, err = client.WatchServices(context.Background(), &pb.WatchServicesRequest{ResumeToken: progress.GetResumeToken()})
+    if err != nil {
+        t.Fatal(err)
+    }
+    // the deletion is replayed if it comes before the watch is resumed
+    if err := registry.DeleteService("svcC"); err != nil {
+        t.Fatal(err)
+    }
+    if ev, err := stream.Recv(); err != nil || ev.GetType() != pb.EventType_DELETED || ev.GetService().GetSvcName() != "svcC" {
+        t.Errorf("event of the resumed watch = %v, %v", ev, err)
+    }
+
+    stream, err = client.WatchServices(context.Background(), &pb.WatchServicesRequest{ResumeToken: "other.1"})
+    if err == nil {
+        _, err = stream.Recv()
+    }
+    if status.Code(err) != codes.FailedPrecondition {
+        t.Errorf("watch resumed with the token of another server = %v, want FailedPrecondition", err)
+    }
+}
+
+func TestWatchChain(t *testing.T) {
+    registry := newRegistry(t)
+    client := serveRegistry(t, registry)
+    stream, err := client.WatchChain(context.Background(), &pb.WatchChainRequest{Chain: chainOf(1, "svcA", "svcB")})
+    if err != nil {
+        t.Fatal(err)
+    }
+    ev, err := stream.Recv()
+    if err != nil || ev.GetType() != pb.EventType_ADDED || ev.GetChainDesc().GetChainDesc()[1].GetSvcDesc() != "b" {
+        t.Fatalf("first event = %v, %v", ev, err)
+    }
+
+    for _, desc := range []string{"b2", "b3", "b4"} {
+        if err := registry.PutService(&pb.ServiceDescriptor{SvcName: "svcB", SvcDesc: desc}); err != nil {
+            t.Fatal(err)
+        }
+        ev, err := stream.Recv()
+        if err != nil {
+            t.Fatal(err)
+        }
+        if got := ev.GetChainDesc().GetChainDesc()[1].GetSvcDesc(); ev.GetType() != pb.EventType_UPDATED || got != desc || ev.GetCause().GetService().GetSvcDesc() != desc {
+            t.Errorf("event = %v, want svcB %s", ev, desc)
+        }
+    }
+    if err := registry.DeleteService("svcB"); err != nil {
+        t.Fatal(err)
+    }
+    if ev, err := stream.Recv(); err != nil || ev.GetChainDesc() != nil || ev.GetError() == "" {
+        t.Errorf("event of the deletion = %v, %v", ev, err)
+    }
+}
+
+func TestWatchStoredChain(t *testing.T) {
+    registry := newRegistry(t)
+    chains := registry.(reg.ChainStore)
+    if err := chains.ReplaceChains([]*pb.StoredChain{storedChain(10, "svcA", 11), storedChain(11, "svcB")}); err != nil {
+        t.Fatal(err)
+    }
+    client := serveRegistry(t, registry)
+    stream, err := client.WatchChain(context.Background(), &pb.WatchChainRequest{Chain: &pb.ServiceChain{ChainId: 10}})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if ev, err := stream.Recv(); err != nil || ev.GetType() != pb.EventType_ADDED || len(ev.GetChainDesc().GetChainDesc()) != 2 {
+        t.Fatalf("first event = %v, %v", ev, err)
+    }
+
+    // the modifications of the nested chains are followed, along with their services
+    if err := chains.PutChain(storedChain(11, "svcC")); err != nil {
+        t.Fatal(err)
+    }
+    ev, err := stream.Recv()
+    if err != nil || ev.GetChainCause().GetChain().GetChainId() != 11 || ev.GetChainDesc().GetChainDesc()[1].GetSvcName() != "svcC" {
+        t.Fatalf("event of the nested chain = %v, %v", ev, err)
+    }
+    if err := registry.PutService(&pb.ServiceDescriptor{SvcName: "svcB", SvcDesc: "b2"}); err != nil {
+        t.Fatal(err)
+    }
+    if err := registry.PutService(&pb.ServiceDescriptor{SvcName: "svcC", SvcDesc: "c2"}); err != nil {
+        t.Fatal(err)
+    }
+    if ev, err := stream.Recv(); err != nil || ev.GetCause().GetService().GetSvcName() != "svcC" {
+        t.Errorf("event of the new nested service = %v, %v", ev, err)
+    }
+    // other chains are not followed
+    if err := chains.PutChain(storedChain(12, "svcA")); err != nil {
+        t.Fatal(err)
+    }
+    if err := chains.DeleteChain(10); err != nil {
+        t.Fatal(err)
+    }
+    if ev, err := stream.Recv(); err != nil || ev.GetChainCause().GetType() != pb.EventType_DELETED || ev.GetChainDesc() != nil || ev.GetError() == "" {
+        t.Errorf("event of the deletion of the chain = %v, %v", ev, err)
+    }
+}
\ No newline at end of file
@@ -0,0 +1,254 @@
+// Implementations of the watch rpcs of the server of mygrpc
+
+package server
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+
+    pb "mygrpc/mygrpc"
+    reg "mygrpc/mygrpcimpl/registry"
+    val "mygrpc/util/validate"
+
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+)
+
+// identifier of this server instance put in resume tokens, since revisions of a
+// registry are only meaningful within the process holding it
+var tokenEpoch = strconv.FormatInt(time.Now().UnixNano(), 36)
+
+// interval of the PROGRESS events of the watches skipping the modifications of the
+// services they do not watch, so that their resume tokens do not fall behind the
+// history of the registry
+var progressInterval = 10 * time.Second
+
+func resumeToken(rev int64) string {
+    return fmt.Sprintf("%s.%d", tokenEpoch, rev)
+}
+
+// return the revision to resume from, or -1 for an empty token
+func parseResumeToken(token string) (int64, error) {
+    if token == "" {
+        return -1, nil
+    }
+    parts := strings.SplitN(token, ".", 2)
+    if len(parts) != 2 {
+        return 0, status.Errorf(codes.InvalidArgument, "Invalid resume token %q", token)
+    }
+    if parts[0] != tokenEpoch {
+        return 0, status.Errorf(codes.FailedPrecondition, "Resume token %q is issued by another server instance, start a new watch", token)
+    }
+    rev, err := strconv.ParseInt(parts[1], 10, 64)
+    if err != nil || rev < 0 {
+        return 0, status.Errorf(codes.InvalidArgument, "Invalid resume token %q", token)
+    }
+    return rev, nil
+}
+
+func (s *myGrpcServer) startWatch(token string, initial bool) (*reg.Watch, error) {
+    wr, ok := s.registry.(reg.Watchable)
+    if !ok {
+        return nil, status.Error(codes.Unimplemented, "The service registry does not support watching")
+    }
+    since, err := parseResumeToken(token)
+    if err != nil {
+        return nil, err
+    }
+    w, err := wr.Watch(since, initial)
+    if err != nil {
+        return nil, status.Errorf(codes.FailedPrecondition, "Failed to resume the watch, start a new one: %v", err)
+    }
+    return w, nil
+}
+
+// status returned when the registry ends a watch, the client may resume it
+func watchEndedStatus(w *reg.Watch) error {
+    return status.Errorf(codes.Aborted, "Watch is ended by the server, resume it with the last token: %v", w.Err())
+}
+
+func serviceEvent(ev reg.Event) *pb.ServiceEvent {
+    return &pb.ServiceEvent{
+               Type:         ev.Type,
+               Service:      ev.Service,
+               ResumeToken:  resumeToken(ev.Revision),
+           }
+}
+
+func (s *myGrpcServer) WatchServices(req *pb.WatchServicesRequest, srv pb.MyGrpc_WatchServicesServer) error {
+    myGrpcLogger.Info("Received watch request of services", "svc_names", req.GetSvcNames(), "resume_token", req.GetResumeToken())
+    w, err := s.startWatch(req.GetResumeToken(), req.GetSendInitial())
+    if err != nil {
+        return err
+    }
+    defer w.Stop()
+    
+    names := make(map[string]bool, len(req.GetSvcNames()))
+    for _, name := range req.GetSvcNames() {
+        names[name] = true
+    }
+    progress := time.NewTicker(progressInterval)
+    defer progress.Stop()
+    skipped := int64(-1)  // revision of the last skipped event, -1 if an event is sent since
+    for {
+        select {
+            case <-srv.Context().Done():
+                return srv.Context().Err()
+            case <-w.Done():
+                return watchEndedStatus(w)
+            case <-progress.C:
+                if skipped < 0 {
+                    continue
+                }
+                if err := srv.Send(&pb.ServiceEvent{Type: pb.EventType_PROGRESS, ResumeToken: resumeToken(skipped)}); err != nil {
+                    return err
+                }
+                skipped = -1
+            case ev := <-w.Events():
+                // the modifications of the stored chains are only followed by WatchChain
+                if ev.Service == nil || (len(names) > 0 && !names[ev.Service.GetSvcName()]) {
+                    skipped = ev.Revision
+                    continue
+                }
+                if err := srv.Send(serviceEvent(ev)); err != nil {
+                    return err
+                }
+                skipped = -1
+        }
+    }
+}
+
+// return an event carrying the descriptor of a service chain, expanded and resolved
+// in svcs, the view of the registry at revision rev, or in the current view, at least
+// as recent as rev, if svcs is nil
+func (s *myGrpcServer) chainEvent(t pb.EventType, sc *pb.ServiceChain, svcs reg.ServiceLookup, rev int64) *pb.ChainEvent {
+    ce := &pb.ChainEvent{
+              Type:         t,
+              ChainId:      sc.GetChainId(),
+              ResumeToken:  resumeToken(rev),
+          }
+    if svcs == nil {
+        svcs = s.snapshot()
+    }
+    stored := val.IsChainRef(sc)
+    var scd *pb.ServiceChainDescriptor
+    esc, err := expandChain(svcs, sc)
+    if err == nil {
+        scd, err = s.getServiceChainDescriptor(svcs, esc, stored)
+    }
+    if err != nil {
+        ce.Error = err.Error()
+    } else {
+        ce.ChainDesc = scd
+    }
+    return ce
+}
+
+// return the names of the services and the ids of the stored chains a service chain
+// depends on, following its references to the chains stored in svcs. the ids of the
+// chains which are not stored are included, so that their creation is noticed
+func chainDeps(svcs reg.ServiceLookup, sc *pb.ServiceChain) (map[string]bool, map[int32]bool) {
+    names := make(map[string]bool)
+    refs := make(map[int32]bool)
+    chains, _ := svcs.(reg.ChainLookup)
+    var walk func(entries []*pb.Service)
+    walk = func(entries []*pb.Service) {
+        for _, svc := range entries {
+            ref := svc.GetChainRef()
+            if ref == 0 {
+                names[svc.GetSvcName()] = true
+                continue
+            }
+            // a chain is walked once, which also ends cycles
+            if refs[ref] {
+                continue
+            }
+            refs[ref] = true
+            if chains == nil {
+                continue
+            }
+            if c, prs := chains.GetChain(ref); prs {
+                walk(c.GetChain())
+            }
+        }
+    }
+    if val.IsChainRef(sc) {
+        walk([]*pb.Service{{ChainRef: sc.GetChainId()}})
+    } else {
+        walk(sc.GetChain())
+    }
+    return names, refs
+}
+
+func (s *myGrpcServer) WatchChain(req *pb.WatchChainRequest, srv pb.MyGrpc_WatchChainServer) error {
+    sc := req.GetChain()
+    if sc == nil {
+        return status.Error(codes.InvalidArgument, "No service chain to watch")
+    }
+    myGrpcLogger.Info("Received watch request of service chain", "chain_id", sc.GetChainId(), "resume_token", req.GetResumeToken())
+    w, err := s.startWatch(req.GetResumeToken(), false)
+    if err != nil {
+        return err
+    }
+    defer w.Stop()
+    start := w.Snapshot()
+    if start == nil {
+        start = s.snapshot()
+    }
+    // a stored chain to watch needs to be found at start, it is reported as an error
+    // of the events once deleted
+    if _, err := expandChain(start, sc); err != nil {
+        return err
+    }
+    
+    if req.GetResumeToken() == "" {
+        if err := srv.Send(s.chainEvent(pb.EventType_ADDED, sc, start, w.Revision())); err != nil {
+            return err
+        }
+    }
+    // the services and the stored chains the chain depends on, which change along
+    // with the definitions of the stored chains
+    names, refs := chainDeps(start, sc)
+    progress := time.NewTicker(progressInterval)
+    defer progress.Stop()
+    skipped := int64(-1)  // revision of the last skipped event, -1 if an event is sent since
+    for {
+        select {
+            case <-srv.Context().Done():
+                return srv.Context().Err()
+            case <-w.Done():
+                return watchEndedStatus(w)
+            case <-progress.C:
+                if skipped < 0 {
+                    continue
+                }
+                if err := srv.Send(&pb.ChainEvent{Type: pb.EventType_PROGRESS, ChainId: sc.GetChainId(), ResumeToken: resumeToken(skipped)}); err != nil {
+                    return err
+                }
+                skipped = -1
+            case ev := <-w.Events():
+                // the registry keeps no view per event, the chain is resolved in the current
+                // view, which may already hold the modifications of the next events
+                var ce *pb.ChainEvent
+                switch {
+                    case ev.Service != nil && names[ev.Service.GetSvcName()]:
+                        ce = s.chainEvent(pb.EventType_UPDATED, sc, nil, ev.Revision)
+                        ce.Cause = serviceEvent(ev)
+                    case ev.Chain != nil && refs[ev.Chain.GetChainId()]:
+                        svcs := s.snapshot()
+                        names, refs = chainDeps(svcs, sc)
+                        ce = s.chainEvent(pb.EventType_UPDATED, sc, svcs, ev.Revision)
+                        ce.ChainCause = &pb.StoredChainEvent{Type: ev.Type, Chain: ev.Chain}
+                    default:
+                        skipped = ev.Revision
+                        continue
+                }
+                if err := srv.Send(ce); err != nil {
+                    return err
+                }
+                skipped = -1
+        }
+    }
+}
\ No newline at end of file
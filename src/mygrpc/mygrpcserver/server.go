@@ -3,59 +3,454 @@
 package main
 
 import (
-    "encoding/json"
+    "crypto"
     "flag"
-    "log"
+    "io"
+    "math"
     "os"
     "fmt"
-    "io/ioutil"
     "net"    
+    "os/signal"
+    "syscall"
+    "time"
     
     pb "mygrpc/mygrpc"
+    reg "mygrpc/mygrpcimpl/registry"
     impl "mygrpc/mygrpcimpl/server"
+    "mygrpc/util/auth"
+    "mygrpc/util/certs"
+    "mygrpc/util/config"
+    "mygrpc/util/logging"
+    "mygrpc/util/metrics"
+    "mygrpc/util/tracing"
     
     "google.golang.org/grpc"
+    healthpb "google.golang.org/grpc/health/grpc_health_v1"
+    "google.golang.org/grpc/keepalive"
+    "google.golang.org/grpc/reflection"
 )
 
 var (
-    // tls              = flag.Bool("tls", false, "Connection uses TLS if true, else plain TCP")
-    useTestFile      = flag.Bool("test_file", true, "Uses the json file containing service info as the data source")
+    useTLS           = flag.Bool("tls", false, "Connection uses TLS if true, else plain TCP")
+    certFile         = flag.String("tls_cert", "", "The TLS certificate file of the server, reloaded when it changes")
+    keyFile          = flag.String("tls_key", "", "The TLS key file of the server, reloaded when it changes")
+    caFile           = flag.String("tls_ca", "", "The CA file verifying the client certificates, and the next servers in the chain forwarding mode")
+    clientAuth       = flag.String("tls_client_auth", "none", "Verification of the client certificates, including 'none', 'optional' (verified if given) and 'require' (mutual TLS)")
+    useTestFile      = flag.Bool("test_file", true, "Uses the json file containing service info as the data source, else starts with an empty in-memory registry")
     svcInfoFile      = flag.String("svc_info_file", "/usr/src/grpc/src/mygrpc/testdata/test_data_server.json", "A json file containing service info for testing")
+    chainsFile       = flag.String("chains_file", "/usr/src/grpc/src/mygrpc/testdata/test_data_chains.json", "A json file of the service chains stored by the server for the requests carrying only a chain_id, loaded with -test_file and imported into the persistent registry when it is seeded. Empty for no stored chain")
+    registryDir      = flag.String("registry_dir", "", "A directory persisting the registry as a snapshot and a write-ahead log, so that registered services survive restarts. Takes precedence over -test_file")
+    registrySeed     = flag.String("registry_seed", "", "A json file of service info imported into the persistent registry when it is empty")
+    compactEvery     = flag.Int("compact_every", reg.DefaultCompactEvery, "The number of modifications logged by the persistent registry before it compacts them into a new snapshot, 0 disables the compaction")
+    reloadInterval   = flag.Int("reload_interval", 5, "The interval in seconds between checks of the json file for changes, 0 disables the checks. The file is also reloaded on SIGHUP")
     svcName          = flag.String("name", "svcA", "The name of the service providing by this server")
     port             = flag.Int("port", 8082, "The server port")
+    forward          = flag.Bool("forward", false, "Executes service chains by forwarding them to the server of the next service, instead of resolving them locally")
+    svcAddrs         = flag.String("svc_addrs", "", "Addresses of the servers of the services in the chain forwarding mode, e.g. 'svcB=10.0.0.2:8082,svcC=svc-c:8082'. Other services are addressed by their name and svc_port")
+    svcPort          = flag.Int("svc_port", 8082, "The port of the servers addressed by their service name in the chain forwarding mode")
+    chainCacheSize   = flag.Int("chain_cache_size", 0, "The maximal number of resolved service chains kept in an LRU cache, invalidated whenever a service changes. 0 disables the cache")
+    trace            = flag.Bool("trace", false, "Traces the rpcs, propagating the trace context in W3C traceparent and B3 headers and logging the trace ids")
+    traceFile        = flag.String("trace_file", "", "A file the spans are appended to as json lines, implies -trace")
+    authPolicy       = flag.String("auth_policy", "", "A json file of the rules authorizing the callers per rpc method and per service name, enabling the authentication by bearer tokens")
+    authAPIKeys      = flag.String("auth_api_keys", "", "A json file of the api keys accepted as bearer tokens")
+    authJWKS         = flag.String("auth_jwks", "", "A JWKS file of the keys verifying the JWTs accepted as bearer tokens")
+    jwtIssuer        = flag.String("jwt_issuer", "", "The issuer required in the JWTs, any if empty")
+    jwtAudience      = flag.String("jwt_audience", "", "The audience required in the JWTs, any if empty")
+    drainTimeout     = flag.Duration("drain_timeout", 25 * time.Second, "The maximal time pending rpcs may take to complete on SIGTERM before the server is stopped by force")
+    keepaliveTime    = flag.Duration("keepalive_time", 2 * time.Hour, "The idle time after which the server pings a client to check the connection")
+    keepaliveTimeout = flag.Duration("keepalive_timeout", 20 * time.Second, "The time the server waits for a ping ack before closing the connection")
+    keepaliveMinTime = flag.Duration("keepalive_min_time", 5 * time.Minute, "The minimal interval between client pings, clients pinging more often are disconnected")
+    permitNoStream   = flag.Bool("keepalive_permit_without_stream", false, "Allows client pings when there is no active stream")
+    maxConnIdle      = flag.Duration("max_connection_idle", 0, "The idle time after which a connection is closed by GOAWAY, 0 for infinity")
+    maxConnAge       = flag.Duration("max_connection_age", 0, "The maximal age of a connection before it is closed by GOAWAY, so that clients reconnect and get rebalanced across replicas. 0 for infinity")
+    maxConnAgeGrace  = flag.Duration("max_connection_age_grace", 0, "The time pending rpcs may take to complete after max_connection_age before the connection is closed by force, 0 for infinity")
+    maxStreams       = flag.Uint("max_concurrent_streams", 0, "The maximal number of concurrent streams per connection, 0 for unlimited")
+    maxRecvMsgSize   = flag.Int("max_recv_msg_size", 4 * 1024 * 1024, "The maximal size in bytes of a received message")
+    maxSendMsgSize   = flag.Int("max_send_msg_size", math.MaxInt32, "The maximal size in bytes of a sent message")
+    windowSize       = flag.Int("initial_window_size", 0, "The initial http/2 window size of a stream in bytes, 0 for the grpc default. Values below 64KiB are ignored")
+    connWindowSize   = flag.Int("initial_conn_window_size", 0, "The initial http/2 window size of a connection in bytes, 0 for the grpc default. Values below 64KiB are ignored")
+    metricsPort      = flag.Int("metrics_port", 9092, "The port of the http endpoint exposing prometheus metrics at /metrics, 0 disables the metrics")
+    logFormat        = flag.String("log_format", "text", "The format of the logs, including 'text' (key=value pairs) and 'json'")
+    logLevel         = flag.String("log_level", "info", "The minimal level of the logs, including 'debug', 'info', 'warn' and 'error'. Stream messages and successful unary rpcs are logged at debug")
+    logPayloads      = flag.String("log_payloads", "off", "Logging of the rpc messages, including 'off', 'full' and 'sample:N' (the messages of one rpc in every N). The svc_desc fields are redacted")
+    admin            = flag.Bool("admin", false, "Enables the MyGrpcAdmin service registering services and storing service chains at runtime. Requires a registry which is not reloaded from its json file, i.e. -registry_dir or -test_file=false")
+    faults           = flag.Bool("faults", false, "Enables the injection of faults into the rpcs of MyGrpc, following the rules set at runtime through the MyGrpcFault service")
+    faultsFile       = flag.String("faults_file", "", "A json file of the initial fault rules in the form of a FaultConfig, implies -faults")
     
-    myGrpcLogger     = log.New(os.Stderr, "mygrpc_server_", log.LstdFlags|log.Lshortfile)
+    myGrpcLogger     = logging.Logger("server")
 )
 
-func main() {
-    
-    flag.Parse()
+// create the registry providing service info according to the flags
+func newRegistry() (reg.ServiceRegistry, error) {
+    if *registryDir != "" {
+        r, err := reg.OpenFileRegistry(*registryDir, *compactEvery)
+        if err != nil {
+            return nil, err
+        }
+        sds, _ := r.ListServices()
+        if len(sds) == 0 && *registrySeed != "" {
+            f, err := os.Open(*registrySeed)
+            if err != nil {
+                r.Close()
+                return nil, err
+            }
+            defer f.Close()
+            if err := r.Import(f, nil); err != nil {
+                r.Close()
+                return nil, err
+            }
+            if cs, _ := r.ListChains(); len(cs) == 0 {
+                if err := loadChains(r); err != nil {
+                    r.Close()
+                    return nil, err
+                }
+            }
+            sds, _ = r.ListServices()
+            myGrpcLogger.Info("Seed the persistent registry", "file", *registrySeed)
+        }
+        cs, _ := r.ListChains()
+        myGrpcLogger.Info("Use the persistent service registry", "dir", *registryDir, "services", len(sds), "chains", len(cs))
+        return r, nil
+    }
     if *useTestFile {
+        myGrpcLogger.Info("Use service info in the json file", "file", *svcInfoFile)
+        r, err := reg.NewJsonFileRegistry(*svcInfoFile)
+        if err != nil {
+            return nil, err
+        }
+        myGrpcLogger.Info("Successfully load service info from the json file", "file", *svcInfoFile)
+        if err := loadChains(r); err != nil {
+            return nil, err
+        }
+        return r, nil
+    }
     
-        myGrpcLogger.Printf("Use service info in the json file at %s", *svcInfoFile)
-        file_data, err := ioutil.ReadFile(*svcInfoFile)
+    myGrpcLogger.Info("Use an empty in-memory service registry")
+    return reg.NewMemRegistry(nil)
+}
+
+// store the service chains of the chains file into the registry, if the file is given
+func loadChains(r reg.ChainStore) error {
+    if *chainsFile == "" {
+        return nil
+    }
+    cs, err := reg.LoadChainFile(*chainsFile)
+    if err != nil {
+        return err
+    }
+    if err := r.ReplaceChains(cs); err != nil {
+        return err
+    }
+    myGrpcLogger.Info("Successfully load service chains from the json file", "file", *chainsFile, "chains", len(cs))
+    return nil
+}
+
+// create the tracer of the rpcs according to the flags, nil if tracing is disabled
+func newTracer() (*tracing.Tracer, error) {
+    if !*trace && *traceFile == "" {
+        return nil, nil
+    }
+    var exporter tracing.Exporter
+    if *traceFile != "" {
+        fe, err := tracing.NewFileExporter(*traceFile)
+        if err != nil {
+            return nil, err
+        }
+        exporter = fe
+        myGrpcLogger.Info("Export spans", "file", *traceFile)
+    }
+    return tracing.NewTracer(*svcName, exporter, myGrpcLogger), nil
+}
+
+// return the options of the connections according to the flags
+func connectionOptions() []grpc.ServerOption {
+    opts := []grpc.ServerOption{
+                grpc.KeepaliveParams(keepalive.ServerParameters{
+                    Time:                   *keepaliveTime,
+                    Timeout:                *keepaliveTimeout,
+                    MaxConnectionIdle:      *maxConnIdle,
+                    MaxConnectionAge:       *maxConnAge,
+                    MaxConnectionAgeGrace:  *maxConnAgeGrace,
+                }),
+                grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+                    MinTime:              *keepaliveMinTime,
+                    PermitWithoutStream:  *permitNoStream,
+                }),
+                grpc.MaxRecvMsgSize(*maxRecvMsgSize),
+                grpc.MaxSendMsgSize(*maxSendMsgSize),
+            }
+    if *maxStreams > 0 {
+        opts = append(opts, grpc.MaxConcurrentStreams(uint32(*maxStreams)))
+    }
+    if *windowSize > 0 {
+        opts = append(opts, grpc.InitialWindowSize(int32(*windowSize)))
+    }
+    if *connWindowSize > 0 {
+        opts = append(opts, grpc.InitialConnWindowSize(int32(*connWindowSize)))
+    }
+    return opts
+}
+
+// create the authorizer of the rpcs according to the flags, nil if no policy is given
+func newAuthorizer() (*auth.Authorizer, error) {
+    if *authPolicy == "" {
+        if *authAPIKeys != "" || *authJWKS != "" {
+            return nil, fmt.Errorf("Tokens are given without a policy authorizing them")
+        }
+        return nil, nil
+    }
+    policy, err := auth.LoadPolicy(*authPolicy)
+    if err != nil {
+        return nil, err
+    }
+    var keys []auth.APIKey
+    if *authAPIKeys != "" {
+        if keys, err = auth.LoadAPIKeys(*authAPIKeys); err != nil {
+            return nil, err
+        }
+    }
+    var jwks map[string]crypto.PublicKey
+    if *authJWKS != "" {
+        if jwks, err = auth.LoadJWKS(*authJWKS); err != nil {
+            return nil, err
+        }
+    }
+    verifier := auth.NewVerifier(keys, jwks)
+    verifier.Issuer, verifier.Audience = *jwtIssuer, *jwtAudience
+    myGrpcLogger.Info("Authorize rpcs", "policy", *authPolicy, "rules", len(policy.Rules), "api_keys", len(keys), "jwt_keys", len(jwks))
+    return auth.NewAuthorizer(verifier, policy, *svcName), nil
+}
+
+// create the injector of the faults according to the flags, nil if disabled
+func newFaultInjector() (*impl.FaultInjector, error) {
+    if !*faults && *faultsFile == "" {
+        return nil, nil
+    }
+    injector := impl.NewFaultInjector(*svcName)
+    if *faultsFile != "" {
+        cfg, err := impl.LoadFaultConfig(*faultsFile)
         if err != nil {
-            myGrpcLogger.Fatalf("Failed to load service info from %s: %v", *svcInfoFile, err) 
+            return nil, err
         }
-        var svc_info []*pb.ServiceDescriptor
-        if err := json.Unmarshal(file_data, &svc_info); err != nil {
-            myGrpcLogger.Fatalf("Failed to unmarshal the service info: %v", err)
+        if err := injector.SetConfig(cfg); err != nil {
+            return nil, err
         }
-        myGrpcLogger.Printf("Successfully load service info from the json file at %s", *svcInfoFile)
-        
-        grpcServer := grpc.NewServer()
-        pb.RegisterMyGrpcServer(grpcServer, impl.NewMyGrpcServer(*useTestFile, svc_info, *svcName))
-        
-        lis, err := net.Listen("tcp", fmt.Sprintf(":%d", *port))
+    }
+    myGrpcLogger.Warn("Fault injection is enabled", "file", *faultsFile)
+    return injector, nil
+}
+
+// registries polling their source file for changes
+type fileWatcher interface {
+    WatchFile(interval time.Duration, stop <-chan struct{}, report func(error))
+}
+
+// reload the registry on SIGHUP and, if supported, whenever its source changes.
+// failures are only logged, the registry keeps serving the previous service info
+func startReloading(registry reg.ServiceRegistry) {
+    r, ok := registry.(reg.Reloader)
+    if !ok {
+        return
+    }
+    report := func(err error) {
         if err != nil {
-            myGrpcLogger.Fatalf("Failed to listen: %v", err)
+            myGrpcLogger.Warn("Failed to reload service info, keep serving the previous one", "err", err)
+            return
+        }
+        myGrpcLogger.Info("Successfully reload service info")
+    }
+    
+    hup := make(chan os.Signal, 1)
+    signal.Notify(hup, syscall.SIGHUP)
+    go func() {
+        for range hup {
+            myGrpcLogger.Info("Received SIGHUP, reloading service info")
+            report(r.Reload())
         }
+    }()
+    
+    if w, ok := registry.(fileWatcher); ok && *reloadInterval > 0 {
+        go w.WatchFile(time.Duration(*reloadInterval * int(time.Second)), nil, report)
+    }
+}
+
+// on SIGTERM or SIGINT, report NOT_SERVING and stop the server gracefully: new rpcs
+// are refused, GOAWAY is sent to the clients and the pending rpcs may complete until
+// the drain timeout, or a second signal, after which the server is stopped by force.
+// the returned channel is closed once the server is stopped
+func stopOnSignal(grpcServer *grpc.Server, healthReporter *impl.HealthReporter, tracker *impl.RpcTracker) <-chan struct{} {
+    term := make(chan os.Signal, 2)
+    signal.Notify(term, syscall.SIGTERM, syscall.SIGINT)
+    done := make(chan struct{})
+    go func() {
+        defer close(done)
+        sig := <-term
+        healthReporter.Shutdown()
+        unary, streams, desc := tracker.Open()
+        myGrpcLogger.Info("Received signal, draining rpcs", "signal", sig.String(), "unary", unary, "streams", streams, "timeout", *drainTimeout, "open", desc)
         
-        myGrpcLogger.Printf("Starting MyGrpc grpc server at port %d", *port)
-        grpcServer.Serve(lis)
+        start := time.Now()
+        stopped := make(chan struct{})
+        go func() {
+            grpcServer.GracefulStop()
+            close(stopped)
+        }()
+        select {
+            case <-stopped:
+                myGrpcLogger.Info("Drained all rpcs", "duration", time.Since(start))
+                return
+            case <-time.After(*drainTimeout):
+                myGrpcLogger.Warn("Drain timeout expired")
+            case sig = <-term:
+                myGrpcLogger.Warn("Received signal again", "signal", sig.String())
+        }
+        unary, streams, desc = tracker.Open()
+        myGrpcLogger.Warn("Force stopping, cutting off the rpcs still open", "duration", time.Since(start), "unary", unary, "streams", streams, "open", desc)
+        grpcServer.Stop()
+        <-stopped
+    }()
+    return done
+}
+
+func main() {
+    
+    cfg, err := config.Load(flag.CommandLine, os.Args[1:])
+    if err != nil {
+        logging.Fatal(myGrpcLogger, "Failed to load the configuration", "err", err)
+    }
+    if cfg.PrintRequested() {
+        cfg.Print(os.Stdout)
+        return
+    }
+    if err := logging.Setup(os.Stderr, *logFormat, *logLevel); err != nil {
+        logging.Fatal(myGrpcLogger, "Failed to set up the logging", "err", err)
+    }
+    if cfg.File != "" {
+        myGrpcLogger.Info("Use the configuration file", "file", cfg.File)
+    }
+    payloads, err := logging.ParsePayloads(*logPayloads)
+    if err != nil {
+        logging.Fatal(myGrpcLogger, "Failed to set up the logging", "err", err)
+    }
+    registry, err := newRegistry()
+    if err != nil {
+        logging.Fatal(myGrpcLogger, "Failed to create the service registry", "err", err)
+    }
+    // a reload replaces the whole registry, which would silently discard the registrations
+    if _, ok := registry.(reg.Reloader); ok && *admin {
+        logging.Fatal(myGrpcLogger, "The admin service cannot modify a registry reloaded from its json file, use -registry_dir or -test_file=false", "svc_info_file", *svcInfoFile)
+    }
+    startReloading(registry)
+    
+    tracer, err := newTracer()
+    if err != nil {
+        logging.Fatal(myGrpcLogger, "Failed to create the tracer", "err", err)
+    }
+    // interceptors of the server, the first one being the outermost
+    tracker := impl.NewRpcTracker()
+    unaryInts := []grpc.UnaryServerInterceptor{tracker.UnaryServerInterceptor()}
+    streamInts := []grpc.StreamServerInterceptor{tracker.StreamServerInterceptor()}
+    serverOpts := connectionOptions()
+    dialOpts := []grpc.DialOption{grpc.WithInsecure()}
+    if *useTLS {
+        files := certs.Files{CertFile: *certFile, KeyFile: *keyFile, CAFile: *caFile, ClientAuth: *clientAuth}
+        serverTLS, err := certs.NewReloader(files, true)
+        if err != nil {
+            logging.Fatal(myGrpcLogger, "Failed to load the TLS certificates", "err", err)
+        }
+        // the next servers are called with the certificate of this server
+        files.ClientAuth = ""
+        clientTLS, err := certs.NewReloader(files, false)
+        if err != nil {
+            logging.Fatal(myGrpcLogger, "Failed to load the TLS certificates", "err", err)
+        }
+        serverOpts = append(serverOpts, grpc.Creds(serverTLS.TransportCredentials()))
+        dialOpts = []grpc.DialOption{grpc.WithTransportCredentials(clientTLS.TransportCredentials())}
+        myGrpcLogger.Info("Use TLS", "client_auth", *clientAuth)
+    }
+    if tracer != nil {
+        unaryInts = append(unaryInts, tracer.UnaryServerInterceptor())
+        streamInts = append(streamInts, tracer.StreamServerInterceptor())
+        dialOpts = append(dialOpts, grpc.WithUnaryInterceptor(tracer.UnaryClientInterceptor()), grpc.WithStreamInterceptor(tracer.StreamClientInterceptor()))
+    }
+    // inside the tracing so that the logs carry the trace ids
+    unaryInts = append(unaryInts, logging.UnaryServerInterceptor(myGrpcLogger, payloads))
+    streamInts = append(streamInts, logging.StreamServerInterceptor(myGrpcLogger, payloads))
+    chainCache := impl.NewChainCache(*chainCacheSize)
+    if *metricsPort > 0 {
+        serverMetrics := metrics.NewServerMetrics(*svcName)
+        if chainCache != nil {
+            serverMetrics.RegisterCache("chain", chainCache.Stats)
+        }
+        unaryInts = append(unaryInts, serverMetrics.UnaryServerInterceptor())
+        streamInts = append(streamInts, serverMetrics.StreamServerInterceptor())
+        go func() {
+            myGrpcLogger.Info("Serving metrics", "port", *metricsPort)
+            if err := serverMetrics.ListenAndServe(fmt.Sprintf(":%d", *metricsPort)); err != nil {
+                logging.Fatal(myGrpcLogger, "Failed to serve metrics", "err", err)
+            }
+        }()
+    }
+    authorizer, err := newAuthorizer()
+    if err != nil {
+        logging.Fatal(myGrpcLogger, "Failed to set up the authorization", "err", err)
+    }
+    if authorizer != nil {
+        unaryInts = append(unaryInts, authorizer.UnaryServerInterceptor())
+        streamInts = append(streamInts, authorizer.StreamServerInterceptor())
+    }
+    // innermost so that the injected faults are logged and counted as the others
+    faultInjector, err := newFaultInjector()
+    if err != nil {
+        logging.Fatal(myGrpcLogger, "Failed to set up the fault injection", "err", err)
+    }
+    if faultInjector != nil {
+        unaryInts = append(unaryInts, faultInjector.UnaryServerInterceptor())
+        streamInts = append(streamInts, faultInjector.StreamServerInterceptor())
+    }
+    serverOpts = append(serverOpts, grpc.ChainUnaryInterceptor(unaryInts...), grpc.ChainStreamInterceptor(streamInts...))
     
+    var forwarder *impl.Forwarder
+    if *forward {
+        addrs, err := impl.ParseServiceAddrs(*svcAddrs)
+        if err != nil {
+            logging.Fatal(myGrpcLogger, "Invalid service addresses", "err", err)
+        }
+        forwarder = impl.NewForwarder(addrs, *svcPort, dialOpts...)
+        myGrpcLogger.Info("Forward service chains to the next servers", "svc_name", *svcName)
+    }
+    
+    grpcServer := grpc.NewServer(serverOpts...)
+    pb.RegisterMyGrpcServer(grpcServer, impl.NewMyGrpcServer(registry, *svcName, forwarder, chainCache))
+    if *admin {
+        pb.RegisterMyGrpcAdminServer(grpcServer, impl.NewMyGrpcAdminServer(registry))
+        myGrpcLogger.Info("Admin service is enabled")
+    }
+    if faultInjector != nil {
+        pb.RegisterMyGrpcFaultServer(grpcServer, impl.NewMyGrpcFaultServer(faultInjector))
     }
+    healthReporter := impl.NewHealthReporter(registry, *svcName)
+    healthpb.RegisterHealthServer(grpcServer, healthReporter.Server())
+    healthReporter.Start()
+    reflection.Register(grpcServer)
+    stopped := stopOnSignal(grpcServer, healthReporter, tracker)
     
-    myGrpcLogger.Fatal("Current implementation only support loading service info from json file!")
+    lis, err := net.Listen("tcp", fmt.Sprintf(":%d", *port))
+    if err != nil {
+        logging.Fatal(myGrpcLogger, "Failed to listen", "err", err)
+    }
+    
+    myGrpcLogger.Info("Starting MyGrpc grpc server", "port", *port)
+    if err := grpcServer.Serve(lis); err != nil {
+        logging.Fatal(myGrpcLogger, "Failed to serve", "err", err)
+    }
+    // serve returns as soon as the server starts stopping
+    <-stopped
+    if c, ok := registry.(io.Closer); ok {
+        if err := c.Close(); err != nil {
+            myGrpcLogger.Warn("Failed to close the service registry", "err", err)
+        }
+    }
+    myGrpcLogger.Info("MyGrpc grpc server stopped")
     
 }
\ No newline at end of file
@@ -0,0 +1,109 @@
+package main
+
+import (
+    "net"
+    "os"
+    "syscall"
+    "testing"
+    "time"
+
+    pb "mygrpc/mygrpc"
+    reg "mygrpc/mygrpcimpl/registry"
+    impl "mygrpc/mygrpcimpl/server"
+
+    "golang.org/x/net/context"
+    "google.golang.org/grpc"
+    healthpb "google.golang.org/grpc/health/grpc_health_v1"
+    "google.golang.org/grpc/test/bufconn"
+)
+
+// serve MyGrpc with a tracker of its rpcs over an in-memory connection
+func serve(t *testing.T) (*grpc.Server, *impl.HealthReporter, *impl.RpcTracker, pb.MyGrpcClient) {
+    registry, err := reg.NewMemRegistry([]*pb.ServiceDescriptor{{SvcName: "svcA"}})
+    if err != nil {
+        t.Fatal(err)
+    }
+    tracker := impl.NewRpcTracker()
+    srv := grpc.NewServer(grpc.ChainUnaryInterceptor(tracker.UnaryServerInterceptor()), grpc.ChainStreamInterceptor(tracker.StreamServerInterceptor()))
+    pb.RegisterMyGrpcServer(srv, impl.NewMyGrpcServer(registry, "svcA", nil, nil))
+    healthReporter := impl.NewHealthReporter(registry, "svcA")
+    healthReporter.Start()
+    lis := bufconn.Listen(1 << 20)
+    go srv.Serve(lis)
+    t.Cleanup(srv.Stop)
+    conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+        return lis.DialContext(ctx)
+    }))
+    if err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() { conn.Close() })
+    return srv, healthReporter, tracker, pb.NewMyGrpcClient(conn)
+}
+
+// send SIGTERM to the process and wait for the server to be stopped
+func terminate(t *testing.T, stopped <-chan struct{}) time.Duration {
+    start := time.Now()
+    if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+        t.Fatal(err)
+    }
+    select {
+        case <-stopped:
+            return time.Since(start)
+        case <-time.After(10 * time.Second):
+            t.Fatal("server is not stopped")
+            return 0
+    }
+}
+
+func TestStopOnSignal(t *testing.T) {
+    defer func(d time.Duration) { *drainTimeout = d }(*drainTimeout)
+    *drainTimeout = 200 * time.Millisecond
+
+    srv, healthReporter, tracker, client := serve(t)
+    stream, err := client.WatchServices(context.Background(), &pb.WatchServicesRequest{SendInitial: true})
+    if err == nil {
+        _, err = stream.Recv()
+    }
+    if err != nil {
+        t.Fatal(err)
+    }
+    if unary, streams, _ := tracker.Open(); unary != 0 || streams != 1 {
+        t.Fatalf("open rpcs = %d unary, %d streams, want the watch", unary, streams)
+    }
+
+    // the watch never ends by itself, it is cut off once the drain times out
+    took := terminate(t, stopOnSignal(srv, healthReporter, tracker))
+    if took < *drainTimeout {
+        t.Errorf("server stopped after %v, before the drain timeout", took)
+    }
+    if _, err := stream.Recv(); err == nil {
+        t.Errorf("watch is still open")
+    }
+    resp, err := healthReporter.Server().Check(context.Background(), &healthpb.HealthCheckRequest{})
+    if err != nil || resp.GetStatus() != healthpb.HealthCheckResponse_NOT_SERVING {
+        t.Errorf("health after the signal = %v, %v", resp, err)
+    }
+    for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+        if _, streams, _ := tracker.Open(); streams == 0 {
+            break
+        }
+        if time.Now().After(deadline) {
+            t.Fatal("the watch is still tracked after the server is stopped")
+        }
+    }
+}
+
+func TestStopOnSignalDrained(t *testing.T) {
+    defer func(d time.Duration) { *drainTimeout = d }(*drainTimeout)
+    *drainTimeout = time.Minute
+
+    // without open rpc the server stops right away
+    srv, healthReporter, tracker, client := serve(t)
+    if _, err := client.GetChainReqResp(context.Background(), &pb.ServiceChain{ChainId: 1, ChainLen: 1, Chain: []*pb.Service{{SvcName: "svcA", SvcPos: 1}}}); err != nil {
+        t.Fatal(err)
+    }
+    if took := terminate(t, stopOnSignal(srv, healthReporter, tracker)); took > 5 * time.Second {
+        t.Errorf("drained server stopped after %v", took)
+    }
+}
\ No newline at end of file
@@ -6,23 +6,50 @@ import json
 
 server_file_path = '../testdata/test_data_server.json'
 client_file_path = '../testdata/test_data_client.json'
+chains_file_path = '../testdata/test_data_chains.json'
 
 svc_list = ['A', 'B', 'C', 'D']
 svc_info_server = []
 
+# version, endpoints, labels, owner and times of the services, the labels being the
+# ones searched by SearchServices. a service without them is kept minimal
+svc_meta = {
+    'A': dict(version='1.4.2',
+              endpoints=['svc-a-0.svc-a:8082', 'svc-a-1.svc-a:8082'],
+              labels={'tier': 'frontend', 'env': 'test'},
+              owner='team-edge',
+              created_at='2024-01-15T09:30:00Z',
+              updated_at='2024-03-02T17:45:00Z'),
+    'B': dict(version='2.0.0',
+              endpoints=['svc-b:8082'],
+              labels={'tier': 'backend', 'env': 'test'},
+              owner='team-payments',
+              created_at='2024-01-15T09:30:00Z',
+              updated_at='2024-01-15T09:30:00Z'),
+    'C': dict(version='0.9.1',
+              endpoints=['svc-c:8082'],
+              labels={'tier': 'backend', 'env': 'test'},
+              owner='team-payments',
+              created_at='2024-02-01T12:00:00Z',
+              updated_at='2024-02-20T08:15:00Z'),
+}
+
 for l in svc_list:
-    svc_info_server.append(dict(svc_name='svc'+l,
-                                svc_desc='This is service '+l,
-                                svc_pos=0))
+    svc = dict(svc_name='svc'+l,
+               svc_desc='This is service '+l,
+               svc_pos=0)
+    svc.update(svc_meta.get(l, {}))
+    svc_info_server.append(svc)
 
 svc_chain_list = [[0, 1, 2], [1, 3, 2], [0, 2, 3, 1]]
-svc_chain_client = []
+svc_chain_names = ['checkout', 'inventory', 'reporting']
+svc_chain_server = []
 
 i = 0
 for c in svc_chain_list:
     sc = dict()
     sc['chain_id'] = i + 1
-    sc['chain_len'] = len(c)
+    sc['name'] = svc_chain_names[i]
     sc['chain'] = []
     i = i + 1
     j = 0
@@ -30,10 +57,16 @@ for c in svc_chain_list:
         sc['chain'].append(dict(svc_name='svc'+svc_list[s],
                                 svc_pos=j+1))
         j = j + 1
-    svc_chain_client.append(sc)
+    svc_chain_server.append(sc)
+
+# the client only refers to the chains stored by the server by their ids
+svc_chain_client = [sc['chain_id'] for sc in svc_chain_server]
 
 with open(server_file_path, 'w') as sf:
     json.dump(svc_info_server, sf, indent=4)
 
+with open(chains_file_path, 'w') as chf:
+    json.dump(svc_chain_server, chf, indent=4)
+
 with open(client_file_path, 'w') as cf:
     json.dump(svc_chain_client, cf, indent=4)
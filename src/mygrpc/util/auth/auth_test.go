@@ -0,0 +1,125 @@
+package auth
+
+import (
+    "crypto"
+    "crypto/ecdsa"
+    "crypto/elliptic"
+    "crypto/rand"
+    "crypto/rsa"
+    "crypto/sha256"
+    "encoding/base64"
+    "encoding/json"
+    "testing"
+    "time"
+
+    "golang.org/x/net/context"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/metadata"
+    "google.golang.org/grpc/status"
+)
+
+func segment(v interface{}) string {
+    b, _ := json.Marshal(v)
+    return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// sign a JWT with an RSA or EC key
+func sign(t *testing.T, key crypto.Signer, kid string, claims map[string]interface{}) string {
+    alg := "RS256"
+    if _, ok := key.(*ecdsa.PrivateKey); ok {
+        alg = "ES256"
+    }
+    signed := segment(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"}) + "." + segment(claims)
+    digest := sha256.Sum256([]byte(signed))
+    var sig []byte
+    switch k := key.(type) {
+        case *rsa.PrivateKey:
+            var err error
+            if sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:]); err != nil {
+                t.Fatal(err)
+            }
+        case *ecdsa.PrivateKey:
+            r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+            if err != nil {
+                t.Fatal(err)
+            }
+            sig = make([]byte, 64)
+            r.FillBytes(sig[:32])
+            s.FillBytes(sig[32:])
+    }
+    return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestVerify(t *testing.T) {
+    rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+    ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+    other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+    v := NewVerifier([]APIKey{{Key: "k1", Subject: "loadtest"}},
+                     map[string]crypto.PublicKey{"rsa": &rsaKey.PublicKey, "ec": &ecKey.PublicKey})
+    v.Audience = "mygrpc"
+    exp := time.Now().Add(time.Hour).Unix()
+
+    cases := []struct {
+        token   string
+        sub     string   // expected subject, empty if rejected
+    }{
+        {"k1", "loadtest"},
+        {"k2", ""},
+        {sign(t, rsaKey, "rsa", map[string]interface{}{"sub": "alice", "aud": "mygrpc", "exp": exp}), "alice"},
+        {sign(t, ecKey, "ec", map[string]interface{}{"sub": "bob", "aud": []string{"x", "mygrpc"}, "exp": exp}), "bob"},
+        {sign(t, ecKey, "ec", map[string]interface{}{"sub": "bob", "aud": "mygrpc", "exp": time.Now().Add(-time.Minute).Unix()}), ""},
+        {sign(t, ecKey, "ec", map[string]interface{}{"sub": "bob", "aud": "other", "exp": exp}), ""},
+        {sign(t, other, "ec", map[string]interface{}{"sub": "eve", "aud": "mygrpc", "exp": exp}), ""},
+        {sign(t, rsaKey, "ec", map[string]interface{}{"sub": "eve", "aud": "mygrpc", "exp": exp}), ""},
+    }
+    for i, c := range cases {
+        sub, err := v.Verify(c.token)
+        if c.sub == "" && err == nil {
+            t.Errorf("case %d: token is accepted as %s", i, sub)
+        }
+        if c.sub != "" && (err != nil || sub != c.sub) {
+            t.Errorf("case %d: got %q, %v, want %s", i, sub, err, c.sub)
+        }
+    }
+}
+
+func TestAuthorize(t *testing.T) {
+    policy := &Policy{Rules: []Rule{
+                  {Methods: []string{"/grpc.health.v1.Health/*"}, Public: true},
+                  {Methods: []string{"/mygrpc.MyGrpcAdmin/*"}, Subjects: []string{"admin"}},
+                  {Methods: []string{"/mygrpc.MyGrpc/*"}, Services: []string{"svcA"}, Subjects: []string{"*"}},
+              }}
+    v := NewVerifier([]APIKey{{Key: "k-admin", Subject: "admin"}, {Key: "k-load", Subject: "loadtest"}}, nil)
+    a := NewAuthorizer(v, policy, "svcA")
+    b := NewAuthorizer(v, policy, "svcB")
+
+    withToken := func(token string) context.Context {
+        if token == "" {
+            return context.Background()
+        }
+        return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer " + token))
+    }
+    cases := []struct {
+        a        *Authorizer
+        method   string
+        token    string
+        code     codes.Code
+    }{
+        {a, "/grpc.health.v1.Health/Check", "", codes.OK},
+        {a, "/mygrpc.MyGrpc/GetChainReqResp", "", codes.Unauthenticated},
+        {a, "/mygrpc.MyGrpc/GetChainReqResp", "bad", codes.Unauthenticated},
+        {a, "/mygrpc.MyGrpc/GetChainReqResp", "k-load", codes.OK},
+        {b, "/mygrpc.MyGrpc/GetChainReqResp", "k-load", codes.PermissionDenied},
+        {a, "/mygrpc.MyGrpcAdmin/RegisterService", "k-load", codes.PermissionDenied},
+        {a, "/mygrpc.MyGrpcAdmin/RegisterService", "k-admin", codes.OK},
+    }
+    for i, c := range cases {
+        ctx, err := c.a.authorize(withToken(c.token), c.method)
+        if status.Code(err) != c.code {
+            t.Errorf("case %d: got %v, want %s", i, err, c.code)
+        }
+        if err == nil && c.token != "" && SubjectFromContext(ctx) == "" {
+            t.Errorf("case %d: no subject in the context", i)
+        }
+    }
+}
\ No newline at end of file
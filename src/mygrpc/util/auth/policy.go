@@ -0,0 +1,70 @@
+// Authorization of the callers of mygrpc per rpc method and per service name
+
+package auth
+
+import (
+    "encoding/json"
+    "fmt"
+    "io/ioutil"
+    "path"
+)
+
+// a rule of a policy. methods, services and subjects are glob patterns, e.g.
+// /mygrpc.MyGrpc/* or svc*
+type Rule struct {
+    Methods    []string   `json:"methods"`   // full rpc methods the rule applies to
+    Services   []string   `json:"services"`   // names of the services of the servers the rule applies to, all if empty
+    Subjects   []string   `json:"subjects"`   // callers allowed, '*' for any authenticated caller
+    Public     bool       `json:"public"`   // whether callers are allowed without token, e.g. for health checks
+}
+
+// rules authorizing the rpcs, the first rule applying to an rpc deciding it. rpcs
+// no rule applies to are denied
+type Policy struct {
+    Rules   []Rule   `json:"rules"`
+}
+
+// read a policy from a json file, {"rules": [...]}
+func LoadPolicy(p string) (*Policy, error) {
+    data, err := ioutil.ReadFile(p)
+    if err != nil {
+        return nil, err
+    }
+    var policy Policy
+    if err := json.Unmarshal(data, &policy); err != nil {
+        return nil, fmt.Errorf("Failed to unmarshal the policy from %s: %v", p, err)
+    }
+    for i, r := range policy.Rules {
+        if len(r.Methods) == 0 {
+            return nil, fmt.Errorf("Rule %d in %s has no method", i, p)
+        }
+        for _, pats := range [][]string{r.Methods, r.Services, r.Subjects} {
+            for _, pat := range pats {
+                if _, err := path.Match(pat, ""); err != nil {
+                    return nil, fmt.Errorf("Rule %d in %s has malformed pattern %q", i, p, pat)
+                }
+            }
+        }
+    }
+    return &policy, nil
+}
+
+func matchAny(pats []string, s string) bool {
+    for _, pat := range pats {
+        if ok, _ := path.Match(pat, s); ok {
+            return true
+        }
+    }
+    return false
+}
+
+// return the rule applying to a method of a service, or nil
+func (p *Policy) rule(method, svcName string) *Rule {
+    for i := range p.Rules {
+        r := &p.Rules[i]
+        if matchAny(r.Methods, method) && (len(r.Services) == 0 || matchAny(r.Services, svcName)) {
+            return r
+        }
+    }
+    return nil
+}
\ No newline at end of file
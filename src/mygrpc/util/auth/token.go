@@ -0,0 +1,245 @@
+// Authentication of the callers of mygrpc by bearer tokens, either static api keys
+// or JWTs verified against a local JWKS file
+
+package auth
+
+import (
+    "crypto"
+    "crypto/ecdsa"
+    "crypto/elliptic"
+    "crypto/rsa"
+    "crypto/sha256"
+    "crypto/subtle"
+    "encoding/base64"
+    "encoding/json"
+    "fmt"
+    "io/ioutil"
+    "math/big"
+    "strings"
+    "time"
+)
+
+// error type raised when a token is rejected
+type TokenError struct {
+    Msg   string
+    Err   error
+}
+
+func (e *TokenError) Error() string {
+    if e.Err == nil {
+        return e.Msg
+    }
+    return fmt.Sprintf("%s: %v", e.Msg, e.Err)
+}
+
+// a static api key and the caller it authenticates
+type APIKey struct {
+    Key       string   `json:"key"`
+    Subject   string   `json:"subject"`
+}
+
+// read the api keys from a json file, a list of {"key": ..., "subject": ...}
+func LoadAPIKeys(path string) ([]APIKey, error) {
+    data, err := ioutil.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    var keys []APIKey
+    if err := json.Unmarshal(data, &keys); err != nil {
+        return nil, fmt.Errorf("Failed to unmarshal the api keys from %s: %v", path, err)
+    }
+    for i, k := range keys {
+        if k.Key == "" || k.Subject == "" {
+            return nil, fmt.Errorf("Api key %d in %s has no key or no subject", i, path)
+        }
+    }
+    return keys, nil
+}
+
+// a key of a JWKS, as in RFC 7517
+type jwk struct {
+    Kty   string   `json:"kty"`
+    Kid   string   `json:"kid"`
+    Alg   string   `json:"alg"`
+    N     string   `json:"n"`
+    E     string   `json:"e"`
+    Crv   string   `json:"crv"`
+    X     string   `json:"x"`
+    Y     string   `json:"y"`
+}
+
+// read the public keys of a JWKS file by key id. RSA keys and EC keys on P-256 are
+// supported, verifying RS256 and ES256 tokens
+func LoadJWKS(path string) (map[string]crypto.PublicKey, error) {
+    data, err := ioutil.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    var set struct {
+        Keys   []jwk   `json:"keys"`
+    }
+    if err := json.Unmarshal(data, &set); err != nil {
+        return nil, fmt.Errorf("Failed to unmarshal the JWKS from %s: %v", path, err)
+    }
+    keys := make(map[string]crypto.PublicKey)
+    for i, k := range set.Keys {
+        pub, err := k.publicKey()
+        if err != nil {
+            return nil, fmt.Errorf("Key %d (%s) in %s: %v", i, k.Kid, path, err)
+        }
+        keys[k.Kid] = pub
+    }
+    return keys, nil
+}
+
+func decodeInt(s string) (*big.Int, error) {
+    b, err := base64.RawURLEncoding.DecodeString(s)
+    if err != nil || len(b) == 0 {
+        return nil, fmt.Errorf("malformed integer %q", s)
+    }
+    return new(big.Int).SetBytes(b), nil
+}
+
+func (k *jwk) publicKey() (crypto.PublicKey, error) {
+    switch k.Kty {
+        case "RSA":
+            n, err := decodeInt(k.N)
+            if err != nil {
+                return nil, err
+            }
+            e, err := decodeInt(k.E)
+            if err != nil {
+                return nil, err
+            }
+            return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+        case "EC":
+            if k.Crv != "P-256" {
+                return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+            }
+            x, err := decodeInt(k.X)
+            if err != nil {
+                return nil, err
+            }
+            y, err := decodeInt(k.Y)
+            if err != nil {
+                return nil, err
+            }
+            return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+        default:
+            return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+    }
+}
+
+// claims of a JWT checked by the verifier
+type claims struct {
+    Sub   string            `json:"sub"`
+    Iss   string            `json:"iss"`
+    Aud   json.RawMessage   `json:"aud"`   // a string or a list of strings
+    Exp   *int64            `json:"exp"`
+    Nbf   *int64            `json:"nbf"`
+}
+
+func (c *claims) hasAudience(aud string) bool {
+    var one string
+    if json.Unmarshal(c.Aud, &one) == nil {
+        return one == aud
+    }
+    var many []string
+    json.Unmarshal(c.Aud, &many)
+    for _, a := range many {
+        if a == aud {
+            return true
+        }
+    }
+    return false
+}
+
+// verifier of the bearer tokens, returning the subject they authenticate
+type Verifier struct {
+    apiKeys    []APIKey
+    jwks       map[string]crypto.PublicKey   // keys verifying the JWTs, by key id
+    Issuer     string   // issuer required in the JWTs, any if empty
+    Audience   string   // audience required in the JWTs, any if empty
+    now        func() time.Time
+}
+
+func NewVerifier(apiKeys []APIKey, jwks map[string]crypto.PublicKey) *Verifier {
+    return &Verifier{apiKeys: apiKeys, jwks: jwks, now: time.Now}
+}
+
+// return the subject authenticated by a token. a token with three dot separated parts
+// is a JWT, any other one an api key
+func (v *Verifier) Verify(token string) (string, error) {
+    if strings.Count(token, ".") == 2 {
+        return v.verifyJWT(token)
+    }
+    for _, k := range v.apiKeys {
+        if subtle.ConstantTimeCompare([]byte(k.Key), []byte(token)) == 1 {
+            return k.Subject, nil
+        }
+    }
+    return "", &TokenError{Msg: "Unknown api key"}
+}
+
+func (v *Verifier) verifyJWT(token string) (string, error) {
+    parts := strings.Split(token, ".")
+    var header struct {
+        Alg   string   `json:"alg"`
+        Kid   string   `json:"kid"`
+    }
+    if err := decodeSegment(parts[0], &header); err != nil {
+        return "", &TokenError{Msg: "Malformed JWT header", Err: err}
+    }
+    key, prs := v.jwks[header.Kid]
+    if !prs {
+        return "", &TokenError{Msg: fmt.Sprintf("Unknown JWT key id %q", header.Kid)}
+    }
+    sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+    if err != nil {
+        return "", &TokenError{Msg: "Malformed JWT signature", Err: err}
+    }
+    digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+    switch pub := key.(type) {
+        case *rsa.PublicKey:
+            if header.Alg != "RS256" {
+                return "", &TokenError{Msg: fmt.Sprintf("JWT algorithm %q does not match the RSA key", header.Alg)}
+            }
+            if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig); err != nil {
+                return "", &TokenError{Msg: "Invalid JWT signature", Err: err}
+            }
+        case *ecdsa.PublicKey:
+            if header.Alg != "ES256" {
+                return "", &TokenError{Msg: fmt.Sprintf("JWT algorithm %q does not match the EC key", header.Alg)}
+            }
+            if len(sig) != 64 || !ecdsa.Verify(pub, digest[:], new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])) {
+                return "", &TokenError{Msg: "Invalid JWT signature"}
+            }
+    }
+
+    var c claims
+    if err := decodeSegment(parts[1], &c); err != nil {
+        return "", &TokenError{Msg: "Malformed JWT claims", Err: err}
+    }
+    now := v.now().Unix()
+    switch {
+        case c.Exp == nil || now >= *c.Exp:
+            return "", &TokenError{Msg: "JWT is expired or has no expiry"}
+        case c.Nbf != nil && now < *c.Nbf:
+            return "", &TokenError{Msg: "JWT is not valid yet"}
+        case v.Issuer != "" && c.Iss != v.Issuer:
+            return "", &TokenError{Msg: fmt.Sprintf("JWT issuer %q is not trusted", c.Iss)}
+        case v.Audience != "" && !c.hasAudience(v.Audience):
+            return "", &TokenError{Msg: fmt.Sprintf("JWT is not issued for audience %q", v.Audience)}
+        case c.Sub == "":
+            return "", &TokenError{Msg: "JWT has no subject"}
+    }
+    return c.Sub, nil
+}
+
+func decodeSegment(seg string, v interface{}) error {
+    b, err := base64.RawURLEncoding.DecodeString(seg)
+    if err != nil {
+        return err
+    }
+    return json.Unmarshal(b, v)
+}
\ No newline at end of file
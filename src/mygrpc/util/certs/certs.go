@@ -0,0 +1,141 @@
+// Generation of throwaway certificates for local tests of TLS
+
+package certs
+
+import (
+    "crypto/ecdsa"
+    "crypto/elliptic"
+    "crypto/rand"
+    "crypto/x509"
+    "crypto/x509/pkix"
+    "encoding/pem"
+    "io/ioutil"
+    "math/big"
+    "net"
+    "os"
+    "path/filepath"
+    "time"
+
+    "mygrpc/util/logging"
+)
+
+var logger = logging.Logger("certs")
+
+// a certificate with its private key
+type KeyPair struct {
+    Cert   *x509.Certificate
+    Key    *ecdsa.PrivateKey
+    DER    []byte   // the certificate in der
+}
+
+// write the certificate and the key as pem files
+func (kp *KeyPair) WriteFiles(certFile, keyFile string) error {
+    keyDER, err := x509.MarshalECPrivateKey(kp.Key)
+    if err != nil {
+        return err
+    }
+    certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: kp.DER})
+    keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+    if err := ioutil.WriteFile(certFile, certPEM, 0644); err != nil {
+        return err
+    }
+    return ioutil.WriteFile(keyFile, keyPEM, 0600)
+}
+
+func newSerial() (*big.Int, error) {
+    return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+// generate a self-signed CA valid for the duration
+func GenerateCA(name string, validity time.Duration) (*KeyPair, error) {
+    key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+    if err != nil {
+        return nil, err
+    }
+    serial, err := newSerial()
+    if err != nil {
+        return nil, err
+    }
+    tmpl := &x509.Certificate{
+                SerialNumber:           serial,
+                Subject:                pkix.Name{CommonName: name, Organization: []string{"mygrpc"}},
+                NotBefore:              time.Now().Add(-time.Hour),
+                NotAfter:               time.Now().Add(validity),
+                KeyUsage:               x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+                BasicConstraintsValid:  true,
+                IsCA:                   true,
+            }
+    return sign(tmpl, key, tmpl, key)
+}
+
+// generate a leaf certificate signed by the CA, for servers if hosts are given, and
+// for clients otherwise. hosts are dns names or ip addresses
+func GenerateLeaf(ca *KeyPair, name string, hosts []string, validity time.Duration) (*KeyPair, error) {
+    key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+    if err != nil {
+        return nil, err
+    }
+    serial, err := newSerial()
+    if err != nil {
+        return nil, err
+    }
+    tmpl := &x509.Certificate{
+                SerialNumber:  serial,
+                Subject:       pkix.Name{CommonName: name, Organization: []string{"mygrpc"}},
+                NotBefore:     time.Now().Add(-time.Hour),
+                NotAfter:      time.Now().Add(validity),
+                KeyUsage:      x509.KeyUsageDigitalSignature,
+                ExtKeyUsage:   []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+            }
+    if len(hosts) > 0 {
+        tmpl.ExtKeyUsage = append(tmpl.ExtKeyUsage, x509.ExtKeyUsageServerAuth)
+    }
+    for _, h := range hosts {
+        if ip := net.ParseIP(h); ip != nil {
+            tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+        } else {
+            tmpl.DNSNames = append(tmpl.DNSNames, h)
+        }
+    }
+    return sign(tmpl, key, ca.Cert, ca.Key)
+}
+
+func sign(tmpl *x509.Certificate, key *ecdsa.PrivateKey, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*KeyPair, error) {
+    der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+    if err != nil {
+        return nil, err
+    }
+    cert, err := x509.ParseCertificate(der)
+    if err != nil {
+        return nil, err
+    }
+    return &KeyPair{Cert: cert, Key: key, DER: der}, nil
+}
+
+// generate a CA, a server and a client certificate into dir as ca.pem, server.pem,
+// server-key.pem, client.pem and client-key.pem. the key of the CA is not kept
+func GenerateAll(dir string, hosts []string, validity time.Duration) error {
+    if err := os.MkdirAll(dir, 0755); err != nil {
+        return err
+    }
+    ca, err := GenerateCA("mygrpc test CA", validity)
+    if err != nil {
+        return err
+    }
+    server, err := GenerateLeaf(ca, "mygrpc server", hosts, validity)
+    if err != nil {
+        return err
+    }
+    client, err := GenerateLeaf(ca, "mygrpc client", nil, validity)
+    if err != nil {
+        return err
+    }
+    caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.DER})
+    if err := ioutil.WriteFile(filepath.Join(dir, "ca.pem"), caPEM, 0644); err != nil {
+        return err
+    }
+    if err := server.WriteFiles(filepath.Join(dir, "server.pem"), filepath.Join(dir, "server-key.pem")); err != nil {
+        return err
+    }
+    return client.WriteFiles(filepath.Join(dir, "client.pem"), filepath.Join(dir, "client-key.pem"))
+}
\ No newline at end of file
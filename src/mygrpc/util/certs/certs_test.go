@@ -0,0 +1,97 @@
+package certs
+
+import (
+    "crypto/tls"
+    "crypto/x509"
+    "io/ioutil"
+    "net"
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+)
+
+// run a handshake between the configurations over a loopback connection. the
+// server verdict on the client certificate is only known after the client handshake
+// with TLS 1.3, so the server error is reported first
+func handshake(server, client *tls.Config) error {
+    lis, err := net.Listen("tcp", "127.0.0.1:0")
+    if err != nil {
+        return err
+    }
+    defer lis.Close()
+    errs := make(chan error, 1)
+    go func() {
+        conn, err := lis.Accept()
+        if err != nil {
+            errs <- err
+            return
+        }
+        defer conn.Close()
+        errs <- tls.Server(conn, server).Handshake()
+    }()
+    conn, err := tls.Dial("tcp", lis.Addr().String(), client)
+    if err != nil {
+        <-errs
+        return err
+    }
+    defer conn.Close()
+    conn.Read(make([]byte, 1))
+    return <-errs
+}
+
+func TestMutualTLS(t *testing.T) {
+    dir, err := ioutil.TempDir("", "certs")
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer os.RemoveAll(dir)
+    if err := GenerateAll(dir, []string{"localhost"}, time.Hour); err != nil {
+        t.Fatal(err)
+    }
+    path := func(name string) string { return filepath.Join(dir, name) }
+
+    server, err := NewReloader(Files{CertFile: path("server.pem"), KeyFile: path("server-key.pem"), CAFile: path("ca.pem"), ClientAuth: ClientAuthRequire}, true)
+    if err != nil {
+        t.Fatal(err)
+    }
+    client, err := NewReloader(Files{CertFile: path("client.pem"), KeyFile: path("client-key.pem"), CAFile: path("ca.pem"), ServerName: "localhost"}, false)
+    if err != nil {
+        t.Fatal(err)
+    }
+    anonymous, err := NewReloader(Files{CAFile: path("ca.pem"), ServerName: "localhost"}, false)
+    if err != nil {
+        t.Fatal(err)
+    }
+    scfg, _ := server.Config()
+    ccfg, _ := client.Config()
+    acfg, _ := anonymous.Config()
+    if err := handshake(scfg, ccfg); err != nil {
+        t.Errorf("mutual TLS handshake failed: %v", err)
+    }
+    if err := handshake(scfg, acfg); err == nil {
+        t.Errorf("client without certificate is accepted")
+    }
+
+    // rotated certificates are picked up on the next check
+    old := scfg.Certificates[0].Leaf
+    if old == nil {
+        old, _ = parseLeaf(scfg)
+    }
+    time.Sleep(10 * time.Millisecond)
+    if err := GenerateAll(dir, []string{"localhost"}, time.Hour); err != nil {
+        t.Fatal(err)
+    }
+    server.mu.Lock()
+    server.checked = time.Time{}
+    server.mu.Unlock()
+    scfg, _ = server.Config()
+    cur, _ := parseLeaf(scfg)
+    if cur.SerialNumber.Cmp(old.SerialNumber) == 0 {
+        t.Errorf("rotated certificate is not reloaded")
+    }
+}
+
+func parseLeaf(cfg *tls.Config) (*x509.Certificate, error) {
+    return x509.ParseCertificate(cfg.Certificates[0].Certificate[0])
+}
\ No newline at end of file
@@ -0,0 +1,239 @@
+// TLS credentials of mygrpc reloaded from disk when the certificates are rotated
+
+package certs
+
+import (
+    "crypto/tls"
+    "crypto/x509"
+    "fmt"
+    "io/ioutil"
+    "net"
+    "os"
+    "sync"
+    "time"
+
+    "golang.org/x/net/context"
+    "google.golang.org/grpc/credentials"
+)
+
+const checkInterval = time.Second   // minimal interval between checks of the files for changes
+
+// client certificate verification of a server
+const (
+    ClientAuthNone      = "none"   // client certificates are not asked
+    ClientAuthOptional  = "optional"   // client certificates are verified if given
+    ClientAuthRequire   = "require"   // client certificates are required and verified
+)
+
+// files of the certificates of one side of the connections. CertFile and KeyFile are
+// the own certificate, required on servers and giving mutual TLS on clients. CAFile
+// verifies the peers, defaulting to the system roots on clients
+type Files struct {
+    CertFile     string
+    KeyFile      string
+    CAFile       string
+    ClientAuth   string   // client certificate verification of servers, ClientAuthNone if empty
+    ServerName   string   // name verified in the server certificate by clients, the dialed host if empty
+}
+
+// modification time and size of a file, telling whether it changed
+type stamp struct {
+    modTime   time.Time
+    size      int64
+}
+
+// source of the tls configuration of one side, rebuilt when the files change
+type Reloader struct {
+    files     Files
+    server    bool
+
+    mu        sync.Mutex   // guards the fields below
+    config    *tls.Config
+    stamps    map[string]stamp   // stamps of the files when last loaded
+    checked   time.Time   // time of the last check of the files
+}
+
+// create the source of the configuration of a server, or of a client, loading the files
+func NewReloader(files Files, server bool) (*Reloader, error) {
+    if server && (files.CertFile == "" || files.KeyFile == "") {
+        return nil, fmt.Errorf("Certificate and key files are required on servers")
+    }
+    if (files.CertFile == "") != (files.KeyFile == "") {
+        return nil, fmt.Errorf("Certificate and key files go together")
+    }
+    switch files.ClientAuth {
+        case "", ClientAuthNone, ClientAuthOptional, ClientAuthRequire:
+        default:
+            return nil, fmt.Errorf("Unknown client auth %q, expecting %s, %s or %s", files.ClientAuth, ClientAuthNone, ClientAuthOptional, ClientAuthRequire)
+    }
+    if files.ClientAuth != "" && files.ClientAuth != ClientAuthNone && files.CAFile == "" {
+        return nil, fmt.Errorf("A CA file is required to verify client certificates")
+    }
+    r := &Reloader{files: files, server: server}
+    if _, err := r.Config(); err != nil {
+        return nil, err
+    }
+    return r, nil
+}
+
+// return the current configuration, reloaded first if a file changed since the last
+// loading. the previous configuration is kept if the new files cannot be loaded, e.g.
+// while they are being rotated
+func (r *Reloader) Config() (*tls.Config, error) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    if r.config != nil && time.Since(r.checked) < checkInterval {
+        return r.config, nil
+    }
+    r.checked = time.Now()
+    stamps, err := r.stampFiles()
+    if err != nil {
+        return r.keep(err)
+    }
+    if r.config != nil && sameStamps(stamps, r.stamps) {
+        return r.config, nil
+    }
+    cfg, err := r.load()
+    if err != nil {
+        return r.keep(err)
+    }
+    if r.config != nil {
+        logger.Info("Reloaded the TLS certificates", "files", r.describe())
+    }
+    r.config, r.stamps = cfg, stamps
+    return cfg, nil
+}
+
+// keep the current configuration on a failed reloading, which is reported
+func (r *Reloader) keep(err error) (*tls.Config, error) {
+    if r.config == nil {
+        return nil, err
+    }
+    logger.Warn("Failed to reload the TLS certificates, keep the previous ones", "err", err)
+    return r.config, nil
+}
+
+func (r *Reloader) paths() []string {
+    var ps []string
+    for _, p := range []string{r.files.CertFile, r.files.KeyFile, r.files.CAFile} {
+        if p != "" {
+            ps = append(ps, p)
+        }
+    }
+    return ps
+}
+
+func (r *Reloader) describe() string {
+    return fmt.Sprintf("%v", r.paths())
+}
+
+func (r *Reloader) stampFiles() (map[string]stamp, error) {
+    stamps := make(map[string]stamp)
+    for _, p := range r.paths() {
+        fi, err := os.Stat(p)
+        if err != nil {
+            return nil, err
+        }
+        stamps[p] = stamp{modTime: fi.ModTime(), size: fi.Size()}
+    }
+    return stamps, nil
+}
+
+func sameStamps(a, b map[string]stamp) bool {
+    if len(a) != len(b) {
+        return false
+    }
+    for p, s := range a {
+        if t, prs := b[p]; !prs || !t.modTime.Equal(s.modTime) || t.size != s.size {
+            return false
+        }
+    }
+    return true
+}
+
+func (r *Reloader) load() (*tls.Config, error) {
+    cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+    if r.files.CertFile != "" {
+        cert, err := tls.LoadX509KeyPair(r.files.CertFile, r.files.KeyFile)
+        if err != nil {
+            return nil, fmt.Errorf("Failed to load the key pair %s, %s: %v", r.files.CertFile, r.files.KeyFile, err)
+        }
+        cfg.Certificates = []tls.Certificate{cert}
+    }
+    var pool *x509.CertPool
+    if r.files.CAFile != "" {
+        pem, err := ioutil.ReadFile(r.files.CAFile)
+        if err != nil {
+            return nil, fmt.Errorf("Failed to read the CA file %s: %v", r.files.CAFile, err)
+        }
+        pool = x509.NewCertPool()
+        if !pool.AppendCertsFromPEM(pem) {
+            return nil, fmt.Errorf("No certificate found in the CA file %s", r.files.CAFile)
+        }
+    }
+    if r.server {
+        cfg.ClientCAs = pool
+        switch r.files.ClientAuth {
+            case ClientAuthOptional:
+                cfg.ClientAuth = tls.VerifyClientCertIfGiven
+            case ClientAuthRequire:
+                cfg.ClientAuth = tls.RequireAndVerifyClientCert
+        }
+    } else {
+        cfg.RootCAs = pool
+        cfg.ServerName = r.files.ServerName
+    }
+    return cfg, nil
+}
+
+// grpc credentials doing every handshake with the current configuration, so that
+// rotated certificates apply to the new connections
+func (r *Reloader) TransportCredentials() credentials.TransportCredentials {
+    return &reloadingCreds{reloader: r}
+}
+
+type reloadingCreds struct {
+    reloader     *Reloader
+    serverName   string   // overridden name verified in the server certificate
+}
+
+func (c *reloadingCreds) current() (credentials.TransportCredentials, error) {
+    cfg, err := c.reloader.Config()
+    if err != nil {
+        return nil, err
+    }
+    cfg = cfg.Clone()
+    if c.serverName != "" {
+        cfg.ServerName = c.serverName
+    }
+    return credentials.NewTLS(cfg), nil
+}
+
+func (c *reloadingCreds) ClientHandshake(ctx context.Context, authority string, rawConn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+    creds, err := c.current()
+    if err != nil {
+        return nil, nil, err
+    }
+    return creds.ClientHandshake(ctx, authority, rawConn)
+}
+
+func (c *reloadingCreds) ServerHandshake(rawConn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+    creds, err := c.current()
+    if err != nil {
+        return nil, nil, err
+    }
+    return creds.ServerHandshake(rawConn)
+}
+
+func (c *reloadingCreds) Info() credentials.ProtocolInfo {
+    return credentials.ProtocolInfo{SecurityProtocol: "tls", ServerName: c.serverName}
+}
+
+func (c *reloadingCreds) Clone() credentials.TransportCredentials {
+    return &reloadingCreds{reloader: c.reloader, serverName: c.serverName}
+}
+
+func (c *reloadingCreds) OverrideServerName(name string) error {
+    c.serverName = name
+    return nil
+}
\ No newline at end of file
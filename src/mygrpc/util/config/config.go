@@ -0,0 +1,242 @@
+// Configuration of the binaries of mygrpc from a file, the environment and the flags
+
+package config
+
+import (
+    "fmt"
+    "flag"
+    "io"
+    "io/ioutil"
+    "os"
+    "sort"
+    "strings"
+    "time"
+
+    "gopkg.in/yaml.v3"
+)
+
+const (
+    EnvPrefix   = "MYGRPC_"   // prefix of the environment variables overriding the settings
+
+    configFlag        = "config"
+    printConfigFlag   = "print-config"
+    enabledKey        = "enabled"   // key of a section holding the setting named after the section, e.g. tls.enabled for tls
+
+    SourceDefault   = "default"
+    SourceFile      = "file"
+    SourceEnv       = "env"
+    SourceFlag      = "flag"
+
+    redacted   = "[REDACTED]"
+)
+
+// error type used to raise exceptions when loading the configuration
+type ConfigError struct {
+    Key   string   // setting causing the error, empty if the error is not about one setting
+    Msg   string
+    Err   error
+}
+
+func (e *ConfigError) Error() string {
+    msg := e.Msg
+    if e.Key != "" {
+        msg = fmt.Sprintf("%s: %s", e.Key, e.Msg)
+    }
+    if e.Err == nil {
+        return fmt.Sprintf("Error in the configuration: %s", msg)
+    }
+
+    return fmt.Sprintf("Error in the configuration: %s: %s", msg, e.Err.Error())
+}
+
+// the effective configuration of a binary. every setting is a flag of the flag set,
+// taking its value from, in increasing precedence, the default of the flag, the
+// config file, the MYGRPC_<NAME> environment variable and the command line
+type Config struct {
+    fs            *flag.FlagSet
+    File          string   // the config file, empty if none
+    print         bool   // whether the effective configuration is asked to be printed
+    sources       map[string]string   // source of each setting not from the defaults, by flag name
+    secrets       map[string]bool   // flags whose values are not printed
+}
+
+// return the name of the environment variable overriding a flag, e.g. MYGRPC_TLS_CERT
+// for tls_cert
+func EnvName(flagName string) string {
+    return EnvPrefix + strings.ToUpper(strings.Replace(flagName, "-", "_", -1))
+}
+
+// register the -config and -print-config flags on fs, parse the arguments and apply
+// the config file and the environment to the flags not given on the command line.
+// the values of the secrets are hidden when the configuration is printed
+func Load(fs *flag.FlagSet, args []string, secrets ...string) (*Config, error) {
+    c := &Config{fs: fs, sources: make(map[string]string), secrets: make(map[string]bool)}
+    for _, s := range secrets {
+        c.secrets[s] = true
+    }
+    fs.StringVar(&c.File, configFlag, "", fmt.Sprintf("A yaml or json file of the settings, keyed by the flag names and optionally nested in sections joined by '_', e.g. 'tls: {enabled: true, cert: server.pem}'. Overridden by the %s<FLAG> environment variables and the flags", EnvPrefix))
+    fs.BoolVar(&c.print, printConfigFlag, false, "Prints the effective configuration with the source of each setting and exits")
+    if err := fs.Parse(args); err != nil {
+        return nil, err
+    }
+    fs.Visit(func(f *flag.Flag) {
+        c.sources[f.Name] = SourceFlag
+    })
+    if _, prs := c.sources[configFlag]; !prs {
+        if v, ok := os.LookupEnv(EnvName(configFlag)); ok {
+            c.File = v
+        }
+    }
+
+    if c.File != "" {
+        settings, err := ReadFile(c.File)
+        if err != nil {
+            return nil, err
+        }
+        if err := c.apply(settings, SourceFile); err != nil {
+            return nil, err
+        }
+    }
+    env := make(map[string]string)
+    fs.VisitAll(func(f *flag.Flag) {
+        if v, ok := os.LookupEnv(EnvName(f.Name)); ok && f.Name != configFlag && f.Name != printConfigFlag {
+            env[f.Name] = v
+        }
+    })
+    if err := c.apply(env, SourceEnv); err != nil {
+        return nil, err
+    }
+    return c, nil
+}
+
+// set the flags not given on the command line to the settings
+func (c *Config) apply(settings map[string]string, source string) error {
+    names := make([]string, 0, len(settings))
+    for name := range settings {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    for _, name := range names {
+        if name == configFlag || name == printConfigFlag || c.fs.Lookup(name) == nil {
+            return &ConfigError{Key: name, Msg: fmt.Sprintf("Unknown setting from the %s", source)}
+        }
+        if c.sources[name] == SourceFlag {
+            continue
+        }
+        if err := c.fs.Set(name, settings[name]); err != nil {
+            return &ConfigError{Key: name, Msg: fmt.Sprintf("Invalid value %q from the %s", settings[name], source), Err: err}
+        }
+        c.sources[name] = source
+    }
+    return nil
+}
+
+// read a yaml or json config file into the values of the settings by flag name
+func ReadFile(path string) (map[string]string, error) {
+    data, err := ioutil.ReadFile(path)
+    if err != nil {
+        return nil, &ConfigError{Msg: "Failed to read " + path, Err: err}
+    }
+    var doc map[string]interface{}
+    if err := yaml.Unmarshal(data, &doc); err != nil {
+        return nil, &ConfigError{Msg: "Failed to parse " + path, Err: err}
+    }
+    settings := make(map[string]string)
+    if err := flatten("", doc, settings); err != nil {
+        return nil, err
+    }
+    return settings, nil
+}
+
+// flatten the sections of a config file into settings named by joining the keys
+// with '_', lists are joined with ','
+func flatten(prefix string, doc map[string]interface{}, settings map[string]string) error {
+    for k, v := range doc {
+        name := k
+        switch {
+            case prefix != "" && k == enabledKey:
+                name = prefix
+            case prefix != "":
+                name = prefix + "_" + k
+        }
+        switch value := v.(type) {
+            case nil:
+            case map[string]interface{}:
+                if err := flatten(name, value, settings); err != nil {
+                    return err
+                }
+            case []interface{}:
+                items := make([]string, len(value))
+                for i, item := range value {
+                    if _, ok := item.(map[string]interface{}); ok {
+                        return &ConfigError{Key: name, Msg: "Lists may only hold plain values"}
+                    }
+                    items[i] = fmt.Sprint(item)
+                }
+                settings[name] = strings.Join(items, ",")
+            default:
+                if _, prs := settings[name]; prs {
+                    return &ConfigError{Key: name, Msg: "Given more than once"}
+                }
+                settings[name] = fmt.Sprint(value)
+        }
+    }
+    return nil
+}
+
+// whether -print-config is given
+func (c *Config) PrintRequested() bool {
+    return c.print
+}
+
+// return where a setting comes from, one of default, file, env and flag
+func (c *Config) Source(name string) string {
+    if s, prs := c.sources[name]; prs {
+        return s
+    }
+    return SourceDefault
+}
+
+// write the effective configuration as yaml, which may be used as a config file,
+// commenting the source of each setting
+func (c *Config) Print(w io.Writer) error {
+    var lines []string
+    c.fs.VisitAll(func(f *flag.Flag) {
+        if f.Name == configFlag || f.Name == printConfigFlag {
+            return
+        }
+        value := printable(f)
+        if c.secrets[f.Name] && f.Value.String() != "" {
+            value = scalar(redacted)
+        }
+        lines = append(lines, fmt.Sprintf("%s: %s  # %s", f.Name, value, c.Source(f.Name)))
+    })
+    header := "# effective configuration, from the defaults < config file < " + EnvPrefix + "* environment < flags"
+    if c.File != "" {
+        header += "\n# config file: " + c.File
+    }
+    _, err := fmt.Fprintf(w, "%s\n%s\n", header, strings.Join(lines, "\n"))
+    return err
+}
+
+// return the value of a flag as a yaml scalar
+func printable(f *flag.Flag) string {
+    var v interface{} = f.Value.String()
+    if g, ok := f.Value.(flag.Getter); ok {
+        switch gv := g.Get().(type) {
+            case time.Duration:
+                v = gv.String()
+            case bool, int, int64, uint, uint64, float64:
+                v = gv
+        }
+    }
+    return scalar(v)
+}
+
+func scalar(v interface{}) string {
+    out, err := yaml.Marshal(v)
+    if err != nil {
+        return fmt.Sprint(v)
+    }
+    return strings.TrimSuffix(string(out), "\n")
+}
\ No newline at end of file
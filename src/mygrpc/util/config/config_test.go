@@ -0,0 +1,101 @@
+package config
+
+import (
+    "bytes"
+    "flag"
+    "io/ioutil"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+)
+
+type settings struct {
+    port      *int
+    useTLS    *bool
+    certFile  *string
+    level     *string
+    timeout   *time.Duration
+    token     *string
+}
+
+func newFlagSet() (*flag.FlagSet, *settings) {
+    fs := flag.NewFlagSet("test", flag.ContinueOnError)
+    return fs, &settings{
+                   port:      fs.Int("port", 8082, ""),
+                   useTLS:    fs.Bool("tls", false, ""),
+                   certFile:  fs.String("tls_cert", "", ""),
+                   level:     fs.String("log_level", "info", ""),
+                   timeout:   fs.Duration("drain_timeout", 25 * time.Second, ""),
+                   token:     fs.String("token", "", ""),
+               }
+}
+
+func writeFile(t *testing.T, name, content string) string {
+    path := filepath.Join(t.TempDir(), name)
+    if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+        t.Fatal(err)
+    }
+    return path
+}
+
+func TestPrecedence(t *testing.T) {
+    path := writeFile(t, "config.yaml", "port: 9000\ntls:\n  enabled: true\n  cert: file.pem\nlog:\n  level: debug\ndrain_timeout: 1m\n")
+    t.Setenv("MYGRPC_LOG_LEVEL", "warn")
+    t.Setenv("MYGRPC_PORT", "9001")
+    t.Setenv("MYGRPC_UNRELATED", "x")
+    fs, s := newFlagSet()
+    c, err := Load(fs, []string{"-config", path, "-port", "9002", "-token", "secret"}, "token")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if *s.port != 9002 || !*s.useTLS || *s.certFile != "file.pem" || *s.level != "warn" || *s.timeout != time.Minute {
+        t.Errorf("settings = %d %v %q %q %v", *s.port, *s.useTLS, *s.certFile, *s.level, *s.timeout)
+    }
+    for name, want := range map[string]string{"port": SourceFlag, "tls": SourceFile, "log_level": SourceEnv, "drain_timeout": SourceFile} {
+        if got := c.Source(name); got != want {
+            t.Errorf("Source(%s) = %s, want %s", name, got, want)
+        }
+    }
+
+    var buf bytes.Buffer
+    if err := c.Print(&buf); err != nil {
+        t.Fatal(err)
+    }
+    out := buf.String()
+    for _, line := range []string{"port: 9002  # flag", "tls: true  # file", "log_level: warn  # env", "drain_timeout: 1m0s  # file", "token: '[REDACTED]'  # flag"} {
+        if !strings.Contains(out, line) && !strings.Contains(out, strings.Replace(line, "'", "\"", -1)) {
+            t.Errorf("printed config misses %q:\n%s", line, out)
+        }
+    }
+    if strings.Contains(out, "secret") {
+        t.Errorf("printed config shows the token:\n%s", out)
+    }
+}
+
+func TestConfigFromEnv(t *testing.T) {
+    path := writeFile(t, "config.json", `{"port": 7000, "tls_cert": "a.pem"}`)
+    t.Setenv("MYGRPC_CONFIG", path)
+    fs, s := newFlagSet()
+    c, err := Load(fs, nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if c.File != path || *s.port != 7000 || *s.certFile != "a.pem" {
+        t.Errorf("config %q gave port %d and cert %q", c.File, *s.port, *s.certFile)
+    }
+}
+
+func TestInvalidSettings(t *testing.T) {
+    for _, content := range []string{"unknown: 1\n", "port: abc\n", "tls_cert: a\ntls:\n  cert: b\n", "port: [\n"} {
+        fs, _ := newFlagSet()
+        if _, err := Load(fs, []string{"-config", writeFile(t, "config.yaml", content)}); err == nil {
+            t.Errorf("Load of %q succeeded", content)
+        }
+    }
+    t.Setenv("MYGRPC_DRAIN_TIMEOUT", "soon")
+    fs, _ := newFlagSet()
+    if _, err := Load(fs, nil); err == nil {
+        t.Errorf("Load with MYGRPC_DRAIN_TIMEOUT=soon succeeded")
+    }
+}
\ No newline at end of file
@@ -0,0 +1,265 @@
+// Logging of the rpcs with their method, peer, chain, duration and status code
+
+package logging
+
+import (
+    "io"
+    "log/slog"
+    "strings"
+    "sync/atomic"
+    "time"
+
+    "mygrpc/util/tracing"
+
+    "golang.org/x/net/context"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/peer"
+    "google.golang.org/grpc/status"
+)
+
+// messages carrying the id of a service chain
+type chainMessage interface {
+    GetChainId() int32
+}
+
+// services whose successful rpcs, streams included, are only logged at the debug
+// level, as they are polled by the probes and the tools rather than called by the
+// clients
+var quietServices = []string{
+    "/grpc.health.v1.Health/",
+    "/grpc.reflection.v1.ServerReflection/",
+    "/grpc.reflection.v1alpha.ServerReflection/",
+}
+
+// return the level an rpc ending with the code is logged at, ok being the level of
+// a success. the failures caused by the server are errors while the ones caused by
+// the clients are warnings
+func codeLevel(method string, code codes.Code, ok slog.Level) slog.Level {
+    switch code {
+        case codes.OK:
+            for _, q := range quietServices {
+                if strings.HasPrefix(method, q) {
+                    return slog.LevelDebug
+                }
+            }
+            return ok
+        case codes.Unknown, codes.Internal, codes.Unavailable, codes.DataLoss, codes.Unimplemented, codes.DeadlineExceeded:
+            return slog.LevelError
+    }
+    return slog.LevelWarn
+}
+
+// return the fields common to the records of an rpc, the method first
+func rpcAttrs(ctx context.Context, method, peerKey, peerAddr string) []slog.Attr {
+    attrs := []slog.Attr{slog.String("method", method), slog.String(peerKey, peerAddr)}
+    if id := tracing.TraceIDFromContext(ctx); id != "" {
+        attrs = append(attrs, slog.String("trace_id", id))
+    }
+    return attrs
+}
+
+func peerAddr(ctx context.Context) string {
+    if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+        return p.Addr.String()
+    }
+    return ""
+}
+
+// log the end of an rpc, at the level ok if it succeeds
+func logEnd(ctx context.Context, l *slog.Logger, attrs []slog.Attr, start time.Time, err error, ok slog.Level, extra ...slog.Attr) {
+    st := status.Convert(err)
+    lvl := codeLevel(attrs[0].Value.String(), st.Code(), ok)
+    if !l.Enabled(ctx, lvl) {
+        return
+    }
+    attrs = append(attrs, extra...)
+    attrs = append(attrs, slog.Duration("duration", time.Since(start)), slog.String("code", st.Code().String()))
+    if err != nil {
+        attrs = append(attrs, slog.String("err", st.Message()))
+    }
+    l.LogAttrs(ctx, lvl, "Finished rpc", attrs...)
+}
+
+// log a message of a stream at the debug level, or at the info level with its
+// payload if the messages of the rpc are sampled
+func logMessage(ctx context.Context, l *slog.Logger, attrs []slog.Attr, msg string, n int64, m interface{}, sampled bool) {
+    lvl := slog.LevelDebug
+    if sampled {
+        lvl = slog.LevelInfo
+    }
+    if !l.Enabled(ctx, lvl) {
+        return
+    }
+    attrs = append(attrs[:len(attrs):len(attrs)], slog.Int64("seq", n))
+    if cm, ok := m.(chainMessage); ok {
+        attrs = append(attrs, slog.Int("chain_id", int(cm.GetChainId())))
+    }
+    if sampled {
+        attrs = append(attrs, slog.Any("payload", Payload(m)))
+    }
+    l.LogAttrs(ctx, lvl, msg, attrs...)
+}
+
+// log the unary rpcs handled by a server, with their messages if sampled by p
+func UnaryServerInterceptor(l *slog.Logger, p *Payloads) grpc.UnaryServerInterceptor {
+    return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+        start := time.Now()
+        resp, err := handler(ctx, req)
+        attrs := rpcAttrs(ctx, info.FullMethod, "peer", peerAddr(ctx))
+        logUnary(ctx, l, attrs, start, req, resp, err, p.Sample())
+        return resp, err
+    }
+}
+
+// log the unary rpcs called by a client, with their messages if sampled by p
+func UnaryClientInterceptor(l *slog.Logger, p *Payloads) grpc.UnaryClientInterceptor {
+    return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+        start := time.Now()
+        err := invoker(ctx, method, req, reply, cc, opts...)
+        attrs := rpcAttrs(ctx, method, "target", cc.Target())
+        var resp interface{}
+        if err == nil {
+            resp = reply
+        }
+        logUnary(ctx, l, attrs, start, req, resp, err, p.Sample())
+        return err
+    }
+}
+
+// log the end of a unary rpc. the successes, which make the bulk of the rpcs, are
+// logged at the debug level unless their payloads are sampled
+func logUnary(ctx context.Context, l *slog.Logger, attrs []slog.Attr, start time.Time, req, resp interface{}, err error, sampled bool) {
+    var extra []slog.Attr
+    if cm, ok := req.(chainMessage); ok {
+        extra = append(extra, slog.Int("chain_id", int(cm.GetChainId())))
+    }
+    lvl := slog.LevelDebug
+    if sampled {
+        lvl = slog.LevelInfo
+        extra = append(extra, slog.Any("request", Payload(req)))
+        if resp != nil {
+            extra = append(extra, slog.Any("response", Payload(resp)))
+        }
+    }
+    logEnd(ctx, l, attrs, start, err, lvl, extra...)
+}
+
+// log the streams handled by a server and their messages, with the payloads if
+// sampled by p
+func StreamServerInterceptor(l *slog.Logger, p *Payloads) grpc.StreamServerInterceptor {
+    return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+        start := time.Now()
+        ls := &loggedStream{
+                  ctx:      ss.Context(),
+                  logger:   l,
+                  attrs:    rpcAttrs(ss.Context(), info.FullMethod, "peer", peerAddr(ss.Context())),
+                  sampled:  p.Sample(),
+              }
+        err := handler(srv, &loggedServerStream{ServerStream: ss, stream: ls})
+        ls.end(start, err)
+        return err
+    }
+}
+
+// log the streams called by a client and their messages, with the payloads if
+// sampled by p
+func StreamClientInterceptor(l *slog.Logger, p *Payloads) grpc.StreamClientInterceptor {
+    return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+        start := time.Now()
+        ls := &loggedStream{
+                  ctx:      ctx,
+                  logger:   l,
+                  attrs:    rpcAttrs(ctx, method, "target", cc.Target()),
+                  sampled:  p.Sample(),
+              }
+        cs, err := streamer(ctx, desc, cc, method, opts...)
+        if err != nil {
+            ls.end(start, err)
+            return nil, err
+        }
+        return &loggedClientStream{ClientStream: cs, stream: ls, start: start, serverStreams: desc.ServerStreams}, nil
+    }
+}
+
+// state of the logging of a stream
+type loggedStream struct {
+    ctx        context.Context
+    logger     *slog.Logger
+    attrs      []slog.Attr
+    sampled    bool
+    sent       atomic.Int64   // counted atomically as streams may send and receive in different goroutines
+    received   atomic.Int64
+}
+
+func (s *loggedStream) send(m interface{}) {
+    logMessage(s.ctx, s.logger, s.attrs, "Sent message", s.sent.Add(1), m, s.sampled)
+}
+
+func (s *loggedStream) recv(m interface{}) {
+    logMessage(s.ctx, s.logger, s.attrs, "Received message", s.received.Add(1), m, s.sampled)
+}
+
+func (s *loggedStream) end(start time.Time, err error) {
+    logEnd(s.ctx, s.logger, s.attrs, start, err, slog.LevelInfo, slog.Int64("msgs_sent", s.sent.Load()), slog.Int64("msgs_received", s.received.Load()))
+}
+
+type loggedServerStream struct {
+    grpc.ServerStream
+    stream   *loggedStream
+}
+
+func (s *loggedServerStream) SendMsg(m interface{}) error {
+    err := s.ServerStream.SendMsg(m)
+    if err == nil {
+        s.stream.send(m)
+    }
+    return err
+}
+
+func (s *loggedServerStream) RecvMsg(m interface{}) error {
+    err := s.ServerStream.RecvMsg(m)
+    if err == nil {
+        s.stream.recv(m)
+    }
+    return err
+}
+
+// client stream logging its end once the server closes it or it fails
+type loggedClientStream struct {
+    grpc.ClientStream
+    stream          *loggedStream
+    start           time.Time
+    serverStreams   bool   // whether the server sends a stream of responses
+    ended           atomic.Bool
+}
+
+func (s *loggedClientStream) finish(err error) {
+    if s.ended.CompareAndSwap(false, true) {
+        s.stream.end(s.start, err)
+    }
+}
+
+func (s *loggedClientStream) SendMsg(m interface{}) error {
+    err := s.ClientStream.SendMsg(m)
+    if err == nil {
+        s.stream.send(m)
+    }
+    return err
+}
+
+func (s *loggedClientStream) RecvMsg(m interface{}) error {
+    err := s.ClientStream.RecvMsg(m)
+    switch {
+        case err == nil:
+            s.stream.recv(m)
+            if !s.serverStreams {
+                s.finish(nil)   // the response of a unary or client streaming rpc ends it
+            }
+        case err == io.EOF:
+            s.finish(nil)
+        default:
+            s.finish(err)
+    }
+    return err
+}
\ No newline at end of file
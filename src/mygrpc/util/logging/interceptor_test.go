@@ -0,0 +1,87 @@
+package logging
+
+import (
+    "bytes"
+    "io"
+    "log/slog"
+    "strings"
+    "testing"
+
+    pb "mygrpc/mygrpc"
+
+    "golang.org/x/net/context"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+)
+
+// server stream ending right away
+type emptyStream struct {
+    grpc.ServerStream
+}
+
+func (s emptyStream) Context() context.Context {
+    return context.Background()
+}
+
+func (s emptyStream) RecvMsg(m interface{}) error {
+    return io.EOF
+}
+
+func TestServerInterceptors(t *testing.T) {
+    req := &pb.ServiceChain{ChainId: 3}
+    info := &grpc.UnaryServerInfo{FullMethod: "/mygrpc.MyGrpc/GetChainReqResp"}
+    for _, tc := range []struct {
+        name       string
+        level      slog.Level
+        payloads   string
+        err        error
+        want       string   // level of the record, empty if none is written
+    }{
+        {"success", slog.LevelInfo, PayloadsOff, nil, ""},
+        {"success at debug", slog.LevelDebug, PayloadsOff, nil, "level=DEBUG"},
+        {"sampled success", slog.LevelInfo, PayloadsFull, nil, "level=INFO"},
+        {"client failure", slog.LevelInfo, PayloadsOff, status.Error(codes.NotFound, "missing"), "level=WARN"},
+        {"server failure", slog.LevelInfo, PayloadsOff, status.Error(codes.Internal, "broken"), "level=ERROR"},
+    } {
+        var buf bytes.Buffer
+        l := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: tc.level}))
+        p, err := ParsePayloads(tc.payloads)
+        if err != nil {
+            t.Fatal(err)
+        }
+        _, err = UnaryServerInterceptor(l, p)(context.Background(), req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+            return &pb.ServiceChainDescriptor{ChainId: 3}, tc.err
+        })
+        if err != tc.err {
+            t.Errorf("%s: interceptor returned %v, want %v", tc.name, err, tc.err)
+        }
+        out := buf.String()
+        if tc.want == "" {
+            if out != "" {
+                t.Errorf("%s: logged %q", tc.name, out)
+            }
+            continue
+        }
+        if !strings.Contains(out, tc.want + " msg=\"Finished rpc\" method=" + info.FullMethod) || !strings.Contains(out, "chain_id=3") {
+            t.Errorf("%s: logged %q, want a record at %s", tc.name, out, tc.want)
+        }
+        if strings.Contains(out, "request=") != (tc.payloads == PayloadsFull) {
+            t.Errorf("%s: logged %q with payloads %s", tc.name, out, tc.payloads)
+        }
+    }
+
+    // the end of a successful stream is logged at info
+    var buf bytes.Buffer
+    l := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+    sinfo := &grpc.StreamServerInfo{FullMethod: "/mygrpc.MyGrpc/GetChainsReqsResps", IsClientStream: true, IsServerStream: true}
+    err := StreamServerInterceptor(l, nil)(nil, emptyStream{}, sinfo, func(srv interface{}, ss grpc.ServerStream) error {
+        if err := ss.RecvMsg(nil); err != io.EOF {
+            return err
+        }
+        return nil
+    })
+    if out := buf.String(); err != nil || !strings.Contains(out, "level=INFO msg=\"Finished rpc\" method=" + sinfo.FullMethod) || !strings.Contains(out, "msgs_received=0") {
+        t.Errorf("stream logged %q, %v", out, err)
+    }
+}
\ No newline at end of file
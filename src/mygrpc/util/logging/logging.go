@@ -0,0 +1,138 @@
+// Structured, leveled logging of mygrpc
+
+package logging
+
+import (
+    "fmt"
+    "io"
+    "log/slog"
+    "os"
+    "strings"
+    "sync/atomic"
+
+    "golang.org/x/net/context"
+)
+
+const (
+    FormatText   = "text"
+    FormatJSON   = "json"
+)
+
+var (
+    level     = new(slog.LevelVar)   // level shared by all the loggers, info by default
+    current   atomic.Pointer[slog.Handler]   // handler all the loggers write through
+)
+
+func init() {
+    var h slog.Handler = slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})
+    current.Store(&h)
+}
+
+// error type used to raise exceptions when setting up the logging
+type LoggingError struct {
+    Msg   string
+    Err   error
+}
+
+func (e *LoggingError) Error() string {
+    if e.Err == nil {
+        return fmt.Sprintf("Error setting up the logging: %s", e.Msg)
+    }
+
+    return fmt.Sprintf("Error setting up the logging: %s: %s", e.Msg, e.Err.Error())
+}
+
+// parse one of debug, info, warn and error
+func ParseLevel(s string) (slog.Level, error) {
+    var l slog.Level
+    if err := l.UnmarshalText([]byte(s)); err != nil {
+        return l, &LoggingError{Msg: fmt.Sprintf("Invalid log level %q, should be one of debug, info, warn and error", s)}
+    }
+    return l, nil
+}
+
+// direct all the loggers, including the ones created before, to w in the given
+// format at or above the given level
+func Setup(w io.Writer, format, lvl string) error {
+    l, err := ParseLevel(lvl)
+    if err != nil {
+        return err
+    }
+    opts := &slog.HandlerOptions{Level: level}
+    var h slog.Handler
+    switch strings.ToLower(format) {
+        case FormatText:
+            h = slog.NewTextHandler(w, opts)
+        case FormatJSON:
+            h = slog.NewJSONHandler(w, opts)
+        default:
+            return &LoggingError{Msg: fmt.Sprintf("Invalid log format %q, should be either text or json", format)}
+    }
+    level.Set(l)
+    current.Store(&h)
+    return nil
+}
+
+// return a logger of a component, e.g. "server", writing through the handler set up
+// by Setup at the time of each record
+func Logger(component string) *slog.Logger {
+    return slog.New(&switchHandler{attrs: []slog.Attr{slog.String("component", component)}})
+}
+
+// log the message at the error level and exit
+func Fatal(l *slog.Logger, msg string, args ...interface{}) {
+    l.Error(msg, args...)
+    os.Exit(1)
+}
+
+// a handler forwarding to the current handler, so that package level loggers follow
+// the flags parsed after they are created. the attributes and groups are replayed
+// on the current handler, which is cached until it is replaced
+type switchHandler struct {
+    attrs    []slog.Attr
+    group    string   // group opened after the attributes, at most one as mygrpc never nests
+    parent   *switchHandler   // handler the attributes and the group are added to, nil for the root
+    cache    atomic.Pointer[cachedHandler]
+}
+
+type cachedHandler struct {
+    base      *slog.Handler
+    handler   slog.Handler
+}
+
+func (h *switchHandler) resolve() slog.Handler {
+    base := current.Load()
+    if c := h.cache.Load(); c != nil && c.base == base {
+        return c.handler
+    }
+    var r slog.Handler
+    if h.parent != nil {
+        r = h.parent.resolve()
+    } else {
+        r = *base
+    }
+    if len(h.attrs) > 0 {
+        r = r.WithAttrs(h.attrs)
+    }
+    if h.group != "" {
+        r = r.WithGroup(h.group)
+    }
+    h.cache.Store(&cachedHandler{base: base, handler: r})
+    return r
+}
+
+func (h *switchHandler) Enabled(ctx context.Context, l slog.Level) bool {
+    return l >= level.Level()
+}
+
+func (h *switchHandler) Handle(ctx context.Context, r slog.Record) error {
+    return h.resolve().Handle(ctx, r)
+}
+
+func (h *switchHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+    return &switchHandler{attrs: attrs, parent: h}
+}
+
+func (h *switchHandler) WithGroup(name string) slog.Handler {
+    return &switchHandler{group: name, parent: h}
+}
\ No newline at end of file
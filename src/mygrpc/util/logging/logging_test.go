@@ -0,0 +1,109 @@
+package logging
+
+import (
+    "bytes"
+    "encoding/json"
+    "log/slog"
+    "os"
+    "strings"
+    "testing"
+
+    pb "mygrpc/mygrpc"
+)
+
+func TestParsePayloads(t *testing.T) {
+    for _, s := range []string{"", "off"} {
+        if p, err := ParsePayloads(s); err != nil || p != nil || p.Sample() {
+            t.Errorf("ParsePayloads(%q) = %v, %v, want nil logging none", s, p, err)
+        }
+    }
+    for _, s := range []string{"all", "sample:", "sample:0", "sample:x"} {
+        if _, err := ParsePayloads(s); err == nil {
+            t.Errorf("ParsePayloads(%q) succeeded, want an error", s)
+        }
+    }
+    p, err := ParsePayloads("sample:3")
+    if err != nil {
+        t.Fatal(err)
+    }
+    var got []bool
+    for i := 0; i < 6; i++ {
+        got = append(got, p.Sample())
+    }
+    if want := []bool{true, false, false, true, false, false}; !equal(got, want) {
+        t.Errorf("samples = %v, want %v", got, want)
+    }
+}
+
+func equal(a, b []bool) bool {
+    if len(a) != len(b) {
+        return false
+    }
+    for i := range a {
+        if a[i] != b[i] {
+            return false
+        }
+    }
+    return true
+}
+
+func TestPayloadRedaction(t *testing.T) {
+    var buf bytes.Buffer
+    if err := Setup(&buf, FormatJSON, "debug"); err != nil {
+        t.Fatal(err)
+    }
+    defer Setup(os.Stderr, FormatText, "info")
+
+    scd := &pb.ServiceChainDescriptor{
+               ChainId:    7,
+               ChainLen:   2,
+               ChainDesc:  []*pb.ServiceDescriptor{
+                               {SvcName: "svcA", SvcDesc: "secret a", SvcPos: 1},
+                               {SvcName: "svcB", SvcPos: 2},
+                           },
+           }
+    Logger("test").Info("Payload", "payload", Payload(scd))
+
+    var rec struct {
+        Component   string `json:"component"`
+        Payload     struct {
+            ChainDesc   []map[string]interface{} `json:"chainDesc"`
+        } `json:"payload"`
+    }
+    if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+        t.Fatalf("Failed to parse %s: %v", buf.String(), err)
+    }
+    if rec.Component != "test" {
+        t.Errorf("component = %q, want test", rec.Component)
+    }
+    if len(rec.Payload.ChainDesc) != 2 || rec.Payload.ChainDesc[0]["svcDesc"] != redacted || rec.Payload.ChainDesc[1]["svcDesc"] != nil {
+        t.Errorf("payload = %s, want svc_desc redacted", buf.String())
+    }
+    if scd.ChainDesc[0].SvcDesc != "secret a" {
+        t.Errorf("the logged message was modified")
+    }
+}
+
+func TestSetupLevel(t *testing.T) {
+    var buf bytes.Buffer
+    l := Logger("test")   // created before the setup, as the package level loggers
+    if err := Setup(&buf, FormatText, "warn"); err != nil {
+        t.Fatal(err)
+    }
+    defer Setup(os.Stderr, FormatText, "info")
+
+    l.Info("hidden")
+    l.With("chain_id", 3).Warn("shown")
+    if out := buf.String(); strings.Contains(out, "hidden") || !strings.Contains(out, "msg=shown") || !strings.Contains(out, "component=test chain_id=3") {
+        t.Errorf("output = %q", out)
+    }
+    if err := Setup(&buf, "xml", "info"); err == nil {
+        t.Errorf("Setup with format xml succeeded")
+    }
+    if _, err := ParseLevel("verbose"); err == nil {
+        t.Errorf("ParseLevel(verbose) succeeded")
+    }
+    if !l.Enabled(nil, slog.LevelWarn) {
+        t.Errorf("warn disabled after a failed setup")
+    }
+}
\ No newline at end of file
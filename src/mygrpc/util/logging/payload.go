@@ -0,0 +1,126 @@
+// Logging of the messages of the rpcs, sampled and redacted
+
+package logging
+
+import (
+    "fmt"
+    "log/slog"
+    "strconv"
+    "strings"
+    "sync/atomic"
+
+    "google.golang.org/protobuf/encoding/protojson"
+    "google.golang.org/protobuf/proto"
+    "google.golang.org/protobuf/protoadapt"
+    "google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+    PayloadsOff      = "off"
+    PayloadsFull     = "full"
+    PayloadsSample   = "sample:"   // prefix of "sample:N", logging the messages of one rpc in every N
+
+    redacted   = "[REDACTED]"
+)
+
+// fields whose values are replaced by redacted in the logged messages
+var redactedFields = map[protoreflect.Name]bool{
+    "svc_desc":  true,
+}
+
+// decide which rpcs have their messages logged, a nil Payloads logs none
+type Payloads struct {
+    every   uint64   // one rpc in every is logged, 1 for all of them
+    count   atomic.Uint64
+}
+
+// parse the payload logging mode, one of off, full and sample:N
+func ParsePayloads(s string) (*Payloads, error) {
+    switch {
+        case s == PayloadsOff || s == "":
+            return nil, nil
+        case s == PayloadsFull:
+            return &Payloads{every: 1}, nil
+        case strings.HasPrefix(s, PayloadsSample):
+            n, err := strconv.ParseUint(strings.TrimPrefix(s, PayloadsSample), 10, 32)
+            if err != nil || n == 0 {
+                return nil, &LoggingError{Msg: fmt.Sprintf("Invalid payload sampling %q, should be sample:N with N a positive integer", s)}
+            }
+            return &Payloads{every: n}, nil
+    }
+    return nil, &LoggingError{Msg: fmt.Sprintf("Invalid payload logging %q, should be one of off, full and sample:N", s)}
+}
+
+// return whether the messages of the next rpc are logged
+func (p *Payloads) Sample() bool {
+    if p == nil {
+        return false
+    }
+    return (p.count.Add(1) - 1) % p.every == 0
+}
+
+// return the value of a message in a log record. the message is only cloned,
+// redacted and marshalled if the record is written
+func Payload(m interface{}) slog.LogValuer {
+    return payload{m: m}
+}
+
+type payload struct {
+    m   interface{}
+}
+
+func (p payload) LogValue() slog.Value {
+    var msg proto.Message
+    switch m := p.m.(type) {
+        case proto.Message:
+            msg = m
+        case protoadapt.MessageV1:
+            msg = protoadapt.MessageV2Of(m)
+        default:
+            return slog.AnyValue(p.m)
+    }
+    msg = proto.Clone(msg)
+    redact(msg.ProtoReflect())
+    b, err := protojson.Marshal(msg)
+    if err != nil {
+        return slog.StringValue(err.Error())
+    }
+    return slog.AnyValue(rawJSON(b))
+}
+
+// replace the values of the redacted fields in the message and its sub-messages
+func redact(m protoreflect.Message) {
+    m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+        switch {
+            case redactedFields[fd.Name()] && fd.Kind() == protoreflect.StringKind && !fd.IsList() && !fd.IsMap():
+                m.Set(fd, protoreflect.ValueOfString(redacted))
+            case fd.Kind() != protoreflect.MessageKind && fd.Kind() != protoreflect.GroupKind:
+            case fd.IsList():
+                l := v.List()
+                for i := 0; i < l.Len(); i++ {
+                    redact(l.Get(i).Message())
+                }
+            case fd.IsMap():
+                if fd.MapValue().Kind() == protoreflect.MessageKind {
+                    v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+                        redact(mv.Message())
+                        return true
+                    })
+                }
+            default:
+                redact(v.Message())
+        }
+        return true
+    })
+}
+
+// json written as is by the json handler and as a string by the text handler
+type rawJSON []byte
+
+func (j rawJSON) MarshalJSON() ([]byte, error) {
+    return j, nil
+}
+
+func (j rawJSON) MarshalText() ([]byte, error) {
+    return j, nil
+}
\ No newline at end of file
@@ -0,0 +1,190 @@
+// Prometheus metrics of the rpcs of mygrpc
+
+package metrics
+
+import (
+    "net/http"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus"
+    "github.com/prometheus/client_golang/prometheus/collectors"
+    "github.com/prometheus/client_golang/prometheus/promhttp"
+    "golang.org/x/net/context"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/status"
+)
+
+const (
+    typeUnary          = "unary"
+    typeClientStream   = "client_stream"
+    typeServerStream   = "server_stream"
+    typeBidiStream     = "bidi_stream"
+)
+
+// metrics of the rpcs handled by a server, labelled by method and by the name of
+// the service providing by the server
+type ServerMetrics struct {
+    registry    *prometheus.Registry
+    svcLabels   prometheus.Labels   // labels of all the metrics
+    started     *prometheus.CounterVec
+    handled     *prometheus.CounterVec
+    latency     *prometheus.HistogramVec
+    inFlight    *prometheus.GaugeVec
+    received    *prometheus.CounterVec
+    sent        *prometheus.CounterVec
+}
+
+func NewServerMetrics(svcName string) *ServerMetrics {
+    labels := prometheus.Labels{"svc_name": svcName}
+    m := &ServerMetrics{
+             registry:  prometheus.NewRegistry(),
+             svcLabels: labels,
+             started:   prometheus.NewCounterVec(prometheus.CounterOpts{
+                            Name:         "mygrpc_server_started_total",
+                            Help:         "Number of rpcs started on the server.",
+                            ConstLabels:  labels,
+                        }, []string{"grpc_type", "grpc_method"}),
+             handled:   prometheus.NewCounterVec(prometheus.CounterOpts{
+                            Name:         "mygrpc_server_handled_total",
+                            Help:         "Number of rpcs completed on the server, by status code.",
+                            ConstLabels:  labels,
+                        }, []string{"grpc_type", "grpc_method", "grpc_code"}),
+             latency:   prometheus.NewHistogramVec(prometheus.HistogramOpts{
+                            Name:         "mygrpc_server_handling_seconds",
+                            Help:         "Time taken by the server to complete rpcs.",
+                            ConstLabels:  labels,
+                            Buckets:      []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+                        }, []string{"grpc_type", "grpc_method"}),
+             inFlight:  prometheus.NewGaugeVec(prometheus.GaugeOpts{
+                            Name:         "mygrpc_server_in_flight",
+                            Help:         "Number of rpcs being handled by the server.",
+                            ConstLabels:  labels,
+                        }, []string{"grpc_type", "grpc_method"}),
+             received:  prometheus.NewCounterVec(prometheus.CounterOpts{
+                            Name:         "mygrpc_server_msg_received_total",
+                            Help:         "Number of stream messages received by the server.",
+                            ConstLabels:  labels,
+                        }, []string{"grpc_type", "grpc_method"}),
+             sent:      prometheus.NewCounterVec(prometheus.CounterOpts{
+                            Name:         "mygrpc_server_msg_sent_total",
+                            Help:         "Number of stream messages sent by the server.",
+                            ConstLabels:  labels,
+                        }, []string{"grpc_type", "grpc_method"}),
+         }
+    m.registry.MustRegister(m.started, m.handled, m.latency, m.inFlight, m.received, m.sent,
+                            collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+    return m
+}
+
+// the http handler exposing the metrics in the prometheus text format
+func (m *ServerMetrics) Handler() http.Handler {
+    return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// serve the metrics at /metrics on addr, until the listener fails
+func (m *ServerMetrics) ListenAndServe(addr string) error {
+    mux := http.NewServeMux()
+    mux.Handle("/metrics", m.Handler())
+    return http.ListenAndServe(addr, mux)
+}
+
+// record an rpc from its start, and return the function recording its end
+func (m *ServerMetrics) start(typ, method string) func(error) {
+    begin := time.Now()
+    m.started.WithLabelValues(typ, method).Inc()
+    m.inFlight.WithLabelValues(typ, method).Inc()
+    return func(err error) {
+        m.inFlight.WithLabelValues(typ, method).Dec()
+        m.handled.WithLabelValues(typ, method, status.Code(err).String()).Inc()
+        m.latency.WithLabelValues(typ, method).Observe(time.Since(begin).Seconds())
+    }
+}
+
+func (m *ServerMetrics) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+    return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+        end := m.start(typeUnary, info.FullMethod)
+        resp, err := handler(ctx, req)
+        end(err)
+        return resp, err
+    }
+}
+
+func (m *ServerMetrics) StreamServerInterceptor() grpc.StreamServerInterceptor {
+    return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+        typ := streamType(info)
+        end := m.start(typ, info.FullMethod)
+        err := handler(srv, &countedServerStream{
+                                ServerStream:  ss,
+                                received:      m.received.WithLabelValues(typ, info.FullMethod),
+                                sent:          m.sent.WithLabelValues(typ, info.FullMethod),
+                            })
+        end(err)
+        return err
+    }
+}
+
+// statistics of a cache of the server, read whenever the metrics are collected
+type CacheStats struct {
+    Hits            uint64
+    Misses          uint64
+    Evictions       uint64   // entries removed to make room for new ones
+    Invalidations   uint64   // entries removed as the services they depend on changed
+    Size            int   // number of entries in the cache
+}
+
+// expose the statistics of a cache as the mygrpc_server_cache_* metrics labelled
+// by the name of the cache
+func (m *ServerMetrics) RegisterCache(name string, stats func() CacheStats) {
+    labels := prometheus.Labels{"cache": name}
+    for k, v := range m.svcLabels {
+        labels[k] = v
+    }
+    counter := func(metric, help string, value func(CacheStats) uint64) prometheus.Collector {
+        return prometheus.NewCounterFunc(prometheus.CounterOpts{Name: metric, Help: help, ConstLabels: labels}, func() float64 {
+            return float64(value(stats()))
+        })
+    }
+    m.registry.MustRegister(
+        counter("mygrpc_server_cache_hits_total", "Number of lookups found in the cache.", func(st CacheStats) uint64 { return st.Hits }),
+        counter("mygrpc_server_cache_misses_total", "Number of lookups not found in the cache.", func(st CacheStats) uint64 { return st.Misses }),
+        counter("mygrpc_server_cache_evictions_total", "Number of entries evicted from the cache when it is full.", func(st CacheStats) uint64 { return st.Evictions }),
+        counter("mygrpc_server_cache_invalidations_total", "Number of entries dropped from the cache as they are out of date.", func(st CacheStats) uint64 { return st.Invalidations }),
+        prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: "mygrpc_server_cache_entries", Help: "Number of entries in the cache.", ConstLabels: labels}, func() float64 {
+            return float64(stats().Size)
+        }),
+    )
+}
+
+func streamType(info *grpc.StreamServerInfo) string {
+    switch {
+        case info.IsClientStream && info.IsServerStream:
+            return typeBidiStream
+        case info.IsClientStream:
+            return typeClientStream
+        default:
+            return typeServerStream
+    }
+}
+
+// server stream counting the messages sent and received
+type countedServerStream struct {
+    grpc.ServerStream
+    received   prometheus.Counter
+    sent       prometheus.Counter
+}
+
+func (s *countedServerStream) SendMsg(m interface{}) error {
+    err := s.ServerStream.SendMsg(m)
+    if err == nil {
+        s.sent.Inc()
+    }
+    return err
+}
+
+func (s *countedServerStream) RecvMsg(m interface{}) error {
+    err := s.ServerStream.RecvMsg(m)
+    if err == nil {
+        s.received.Inc()
+    }
+    return err
+}
\ No newline at end of file
@@ -0,0 +1,107 @@
+package metrics
+
+import (
+    "io"
+    "testing"
+
+    "github.com/prometheus/client_golang/prometheus/testutil"
+    "golang.org/x/net/context"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+)
+
+// server stream receiving n messages before the end of the stream
+type fakeStream struct {
+    grpc.ServerStream
+    n   int
+}
+
+func (s *fakeStream) RecvMsg(m interface{}) error {
+    if s.n == 0 {
+        return io.EOF
+    }
+    s.n--
+    return nil
+}
+
+func (s *fakeStream) SendMsg(m interface{}) error {
+    return nil
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+    m := NewServerMetrics("svcA")
+    intercept := m.UnaryServerInterceptor()
+    info := &grpc.UnaryServerInfo{FullMethod: "/mygrpc.MyGrpc/GetChainReqResp"}
+    for _, code := range []codes.Code{codes.OK, codes.OK, codes.NotFound} {
+        _, err := intercept(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+            if got := testutil.ToFloat64(m.inFlight.WithLabelValues(typeUnary, info.FullMethod)); got != 1 {
+                t.Errorf("%v rpcs in flight while handling one", got)
+            }
+            return nil, status.Error(code, "")
+        })
+        if status.Code(err) != code {
+            t.Errorf("interceptor returned %v, want %v", err, code)
+        }
+    }
+    if got := testutil.ToFloat64(m.started.WithLabelValues(typeUnary, info.FullMethod)); got != 3 {
+        t.Errorf("%v rpcs started, want 3", got)
+    }
+    if got := testutil.ToFloat64(m.handled.WithLabelValues(typeUnary, info.FullMethod, "OK")); got != 2 {
+        t.Errorf("%v rpcs handled with OK, want 2", got)
+    }
+    if got := testutil.ToFloat64(m.handled.WithLabelValues(typeUnary, info.FullMethod, "NotFound")); got != 1 {
+        t.Errorf("%v rpcs handled with NotFound, want 1", got)
+    }
+    if got := testutil.ToFloat64(m.inFlight.WithLabelValues(typeUnary, info.FullMethod)); got != 0 {
+        t.Errorf("%v rpcs in flight after they end", got)
+    }
+    if got := testutil.CollectAndCount(m.latency); got != 1 {
+        t.Errorf("%d latency histograms, want 1", got)
+    }
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+    m := NewServerMetrics("svcA")
+    intercept := m.StreamServerInterceptor()
+    for _, tc := range []struct {
+        info   grpc.StreamServerInfo
+        typ    string
+        code   codes.Code
+    }{
+        {grpc.StreamServerInfo{FullMethod: "/mygrpc.MyGrpc/GetChainsReqResps", IsServerStream: true}, typeServerStream, codes.OK},
+        {grpc.StreamServerInfo{FullMethod: "/mygrpc.MyGrpc/GetChainsReqsResp", IsClientStream: true}, typeClientStream, codes.InvalidArgument},
+        {grpc.StreamServerInfo{FullMethod: "/mygrpc.MyGrpc/GetChainsReqsResps", IsClientStream: true, IsServerStream: true}, typeBidiStream, codes.OK},
+    } {
+        method := tc.info.FullMethod
+        // every message received is answered twice
+        err := intercept(nil, &fakeStream{n: 3}, &tc.info, func(srv interface{}, ss grpc.ServerStream) error {
+            if got := testutil.ToFloat64(m.inFlight.WithLabelValues(tc.typ, method)); got != 1 {
+                t.Errorf("%s: %v rpcs in flight while handling one", method, got)
+            }
+            for ss.RecvMsg(nil) == nil {
+                ss.SendMsg(nil)
+                ss.SendMsg(nil)
+            }
+            return status.Error(tc.code, "")
+        })
+        if status.Code(err) != tc.code {
+            t.Errorf("%s: interceptor returned %v, want %v", method, err, tc.code)
+        }
+        if got := testutil.ToFloat64(m.received.WithLabelValues(tc.typ, method)); got != 3 {
+            t.Errorf("%s: %v messages received, want 3", method, got)
+        }
+        if got := testutil.ToFloat64(m.sent.WithLabelValues(tc.typ, method)); got != 6 {
+            t.Errorf("%s: %v messages sent, want 6", method, got)
+        }
+        if got := testutil.ToFloat64(m.handled.WithLabelValues(tc.typ, method, tc.code.String())); got != 1 {
+            t.Errorf("%s: %v rpcs handled with %v, want 1", method, got, tc.code)
+        }
+        if got := testutil.ToFloat64(m.inFlight.WithLabelValues(tc.typ, method)); got != 0 {
+            t.Errorf("%s: %v rpcs in flight after it ends", method, got)
+        }
+    }
+    if got := testutil.CollectAndCount(m.started); got != 3 {
+        t.Errorf("%d started counters, want one per method", got)
+    }
+}
\ No newline at end of file
@@ -0,0 +1,71 @@
+// Exporters of the spans
+
+package tracing
+
+import (
+    "encoding/json"
+    "os"
+    "sync"
+)
+
+// destination of the finished spans. implementations must be safe for concurrent use
+type Exporter interface {
+    ExportSpan(s *SpanData)
+}
+
+// exporter writing the spans to a file, one json object per line
+type FileExporter struct {
+    mu     sync.Mutex   // serializes the writes
+    file   *os.File
+    enc    *json.Encoder
+}
+
+// create an exporter appending to the file at path, created if missing
+func NewFileExporter(path string) (*FileExporter, error) {
+    f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+    if err != nil {
+        return nil, err
+    }
+    return &FileExporter{file: f, enc: json.NewEncoder(f)}, nil
+}
+
+func (e *FileExporter) ExportSpan(s *SpanData) {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    e.enc.Encode(s)
+}
+
+func (e *FileExporter) Close() error {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    return e.file.Close()
+}
+
+// exporter keeping the spans in memory, for tests and local runs
+type MemoryExporter struct {
+    mu      sync.Mutex   // guards spans
+    spans   []SpanData
+}
+
+func NewMemoryExporter() *MemoryExporter {
+    return &MemoryExporter{}
+}
+
+func (e *MemoryExporter) ExportSpan(s *SpanData) {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    e.spans = append(e.spans, *s)
+}
+
+// return the spans exported so far, in order of ending
+func (e *MemoryExporter) Spans() []SpanData {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    return append([]SpanData(nil), e.spans...)
+}
+
+func (e *MemoryExporter) Reset() {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    e.spans = nil
+}
\ No newline at end of file
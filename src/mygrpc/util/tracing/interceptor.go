@@ -0,0 +1,149 @@
+// Grpc interceptors creating a span per rpc and per stream message
+
+package tracing
+
+import (
+    "fmt"
+    "io"
+
+    "golang.org/x/net/context"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/metadata"
+    "google.golang.org/grpc/peer"
+    "google.golang.org/grpc/status"
+)
+
+// start the server span of an rpc, child of the span propagated by the client
+func (t *Tracer) startServerSpan(ctx context.Context, method string) *Span {
+    md, _ := metadata.FromIncomingContext(ctx)
+    span := t.StartSpan(method, KindServer, Extract(md))
+    span.SetAttribute("rpc.method", method)
+    if p, ok := peer.FromContext(ctx); ok {
+        span.SetAttribute("net.peer", p.Addr.String())
+    }
+    return span
+}
+
+// start the client span of an rpc, child of the span carried by the context if any,
+// and return the context propagating it
+func (t *Tracer) startClientSpan(ctx context.Context, method, target string) (context.Context, *Span) {
+    var parent SpanContext
+    if ps := SpanFromContext(ctx); ps != nil {
+        parent = ps.SpanContext()
+    }
+    span := t.StartSpan(method, KindClient, parent)
+    span.SetAttribute("rpc.method", method)
+    span.SetAttribute("net.peer", target)
+    md, _ := metadata.FromOutgoingContext(ctx)
+    md = md.Copy()
+    Inject(md, span.ctx, span.parent)
+    return metadata.NewOutgoingContext(ContextWithSpan(ctx, span), md), span
+}
+
+// start and end the span of a single message of a stream
+func (t *Tracer) traceMessage(parent *Span, name string, n int, op func() error) error {
+    span := t.StartSpan(fmt.Sprintf("%s #%d", name, n), KindMessage, parent.SpanContext())
+    err := op()
+    if err == io.EOF {
+        return err   // end of the stream, not a message
+    }
+    span.End(status.Code(err).String(), err)
+    return err
+}
+
+func (t *Tracer) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+    return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+        span := t.startServerSpan(ctx, info.FullMethod)
+        resp, err := handler(ContextWithSpan(ctx, span), req)
+        span.End(status.Code(err).String(), err)
+        return resp, err
+    }
+}
+
+func (t *Tracer) StreamServerInterceptor() grpc.StreamServerInterceptor {
+    return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+        span := t.startServerSpan(ss.Context(), info.FullMethod)
+        err := handler(srv, &tracedServerStream{ServerStream: ss, tracer: t, span: span, ctx: ContextWithSpan(ss.Context(), span)})
+        span.End(status.Code(err).String(), err)
+        return err
+    }
+}
+
+// server stream tracing the messages as children of the span of the rpc
+type tracedServerStream struct {
+    grpc.ServerStream
+    tracer   *Tracer
+    span     *Span
+    ctx      context.Context
+    sent     int   // number of messages sent
+    recv     int   // number of messages received
+}
+
+func (s *tracedServerStream) Context() context.Context {
+    return s.ctx
+}
+
+func (s *tracedServerStream) SendMsg(m interface{}) error {
+    s.sent++
+    return s.tracer.traceMessage(s.span, "send", s.sent, func() error { return s.ServerStream.SendMsg(m) })
+}
+
+func (s *tracedServerStream) RecvMsg(m interface{}) error {
+    s.recv++
+    return s.tracer.traceMessage(s.span, "recv", s.recv, func() error { return s.ServerStream.RecvMsg(m) })
+}
+
+func (t *Tracer) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+    return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+        ctx, span := t.startClientSpan(ctx, method, cc.Target())
+        err := invoker(ctx, method, req, reply, cc, opts...)
+        span.End(status.Code(err).String(), err)
+        return err
+    }
+}
+
+func (t *Tracer) StreamClientInterceptor() grpc.StreamClientInterceptor {
+    return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+        ctx, span := t.startClientSpan(ctx, method, cc.Target())
+        cs, err := streamer(ctx, desc, cc, method, opts...)
+        if err != nil {
+            span.End(status.Code(err).String(), err)
+            return nil, err
+        }
+        return &tracedClientStream{ClientStream: cs, tracer: t, span: span, serverStreams: desc.ServerStreams}, nil
+    }
+}
+
+// client stream tracing the messages as children of the span of the rpc, which ends
+// with the last response or the first error
+type tracedClientStream struct {
+    grpc.ClientStream
+    tracer          *Tracer
+    span            *Span
+    serverStreams   bool   // whether the server sends a stream of responses, else a single one
+    sent            int   // number of messages sent
+    recv            int   // number of messages received
+}
+
+func (s *tracedClientStream) SendMsg(m interface{}) error {
+    s.sent++
+    err := s.tracer.traceMessage(s.span, "send", s.sent, func() error { return s.ClientStream.SendMsg(m) })
+    if err != nil && err != io.EOF {
+        s.span.End(status.Code(err).String(), err)
+    }
+    return err
+}
+
+func (s *tracedClientStream) RecvMsg(m interface{}) error {
+    s.recv++
+    err := s.tracer.traceMessage(s.span, "recv", s.recv, func() error { return s.ClientStream.RecvMsg(m) })
+    switch {
+        case err == io.EOF:
+            s.span.End(status.Code(nil).String(), nil)
+        case err != nil:
+            s.span.End(status.Code(err).String(), err)
+        case !s.serverStreams:
+            s.span.End(status.Code(nil).String(), nil)
+    }
+    return err
+}
\ No newline at end of file
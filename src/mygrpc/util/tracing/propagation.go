@@ -0,0 +1,106 @@
+// Propagation of the span contexts through grpc metadata, in the W3C trace context
+// and the B3 formats. the B3 headers are the ones forwarded by the envoy proxies of istio
+
+package tracing
+
+import (
+    "encoding/hex"
+    "strings"
+
+    "google.golang.org/grpc/metadata"
+)
+
+const (
+    traceparentKey   = "traceparent"
+    b3TraceIDKey     = "x-b3-traceid"
+    b3SpanIDKey      = "x-b3-spanid"
+    b3ParentIDKey    = "x-b3-parentspanid"
+    b3SampledKey     = "x-b3-sampled"
+    b3SingleKey      = "b3"
+)
+
+// write the span context into the metadata in both formats
+func Inject(md metadata.MD, sc SpanContext, parent SpanID) {
+    flags, sampled := "00", "0"
+    if sc.Sampled {
+        flags, sampled = "01", "1"
+    }
+    md.Set(traceparentKey, "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags)
+    md.Set(b3TraceIDKey, sc.TraceID.String())
+    md.Set(b3SpanIDKey, sc.SpanID.String())
+    md.Set(b3SampledKey, sampled)
+    if parent.IsValid() {
+        md.Set(b3ParentIDKey, parent.String())
+    } else {
+        md.Delete(b3ParentIDKey)
+    }
+    md.Delete(b3SingleKey)
+}
+
+// read the span context from the metadata, trying traceparent, then the multiple
+// B3 headers, then the single b3 header. the result is invalid if none is found
+func Extract(md metadata.MD) SpanContext {
+    if sc, ok := parseTraceparent(first(md, traceparentKey)); ok {
+        return sc
+    }
+    if sc, ok := parseB3(first(md, b3TraceIDKey), first(md, b3SpanIDKey), first(md, b3SampledKey)); ok {
+        return sc
+    }
+    if parts := strings.Split(first(md, b3SingleKey), "-"); len(parts) >= 2 {
+        sampled := ""
+        if len(parts) >= 3 {
+            sampled = parts[2]
+        }
+        if sc, ok := parseB3(parts[0], parts[1], sampled); ok {
+            return sc
+        }
+    }
+    return SpanContext{}
+}
+
+func first(md metadata.MD, key string) string {
+    if vs := md.Get(key); len(vs) > 0 {
+        return strings.TrimSpace(vs[0])
+    }
+    return ""
+}
+
+// parse 'version-traceid-spanid-flags', e.g. 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+func parseTraceparent(v string) (SpanContext, bool) {
+    parts := strings.Split(v, "-")
+    if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[3]) != 2 {
+        return SpanContext{}, false
+    }
+    var sc SpanContext
+    if !decodeID(sc.TraceID[:], parts[1]) || !decodeID(sc.SpanID[:], parts[2]) {
+        return SpanContext{}, false
+    }
+    flags, err := hex.DecodeString(parts[3])
+    if err != nil {
+        return SpanContext{}, false
+    }
+    sc.Sampled = flags[0] & 1 == 1
+    return sc, sc.IsValid()
+}
+
+// parse B3 ids. 64 bit trace ids are left padded with zeros. an absent sampling
+// decision is taken as sampled, as the decision is then left to the receiver
+func parseB3(traceID, spanID, sampled string) (SpanContext, bool) {
+    if len(traceID) == 16 {
+        traceID = strings.Repeat("0", 16) + traceID
+    }
+    var sc SpanContext
+    if !decodeID(sc.TraceID[:], traceID) || !decodeID(sc.SpanID[:], spanID) {
+        return SpanContext{}, false
+    }
+    sc.Sampled = sampled != "0" && sampled != "false"
+    return sc, sc.IsValid()
+}
+
+func decodeID(dst []byte, s string) bool {
+    if len(s) != 2 * len(dst) {
+        return false
+    }
+    _, err := hex.Decode(dst, []byte(strings.ToLower(s)))
+    return err == nil
+}
\ No newline at end of file
@@ -0,0 +1,70 @@
+package tracing
+
+import (
+    "testing"
+
+    "google.golang.org/grpc/metadata"
+)
+
+func TestInjectExtract(t *testing.T) {
+    tracer := NewTracer("svcA", nil, nil)
+    span := tracer.StartSpan("op", KindClient, SpanContext{})
+    md := metadata.MD{}
+    Inject(md, span.SpanContext(), SpanID{})
+    if got := Extract(md); got != span.SpanContext() {
+        t.Errorf("extracted %+v, want %+v", got, span.SpanContext())
+    }
+
+    // B3 alone, as forwarded by the proxies
+    md.Delete(traceparentKey)
+    if got := Extract(md); got != span.SpanContext() {
+        t.Errorf("extracted %+v from b3, want %+v", got, span.SpanContext())
+    }
+}
+
+func TestExtractFormats(t *testing.T) {
+    cases := []struct {
+        md        metadata.MD
+        trace     string
+        sampled   bool
+    }{
+        {metadata.Pairs("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"), "4bf92f3577b34da6a3ce929d0e0e4736", true},
+        {metadata.Pairs("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"), "4bf92f3577b34da6a3ce929d0e0e4736", false},
+        {metadata.Pairs("x-b3-traceid", "a3ce929d0e0e4736", "x-b3-spanid", "00f067aa0ba902b7"), "0000000000000000a3ce929d0e0e4736", true},
+        {metadata.Pairs("b3", "4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0"), "4bf92f3577b34da6a3ce929d0e0e4736", false},
+        {metadata.Pairs("traceparent", "00-00000000000000000000000000000000-00f067aa0ba902b7-01"), "", false},
+        {metadata.Pairs("x-b3-traceid", "xyz", "x-b3-spanid", "00f067aa0ba902b7"), "", false},
+    }
+    for i, c := range cases {
+        sc := Extract(c.md)
+        if c.trace == "" {
+            if sc.IsValid() {
+                t.Errorf("case %d: extracted %+v from invalid headers", i, sc)
+            }
+            continue
+        }
+        if sc.TraceID.String() != c.trace || sc.Sampled != c.sampled {
+            t.Errorf("case %d: extracted trace %s sampled %v, want %s %v", i, sc.TraceID, sc.Sampled, c.trace, c.sampled)
+        }
+    }
+}
+
+func TestSpanExport(t *testing.T) {
+    exporter := NewMemoryExporter()
+    tracer := NewTracer("svcA", exporter, nil)
+    parent := tracer.StartSpan("parent", KindServer, SpanContext{})
+    child := tracer.StartSpan("child", KindMessage, parent.SpanContext())
+    child.End("OK", nil)
+    child.End("OK", nil)
+    parent.End("OK", nil)
+    unsampled := tracer.StartSpan("unsampled", KindServer, SpanContext{TraceID: TraceID{1}, SpanID: SpanID{1}})
+    unsampled.End("OK", nil)
+
+    spans := exporter.Spans()
+    if len(spans) != 2 {
+        t.Fatalf("got %d spans exported, want 2", len(spans))
+    }
+    if spans[0].TraceID != spans[1].TraceID || spans[0].ParentID != spans[1].SpanID {
+        t.Errorf("child %+v is not linked to parent %+v", spans[0], spans[1])
+    }
+}
\ No newline at end of file
@@ -0,0 +1,178 @@
+// Tracing of the rpcs of mygrpc
+
+package tracing
+
+import (
+    "crypto/rand"
+    "encoding/hex"
+    "log/slog"
+    "sync"
+    "time"
+
+    "golang.org/x/net/context"
+)
+
+type TraceID [16]byte
+
+type SpanID [8]byte
+
+func (t TraceID) String() string {
+    return hex.EncodeToString(t[:])
+}
+
+func (t TraceID) IsValid() bool {
+    return t != TraceID{}
+}
+
+func (s SpanID) String() string {
+    return hex.EncodeToString(s[:])
+}
+
+func (s SpanID) IsValid() bool {
+    return s != SpanID{}
+}
+
+// identity of a span propagated between processes
+type SpanContext struct {
+    TraceID   TraceID
+    SpanID    SpanID
+    Sampled   bool   // whether the spans of the trace are exported
+}
+
+func (sc SpanContext) IsValid() bool {
+    return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+const (
+    KindServer   = "server"
+    KindClient   = "client"
+    KindMessage  = "message"   // a single message sent or received within a stream
+)
+
+// a finished span as passed to the exporters
+type SpanData struct {
+    Name         string              `json:"name"`
+    Kind         string              `json:"kind"`
+    Service      string              `json:"service"`
+    TraceID      string              `json:"trace_id"`
+    SpanID       string              `json:"span_id"`
+    ParentID     string              `json:"parent_span_id,omitempty"`
+    Start        time.Time           `json:"start"`
+    End          time.Time           `json:"end"`
+    DurationNs   int64               `json:"duration_ns"`
+    Attributes   map[string]string   `json:"attributes,omitempty"`
+    Code         string              `json:"code"`
+    Error        string              `json:"error,omitempty"`
+}
+
+// an operation being traced
+type Span struct {
+    tracer   *Tracer
+    ctx      SpanContext
+    parent   SpanID
+
+    mu       sync.Mutex   // guards the fields below
+    data     SpanData
+    ended    bool
+}
+
+// identity of the span, to be propagated to the callees
+func (s *Span) SpanContext() SpanContext {
+    return s.ctx
+}
+
+func (s *Span) SetAttribute(key, value string) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if s.data.Attributes == nil {
+        s.data.Attributes = make(map[string]string)
+    }
+    s.data.Attributes[key] = value
+}
+
+// end the span with the status code and error of the operation, and export it if
+// sampled. a span is ended only once, later calls are ignored
+func (s *Span) End(code string, err error) {
+    s.mu.Lock()
+    if s.ended {
+        s.mu.Unlock()
+        return
+    }
+    s.ended = true
+    s.data.End = time.Now()
+    s.data.DurationNs = int64(s.data.End.Sub(s.data.Start))
+    s.data.Code = code
+    if err != nil {
+        s.data.Error = err.Error()
+    }
+    data := s.data
+    s.mu.Unlock()
+
+    if s.tracer.logger != nil && data.Kind != KindMessage {
+        s.tracer.logger.Debug("Finished span", "kind", data.Kind, "span_id", data.SpanID, "name", data.Name, "trace_id", data.TraceID, "code", code, "duration", time.Duration(data.DurationNs))
+    }
+    if s.ctx.Sampled && s.tracer.exporter != nil {
+        s.tracer.exporter.ExportSpan(&data)
+    }
+}
+
+// creator of the spans of one process
+type Tracer struct {
+    service    string   // name of the service reported in the spans
+    exporter   Exporter   // nil to drop the spans
+    logger     *slog.Logger   // logger of the finished rpc spans, nil to disable
+}
+
+func NewTracer(service string, exporter Exporter, logger *slog.Logger) *Tracer {
+    return &Tracer{service: service, exporter: exporter, logger: logger}
+}
+
+// start a span as a child of parent, or as the root of a new trace if parent is invalid
+func (t *Tracer) StartSpan(name, kind string, parent SpanContext) *Span {
+    sc := SpanContext{Sampled: true}
+    if parent.IsValid() {
+        sc.TraceID, sc.Sampled = parent.TraceID, parent.Sampled
+    } else {
+        rand.Read(sc.TraceID[:])
+    }
+    rand.Read(sc.SpanID[:])
+    s := &Span{
+             tracer:  t,
+             ctx:     sc,
+             parent:  parent.SpanID,
+             data:    SpanData{
+                          Name:     name,
+                          Kind:     kind,
+                          Service:  t.service,
+                          TraceID:  sc.TraceID.String(),
+                          SpanID:   sc.SpanID.String(),
+                          Start:    time.Now(),
+                      },
+         }
+    if parent.IsValid() {
+        s.data.ParentID = parent.SpanID.String()
+    }
+    return s
+}
+
+type spanKey struct{}
+
+// return a context carrying the span, the parent of the spans started from the context
+func ContextWithSpan(ctx context.Context, s *Span) context.Context {
+    return context.WithValue(ctx, spanKey{}, s)
+}
+
+// return the span carried by the context, or nil
+func SpanFromContext(ctx context.Context) *Span {
+    s, _ := ctx.Value(spanKey{}).(*Span)
+    return s
+}
+
+// return the trace id of the span carried by the context, or an empty string, e.g.
+// to correlate logs with the traces
+func TraceIDFromContext(ctx context.Context) string {
+    if s := SpanFromContext(ctx); s != nil {
+        return s.ctx.TraceID.String()
+    }
+    return ""
+}
\ No newline at end of file
@@ -2,6 +2,36 @@
 
 package validate
 
+import (
+    "fmt"
+    "net"
+    "regexp"
+    "sort"
+    "strconv"
+    "strings"
+
+    pb "mygrpc/mygrpc"
+
+    "google.golang.org/genproto/googleapis/rpc/errdetails"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+)
+
+const (
+    MaxSvcNameLen   = 63   // maximal length of a service name
+    MaxChainLen     = 64   // maximal number of services in a service chain
+    MaxChainDepth   = 8   // maximal nesting depth of the chains referenced by a service chain
+    MaxChains       = 1000   // maximal number of service chains in a single request
+    MaxLabelKeyLen  = 253   // maximal length of a label key
+)
+
+// label keys are like service names, optionally prefixed by a domain and '/', e.g.
+// tier or example.com/team
+var labelKeyRe = regexp.MustCompile(`^([A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?/)?[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`)
+
+// service names are dns-label like, e.g. svcA, auth-svc, payments.v2
+var svcNameRe = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`)
+
 func ValRpcType(t string) bool {
     rpc_type := [4]string{"simple", "server_stream", "client_stream", "bi_stream"}
     for _, st := range rpc_type {
@@ -10,4 +40,244 @@ func ValRpcType(t string) bool {
         }
     }
     return false
+}
+
+// a single problem found in a request
+type Violation struct {
+    Field         string   // path of the offending field, e.g. chains[2].chain[1].svc_pos
+    Description   string
+}
+
+// error type reporting all the problems found in a request at once
+type ValidationError struct {
+    Violations   []Violation
+}
+
+func (e *ValidationError) Error() string {
+    msgs := make([]string, len(e.Violations))
+    for i, v := range e.Violations {
+        msgs[i] = fmt.Sprintf("%s: %s", v.Field, v.Description)
+    }
+    return fmt.Sprintf("Invalid request with %d violation(s): %s", len(e.Violations), strings.Join(msgs, "; "))
+}
+
+// convert the error into an InvalidArgument status carrying a BadRequest with all
+// the violations. the status is picked up by grpc when the error is returned by a handler
+func (e *ValidationError) GRPCStatus() *status.Status {
+    st := status.New(codes.InvalidArgument, e.Error())
+    br := &errdetails.BadRequest{}
+    for _, v := range e.Violations {
+        br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+                                                            Field:        v.Field,
+                                                            Description:  v.Description,
+                                                        })
+    }
+    ds, err := st.WithDetails(br)
+    if err != nil {
+        return st
+    }
+    return ds
+}
+
+// collector of the violations found in a request
+type violations []Violation
+
+func (vs *violations) add(field, format string, args ...interface{}) {
+    *vs = append(*vs, Violation{Field: field, Description: fmt.Sprintf(format, args...)})
+}
+
+func (vs violations) err() error {
+    if len(vs) == 0 {
+        return nil
+    }
+    return &ValidationError{Violations: vs}
+}
+
+func prefixed(prefix, field string) string {
+    if prefix == "" {
+        return field
+    }
+    if field == "" {
+        return prefix
+    }
+    return prefix + "." + field
+}
+
+// check that a service name is non-empty and well-formed
+func ValServiceName(name string) error {
+    var vs violations
+    valServiceName(&vs, "svc_name", name)
+    return vs.err()
+}
+
+func valServiceName(vs *violations, field, name string) {
+    switch {
+        case name == "":
+            vs.add(field, "Service name is empty")
+        case len(name) > MaxSvcNameLen:
+            vs.add(field, "Service name %q is longer than %d characters", name, MaxSvcNameLen)
+        case !svcNameRe.MatchString(name):
+            vs.add(field, "Service name %q is malformed, expecting letters, digits, '-', '_' or '.' starting and ending with a letter or digit", name)
+    }
+}
+
+// check a service descriptor: a well-formed name, endpoints as host:port and
+// well-formed label keys
+func ValServiceDescriptor(sd *pb.ServiceDescriptor) error {
+    var vs violations
+    valServiceName(&vs, "svc_name", sd.GetSvcName())
+    for i, ep := range sd.GetEndpoints() {
+        host, port, err := net.SplitHostPort(ep)
+        if err != nil || host == "" {
+            vs.add(fmt.Sprintf("endpoints[%d]", i), "Endpoint %q is not of the form host:port", ep)
+            continue
+        }
+        if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+            vs.add(fmt.Sprintf("endpoints[%d]", i), "Endpoint %q has invalid port %q", ep, port)
+        }
+    }
+    keys := make([]string, 0, len(sd.GetLabels()))
+    for k := range sd.GetLabels() {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+    for _, k := range keys {
+        valLabelKey(&vs, fmt.Sprintf("labels[%q]", k), k)
+    }
+    return vs.err()
+}
+
+// check that a label key is well-formed
+func ValLabelKey(key string) error {
+    var vs violations
+    valLabelKey(&vs, "key", key)
+    return vs.err()
+}
+
+func valLabelKey(vs *violations, field, key string) {
+    if len(key) > MaxLabelKeyLen || !labelKeyRe.MatchString(key) {
+        vs.add(field, "Label key %q is malformed, expecting a name of letters, digits, '-', '_' or '.' optionally prefixed by a domain and '/'", key)
+    }
+}
+
+// report whether a service chain is a reference to a chain stored by the server,
+// holding neither a service nor a chain_len
+func IsChainRef(sc *pb.ServiceChain) bool {
+    return len(sc.GetChain()) == 0 && sc.GetChainLen() == 0
+}
+
+// check the structure of a service chain: positive chain_id, chain_len matching the
+// number of services within the size limit, well-formed names or positive chain_refs,
+// and positions which are unique and cover 1..chain_len. a reference to a stored chain
+// only needs a positive chain_id. the chain_id of a chain of the request is picked by
+// the client, so that it may be the id of a stored chain referenced by the chain
+func ValServiceChain(sc *pb.ServiceChain) error {
+    var vs violations
+    valServiceChain(&vs, "", sc, false)
+    return vs.err()
+}
+
+// check a chain to be stored by the server like a service chain, which may neither be
+// a reference nor reference itself
+func ValStoredChain(c *pb.StoredChain) error {
+    var vs violations
+    if len(c.GetChain()) == 0 {
+        vs.add("chain", "Service chain %d has no service", c.GetChainId())
+    } else {
+        valServiceChain(&vs, "", &pb.ServiceChain{ChainId: c.GetChainId(), ChainLen: int32(len(c.GetChain())), Chain: c.GetChain()}, true)
+    }
+    if c.GetName() != "" && !svcNameRe.MatchString(c.GetName()) {
+        vs.add("name", "Chain name %q is malformed, expecting letters, digits, '-', '_' or '.' starting and ending with a letter or digit", c.GetName())
+    }
+    return vs.err()
+}
+
+// stored tells whether the chain is stored under its chain_id, which it then may not reference
+func valServiceChain(vs *violations, prefix string, sc *pb.ServiceChain, stored bool) {
+    if sc == nil {
+        vs.add(prefix, "Service chain is missing")
+        return
+    }
+    if sc.GetChainId() <= 0 {
+        vs.add(prefixed(prefix, "chain_id"), "Chain id %d is not positive", sc.GetChainId())
+    }
+    if IsChainRef(sc) {
+        return
+    }
+    if len(sc.GetChain()) == 0 {
+        vs.add(prefixed(prefix, "chain"), "Service chain %d has no service", sc.GetChainId())
+    }
+    if len(sc.GetChain()) > MaxChainLen {
+        vs.add(prefixed(prefix, "chain"), "Service chain %d has %d services, more than %d", sc.GetChainId(), len(sc.GetChain()), MaxChainLen)
+    }
+    if int(sc.GetChainLen()) != len(sc.GetChain()) {
+        vs.add(prefixed(prefix, "chain_len"), "Chain len %d of service chain %d does not match its %d services", sc.GetChainLen(), sc.GetChainId(), len(sc.GetChain()))
+    }
+
+    seen := make(map[int32]int)   // index of the service holding each position
+    for i, svc := range sc.GetChain() {
+        field := prefixed(prefix, fmt.Sprintf("chain[%d]", i))
+        name := "Service " + svc.GetSvcName()
+        switch ref := svc.GetChainRef(); {
+            case ref == 0:
+                valServiceName(vs, field + ".svc_name", svc.GetSvcName())
+            case ref < 0:
+                vs.add(field + ".chain_ref", "Chain ref %d in chain %d is not positive", ref, sc.GetChainId())
+            case stored && ref == sc.GetChainId():
+                vs.add(field + ".chain_ref", "Service chain %d references itself", ref)
+            case svc.GetSvcName() != "":
+                vs.add(field + ".svc_name", "Entry referencing chain %d in chain %d also names service %s", ref, sc.GetChainId(), svc.GetSvcName())
+        }
+        if svc.GetChainRef() != 0 {
+            name = fmt.Sprintf("Chain ref %d", svc.GetChainRef())
+        }
+        pos := svc.GetSvcPos()
+        if pos < 1 || pos > int32(len(sc.GetChain())) {
+            vs.add(field + ".svc_pos", "%s in chain %d has position %d out of range [1, %d]", name, sc.GetChainId(), pos, len(sc.GetChain()))
+            continue
+        }
+        if j, dup := seen[pos]; dup {
+            vs.add(field + ".svc_pos", "%s in chain %d has position %d already taken by chain[%d]", name, sc.GetChainId(), pos, j)
+            continue
+        }
+        seen[pos] = i
+    }
+}
+
+// check a set of service chains: the number of chains within the size limit and the
+// structure of every chain. a chain may be given more than once, as repeated requests do
+func ValServiceChains(scs *pb.ServiceChains) error {
+    batch, each := ValServiceChainsEach(scs)
+    var vs violations
+    if batch != nil {
+        vs = append(vs, batch.(*ValidationError).Violations...)
+    }
+    for i, err := range each {
+        if err == nil {
+            continue
+        }
+        for _, v := range err.(*ValidationError).Violations {
+            vs = append(vs, Violation{Field: prefixed(fmt.Sprintf("chains[%d]", i), v.Field), Description: v.Description})
+        }
+    }
+    return vs.err()
+}
+
+// check a set of service chains like ValServiceChains, but report separately the
+// problems of the set itself and the ones of each chain, with fields relative to the chain
+func ValServiceChainsEach(scs *pb.ServiceChains) (batch error, chains []error) {
+    var bvs violations
+    if len(scs.GetChains()) == 0 {
+        bvs.add("chains", "No service chain is given")
+    }
+    if len(scs.GetChains()) > MaxChains {
+        bvs.add("chains", "%d service chains are given, more than %d", len(scs.GetChains()), MaxChains)
+    }
+    chains = make([]error, len(scs.GetChains()))
+    for i, sc := range scs.GetChains() {
+        var vs violations
+        valServiceChain(&vs, "", sc, false)
+        chains[i] = vs.err()
+    }
+    return bvs.err(), chains
 }
\ No newline at end of file
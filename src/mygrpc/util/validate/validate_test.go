@@ -0,0 +1,128 @@
+package validate
+
+import (
+    "reflect"
+    "testing"
+
+    pb "mygrpc/mygrpc"
+)
+
+func chain(id, l int32, svcs ...*pb.Service) *pb.ServiceChain {
+    return &pb.ServiceChain{ChainId: id, ChainLen: l, Chain: svcs}
+}
+
+func svc(name string, pos int32) *pb.Service {
+    return &pb.Service{SvcName: name, SvcPos: pos}
+}
+
+func fields(err error) []string {
+    if err == nil {
+        return nil
+    }
+    var fs []string
+    for _, v := range err.(*ValidationError).Violations {
+        fs = append(fs, v.Field)
+    }
+    return fs
+}
+
+func TestValServiceChain(t *testing.T) {
+    if err := ValServiceChain(chain(1, 3, svc("svcA", 2), svc("svcB", 1), svc("svcC", 3))); err != nil {
+        t.Errorf("valid chain is rejected: %v", err)
+    }
+
+    // every problem of the chain is reported at once
+    err := ValServiceChain(chain(0, 4, svc("", 1), svc("svcB", 1), svc("svc C", 5)))
+    want := []string{"chain_id", "chain_len", "chain[0].svc_name", "chain[1].svc_pos", "chain[2].svc_name", "chain[2].svc_pos"}
+    got := fields(err)
+    if len(got) != len(want) {
+        t.Fatalf("got violations %v, want %v", got, want)
+    }
+    for i := range want {
+        if got[i] != want[i] {
+            t.Errorf("got violation %d on %s, want %s", i, got[i], want[i])
+        }
+    }
+}
+
+func TestValChainRef(t *testing.T) {
+    if err := ValServiceChain(chain(7, 0)); err != nil {
+        t.Errorf("reference to a stored chain is rejected: %v", err)
+    }
+    if got := fields(ValServiceChain(chain(0, 0))); len(got) != 1 || got[0] != "chain_id" {
+        t.Errorf("got violations %v of a reference without chain id", got)
+    }
+    if got := fields(ValServiceChain(chain(7, 2))); len(got) != 2 || got[0] != "chain" || got[1] != "chain_len" {
+        t.Errorf("got violations %v of a chain without service", got)
+    }
+    if got := fields(ValStoredChain(&pb.StoredChain{ChainId: 7, Name: "-x"})); len(got) != 2 || got[0] != "chain" || got[1] != "name" {
+        t.Errorf("got violations %v of an empty stored chain", got)
+    }
+}
+
+func TestValNestedChain(t *testing.T) {
+    if err := ValServiceChain(chain(1, 2, svc("svcA", 1), &pb.Service{ChainRef: 4, SvcPos: 2})); err != nil {
+        t.Errorf("chain referencing another chain is rejected: %v", err)
+    }
+    got := fields(ValServiceChain(chain(1, 3, &pb.Service{ChainRef: 1, SvcPos: 1}, &pb.Service{ChainRef: -2, SvcPos: 2}, &pb.Service{SvcName: "svcA", ChainRef: 3, SvcPos: 3})))
+    if len(got) != 2 || got[0] != "chain[1].chain_ref" || got[1] != "chain[2].svc_name" {
+        t.Errorf("got violations %v of malformed chain refs", got)
+    }
+    // only a stored chain is referenced by its own chain_id
+    got = fields(ValStoredChain(&pb.StoredChain{ChainId: 1, Chain: []*pb.Service{{ChainRef: 1, SvcPos: 1}}}))
+    if len(got) != 1 || got[0] != "chain[0].chain_ref" {
+        t.Errorf("got violations %v of a stored chain referencing itself", got)
+    }
+}
+
+func TestValServiceChains(t *testing.T) {
+    err := ValServiceChains(&pb.ServiceChains{Chains: []*pb.ServiceChain{
+               chain(1, 1, svc("svcA", 1)),
+               chain(0, 1, svc("svcB", 1)),
+               chain(2, 2, svc("svcC", 1)),
+           }})
+    got := fields(err)
+    if len(got) != 2 || got[0] != "chains[1].chain_id" || got[1] != "chains[2].chain_len" {
+        t.Errorf("got violations %v", got)
+    }
+
+    if err := ValServiceChains(&pb.ServiceChains{}); err == nil {
+        t.Errorf("empty service chains are accepted")
+    }
+}
+
+func TestValServiceChainsEach(t *testing.T) {
+    batch, each := ValServiceChainsEach(&pb.ServiceChains{Chains: []*pb.ServiceChain{
+                        chain(1, 1, svc("svcA", 1)),
+                        chain(1, 1, svc("svcA", 1)),
+                        chain(2, 1, svc("svcB", 2)),
+                    }})
+    if batch != nil || each[0] != nil || each[1] != nil {
+        t.Errorf("valid or repeated chain or batch is rejected: %v, %v, %v", batch, each[0], each[1])
+    }
+    if got := fields(each[2]); len(got) != 1 || got[0] != "chain[0].svc_pos" {
+        t.Errorf("got violations %v of the invalid chain", got)
+    }
+
+    if batch, _ := ValServiceChainsEach(&pb.ServiceChains{}); batch == nil {
+        t.Errorf("empty service chains are accepted")
+    }
+}
+
+func TestValServiceDescriptor(t *testing.T) {
+    sd := &pb.ServiceDescriptor{
+              SvcName:    "svcA",
+              Endpoints:  []string{"10.0.0.1:8082", "svc-a:8082", "[::1]:8082"},
+              Labels:     map[string]string{"tier": "backend", "example.com/team": "payments"},
+          }
+    if err := ValServiceDescriptor(sd); err != nil {
+        t.Errorf("valid descriptor is rejected: %v", err)
+    }
+
+    sd.Endpoints = []string{"svc-a", ":8082", "svc-a:http", "svc-a:70000"}
+    sd.Labels = map[string]string{"-tier": "", "a/b/c": ""}
+    want := []string{"endpoints[0]", "endpoints[1]", "endpoints[2]", "endpoints[3]", `labels["-tier"]`, `labels["a/b/c"]`}
+    if got := fields(ValServiceDescriptor(sd)); !reflect.DeepEqual(got, want) {
+        t.Errorf("violations = %v, want %v", got, want)
+    }
+}
\ No newline at end of file